@@ -2,186 +2,196 @@ package factomdid
 
 import (
 	"crypto"
-	"crypto/ed25519"
-	"crypto/rand"
 	"crypto/rsa"
-	"crypto/sha256"
-	"crypto/x509"
 	"fmt"
-
-	"github.com/frankbraun/dcrd/dcrec/secp256k1"
 )
 
 // AbstractKey represents the common fields and functionality in a ManagementKey and a DIDKey.
 type AbstractKey struct {
 	Alias               string `json:"alias" form:"alias" query:"alias" validate:"required"`
-	KeyType             string `json:"keyType" form:"keyType" query:"keyType" validate:"required,oneof=ECDSASecp256k1VerificationKey Ed25519VerificationKey RSAVerificationKey"`
+	KeyType             string `json:"keyType" form:"keyType" query:"keyType" validate:"required,keytype"`
 	Controller          string `json:"controller" form:"controller" query:"controller" validate:"required"`
 	PriorityRequirement *int   `json:"priorityRequirement" form:"priorityRequirement" query:"omitempty,priorityRequirement"`
 	PublicKey           []byte `json:"publicKey" form:"publicKey" query:"publicKey" validate:"required"`
-	PrivateKey          []byte `json:"privateKey" form:"privateKey" query:"privateKey" validate:"required"`
+	PrivateKey          []byte `json:"privateKey,omitempty" form:"privateKey" query:"privateKey" validate:"required"`
+	RSAPSS              bool   `json:"rsaPss,omitempty" form:"rsaPss" query:"rsaPss"`
 }
 
 const (
 	// KeyTypeECDSA is a constant for "ECDSASecp256k1VerificationKey"
 	KeyTypeECDSA = "ECDSASecp256k1VerificationKey"
+	// KeyTypeECDSAP256 is a constant for "ECDSASecp256r1VerificationKey" (NIST P-256)
+	KeyTypeECDSAP256 = "ECDSASecp256r1VerificationKey"
 	// KeyTypeEdDSA is a constant for "Ed25519VerificationKey"
 	KeyTypeEdDSA = "Ed25519VerificationKey"
 	// KeyTypeRSA is a constant for "RSAVerificationKey"
 	KeyTypeRSA = "RSAVerificationKey"
+	// KeyTypeX25519 is a constant for "X25519KeyAgreementKey", this key can't sign and is used for key agreement only
+	KeyTypeX25519 = "X25519KeyAgreementKey"
 )
 
 // Sign a message with the existing private key and signature type
-// The message is hashed (SHA-256) before being signed
+// The message is hashed (SHA-256) before being signed (SignatureModePrehashed), as Factom DID spec requires.
+// RSA keys sign with PKCS #1 v1.5, or with RSASSA-PSS if AbstractKey.RSAPSS is set
 func (key *AbstractKey) Sign(message []byte) ([]byte, error) {
+	return key.SignWithMode(message, SignatureModePrehashed)
+}
+
+// SignWithMode signs a message using SignatureMode, see SignatureMode constants
+func (key *AbstractKey) SignWithMode(message []byte, mode SignatureMode) ([]byte, error) {
 
 	err := validate.StructPartial(key, "PrivateKey", "KeyType")
 	if err != nil {
 		return nil, err
 	}
 
-	// signing sha256 hash of the message
-	hashed := sha256.Sum256(message)
-
-	switch key.KeyType {
-	case KeyTypeECDSA:
-
-		privKey, _ := secp256k1.PrivKeyFromBytes(secp256k1.S256(), key.PrivateKey)
-
-		signature, err := privKey.Sign(hashed[:])
-		if err != nil {
-			return nil, err
-		}
-
-		signatureBytes := signature.Serialize()
-		if err != nil {
-			return nil, err
-		}
-
-		return signatureBytes, nil
-
-	case KeyTypeEdDSA:
-
-		signature := ed25519.Sign(key.PrivateKey, hashed[:])
-
-		return signature, nil
-
-	case KeyTypeRSA:
-
-		p, err := x509.ParsePKCS1PrivateKey(key.PrivateKey)
-		if err != nil {
-			return nil, err
-		}
-
-		signature, err := rsa.SignPKCS1v15(rand.Reader, p, crypto.SHA256, hashed[:])
-		if err != nil {
-			return nil, err
-		}
+	suite, err := GetKeySuite(key.KeyType)
+	if err != nil {
+		return nil, err
+	}
 
-		return signature, nil
+	keyOpts, err := key.signerOpts()
+	if err != nil {
+		return nil, err
+	}
 
+	data, opts, err := mode.prepare(message, keyOpts)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil, fmt.Errorf("Invalid key.KeyType")
+	return suite.Sign(key.PrivateKey, data, opts)
 
 }
 
 // Verify the signature of the given message.
-// The message is hashed (SHA-256) before being verified
+// The message is hashed (SHA-256) before being verified (SignatureModePrehashed).
+// RSA keys accept both PKCS #1 v1.5 and RSASSA-PSS signatures
 func (key *AbstractKey) Verify(message []byte, signature []byte) (bool, error) {
+	return key.VerifyWithMode(message, signature, SignatureModePrehashed)
+}
+
+// VerifyWithMode verifies the signature of the given message using SignatureMode
+func (key *AbstractKey) VerifyWithMode(message []byte, signature []byte, mode SignatureMode) (bool, error) {
 
 	err := validate.StructPartial(key, "PublicKey", "KeyType")
 	if err != nil {
 		return false, err
 	}
 
-	// signing sha256 hash of the message
-	hashed := sha256.Sum256(message)
-
-	switch key.KeyType {
-	case KeyTypeECDSA:
-
-		pubKey, err := secp256k1.ParsePubKey(key.PublicKey, secp256k1.S256())
-		if err != nil {
-			return false, err
-		}
-
-		sig, err := secp256k1.ParseSignature(signature, secp256k1.S256())
-		if err != nil {
-			return false, err
-		}
-
-		v := sig.Verify(hashed[:], pubKey)
+	suite, err := GetKeySuite(key.KeyType)
+	if err != nil {
+		return false, err
+	}
 
-		return v, nil
+	data, opts, err := mode.prepare(message, crypto.SHA256)
+	if err != nil {
+		return false, err
+	}
 
-	case KeyTypeEdDSA:
+	return suite.Verify(key.PublicKey, data, signature, opts)
 
-		v := ed25519.Verify(key.PublicKey, hashed[:], signature)
+}
 
-		return v, nil
+// VerifyStrict verifies the signature like Verify, but also rejects malleable signatures:
+// high S and non-strict DER ECDSA signatures, non-canonical Ed25519 signatures and RSA signatures of invalid length
+func (key *AbstractKey) VerifyStrict(message []byte, signature []byte) (bool, error) {
 
-	case KeyTypeRSA:
+	err := validate.StructPartial(key, "PublicKey", "KeyType")
+	if err != nil {
+		return false, err
+	}
 
-		p, err := x509.ParsePKCS1PublicKey(key.PublicKey)
-		if err != nil {
-			return false, err
-		}
+	suite, err := GetKeySuite(key.KeyType)
+	if err != nil {
+		return false, err
+	}
 
-		err = rsa.VerifyPKCS1v15(p, crypto.SHA256, hashed[:], signature)
-		if err != nil {
-			return false, err
-		}
-		return true, nil
+	strictSuite, ok := suite.(StrictKeySuite)
+	if !ok {
+		return false, fmt.Errorf("%s doesn't support strict verification", key.KeyType)
+	}
 
+	err = strictSuite.CheckCanonicalSignature(key.PublicKey, signature)
+	if err != nil {
+		return false, err
 	}
 
-	return false, fmt.Errorf("Invalid key.KeyType")
+	return key.Verify(message, signature)
 
 }
 
 // internal helper function
 // GenerateRandomKeys generates random keypair of AbstractKey.KeyType
-func (key *AbstractKey) generateRandomKeys() *AbstractKey {
-
-	switch key.KeyType {
-	case KeyTypeECDSA:
+func (key *AbstractKey) generateRandomKeys(opts ...KeyOption) error {
 
-		// Generate random ecdsa key
-		privateKey, _ := secp256k1.GeneratePrivateKey(secp256k1.S256())
-		key.PrivateKey = privateKey.Serialize()
-
-		// Get corresponding public key
-		x, y := privateKey.Public()
-		key.PublicKey = secp256k1.NewPublicKey(secp256k1.S256(), x, y).Serialize()
+	suite, err := GetKeySuite(key.KeyType)
+	if err != nil {
+		return err
+	}
 
-	case KeyTypeEdDSA:
+	o := newKeyOptions(opts)
 
-		// Generate random eddsa key
-		key.PublicKey, key.PrivateKey, _ = ed25519.GenerateKey(rand.Reader)
+	if o.RSAPSS && key.KeyType != KeyTypeRSA {
+		return fmt.Errorf("RSASSA-PSS requires %s", KeyTypeRSA)
+	}
 
-	case KeyTypeRSA:
+	switch s := suite.(type) {
+	case ConfigurableKeySuite:
+		key.PublicKey, key.PrivateKey, err = s.GenerateKeyWithOptions(o)
+	default:
+		if len(opts) > 0 {
+			return fmt.Errorf("%s doesn't support key options", key.KeyType)
+		}
+		key.PublicKey, key.PrivateKey, err = suite.GenerateKey()
+	}
+	if err != nil {
+		return err
+	}
 
-		// Generate random rsa key
-		privateKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	key.RSAPSS = o.RSAPSS
 
-		// Get corresponding public key
-		publicKey := privateKey.Public()
+	return nil
 
-		// Marshall private key to []byte
-		key.PrivateKey = x509.MarshalPKCS1PrivateKey(privateKey)
+}
 
-		// Marshal public key to []byte
-		key.PublicKey = x509.MarshalPKCS1PublicKey(publicKey.(*rsa.PublicKey))
+// helper function that returns crypto.SignerOpts used by AbstractKey.Sign
+func (key *AbstractKey) signerOpts() (crypto.SignerOpts, error) {
 
+	if key.RSAPSS {
+		if key.KeyType != KeyTypeRSA {
+			return nil, fmt.Errorf("RSASSA-PSS requires %s", KeyTypeRSA)
+		}
+		return &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: crypto.SHA256}, nil
 	}
 
-	return key
+	return crypto.SHA256, nil
 
 }
 
+// HasPrivateKey returns true if AbstractKey contains a private key
+func (key *AbstractKey) HasPrivateKey() bool {
+	return len(key.PrivateKey) > 0
+}
+
 // SetPriorityRequirement sets PriorityRequirement for AbstractKey
 func (key *AbstractKey) SetPriorityRequirement(i int) *AbstractKey {
 	key.PriorityRequirement = &i
 	return key
 }
+
+// helper function that returns a deep copy of AbstractKey without PrivateKey
+func (key *AbstractKey) public() AbstractKey {
+
+	public := *key
+	public.PrivateKey = nil
+	public.PublicKey = append([]byte(nil), key.PublicKey...)
+
+	if key.PriorityRequirement != nil {
+		priorityRequirement := *key.PriorityRequirement
+		public.PriorityRequirement = &priorityRequirement
+	}
+
+	return public
+
+}
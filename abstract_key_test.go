@@ -1,8 +1,15 @@
 package factomdid
 
 import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"encoding/asn1"
+	"encoding/hex"
+	"math/big"
 	"testing"
 
+	"github.com/frankbraun/dcrd/dcrec/secp256k1"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -28,6 +35,27 @@ func TestAbstractKeys(t *testing.T) {
 	assert.NoError(t, err)
 	assert.True(t, verify)
 
+	// test P-256 key
+	p256 := &AbstractKey{}
+	p256.KeyType = KeyTypeECDSAP256
+	p256.generateRandomKeys()
+
+	err = validate.StructPartial(p256, "PrivateKey", "PublicKey")
+	assert.NoError(t, err)
+	assert.Equal(t, 32, len(p256.PrivateKey))
+	assert.Equal(t, 33, len(p256.PublicKey))
+
+	signature, err = p256.Sign([]byte("Test"))
+	assert.NoError(t, err)
+
+	verify, err = p256.Verify([]byte("Test"), signature)
+	assert.NoError(t, err)
+	assert.True(t, verify)
+
+	verify, err = p256.Verify([]byte("Test2"), signature)
+	assert.NoError(t, err)
+	assert.False(t, verify)
+
 	// test eddsa key
 	eddsa := &AbstractKey{}
 	eddsa.KeyType = KeyTypeEdDSA
@@ -59,3 +87,142 @@ func TestAbstractKeys(t *testing.T) {
 	assert.True(t, verify)
 
 }
+
+// TestVerifyStrict covers rejection of malleated signatures
+func TestVerifyStrict(t *testing.T) {
+
+	var err error
+	var v bool
+
+	message := []byte("Test")
+
+	// secp256k1 key with fixed private key, RFC 6979 signatures are deterministic
+	ecdsa := &AbstractKey{KeyType: KeyTypeECDSA}
+	ecdsa.PrivateKey, _ = hex.DecodeString("c9afa9d845ba75166b5c215767b1d6934e50c3db36e89b127b8a622b120f6721")
+	privKey, _ := secp256k1.PrivKeyFromBytes(secp256k1.S256(), ecdsa.PrivateKey)
+	x, y := privKey.Public()
+	ecdsa.PublicKey = secp256k1.NewPublicKey(secp256k1.S256(), x, y).Serialize()
+
+	signature, err := ecdsa.Sign(message)
+	assert.NoError(t, err)
+
+	v, err = ecdsa.VerifyStrict(message, signature)
+	assert.NoError(t, err)
+	assert.True(t, v)
+
+	sig, _ := secp256k1.ParseDERSignature(signature, secp256k1.S256())
+
+	// high S variant is accepted by Verify, but rejected by VerifyStrict
+	highS := encodeDERSignature(sig.R.Bytes(), new(big.Int).Sub(secp256k1.S256().N, sig.S).Bytes())
+	v, err = ecdsa.Verify(message, highS)
+	assert.NoError(t, err)
+	assert.True(t, v)
+	v, err = ecdsa.VerifyStrict(message, highS)
+	assert.Error(t, err)
+	assert.False(t, v)
+
+	// excessively padded R is accepted by Verify, but rejected by VerifyStrict
+	paddedR := encodeDERSignature(append([]byte{0x00, 0x00}, sig.R.Bytes()...), sig.S.Bytes())
+	v, err = ecdsa.Verify(message, paddedR)
+	assert.NoError(t, err)
+	assert.True(t, v)
+	v, err = ecdsa.VerifyStrict(message, paddedR)
+	assert.Error(t, err)
+	assert.False(t, v)
+
+	// trailing data is accepted by Verify, but rejected by VerifyStrict
+	trailing := append(append([]byte{}, signature...), 0x01)
+	v, err = ecdsa.Verify(message, trailing)
+	assert.NoError(t, err)
+	assert.True(t, v)
+	v, err = ecdsa.VerifyStrict(message, trailing)
+	assert.Error(t, err)
+	assert.False(t, v)
+
+	// P-256 signatures are low S and high S variant is rejected by VerifyStrict
+	p256 := &AbstractKey{KeyType: KeyTypeECDSAP256}
+	p256.generateRandomKeys()
+	for i := 0; i < 8; i++ {
+		signature, err = p256.Sign(message)
+		assert.NoError(t, err)
+		v, err = p256.VerifyStrict(message, signature)
+		assert.NoError(t, err)
+		assert.True(t, v)
+	}
+	p256Sig := &ecdsaSignature{}
+	asn1.Unmarshal(signature, p256Sig)
+	p256Sig.S.Sub(elliptic.P256().Params().N, p256Sig.S)
+	highS, _ = asn1.Marshal(*p256Sig)
+	v, err = p256.Verify(message, highS)
+	assert.NoError(t, err)
+	assert.True(t, v)
+	v, err = p256.VerifyStrict(message, highS)
+	assert.Error(t, err)
+	assert.False(t, v)
+
+	// Ed25519 key with RFC 8032 test 1 private key
+	eddsa := &AbstractKey{KeyType: KeyTypeEdDSA}
+	seed, _ := hex.DecodeString("9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60")
+	eddsa.PrivateKey = ed25519.NewKeyFromSeed(seed)
+	eddsa.PublicKey = ed25519.PrivateKey(eddsa.PrivateKey).Public().(ed25519.PublicKey)
+
+	signature, err = eddsa.Sign(message)
+	assert.NoError(t, err)
+	v, err = eddsa.VerifyStrict(message, signature)
+	assert.NoError(t, err)
+	assert.True(t, v)
+
+	// S + L variant
+	s := new(big.Int).SetBytes(reverse(signature[32:]))
+	s.Add(s, ed25519L)
+	malleated := append(append([]byte{}, signature[:32]...), reverse(s.FillBytes(make([]byte, 32)))...)
+	v, err = eddsa.VerifyStrict(message, malleated)
+	assert.Error(t, err)
+	assert.False(t, v)
+
+	// non-canonical R (y >= p)
+	nonCanonicalR := append([]byte{}, signature...)
+	copy(nonCanonicalR[:32], bytes.Repeat([]byte{0xff}, 32))
+	nonCanonicalR[31] = 0x7f
+	v, err = eddsa.VerifyStrict(message, nonCanonicalR)
+	assert.Error(t, err)
+	assert.False(t, v)
+
+	// RSA signature with leading zero
+	rsa := &AbstractKey{KeyType: KeyTypeRSA}
+	rsa.generateRandomKeys()
+	signature, err = rsa.Sign(message)
+	assert.NoError(t, err)
+	v, err = rsa.VerifyStrict(message, signature)
+	assert.NoError(t, err)
+	assert.True(t, v)
+	v, err = rsa.VerifyStrict(message, append([]byte{0x00}, signature...))
+	assert.Error(t, err)
+	assert.False(t, v)
+
+	// X25519 keys can't verify
+	x25519 := &AbstractKey{KeyType: KeyTypeX25519}
+	x25519.generateRandomKeys()
+	v, err = x25519.VerifyStrict(message, signature)
+	assert.Error(t, err)
+	assert.False(t, v)
+
+}
+
+// helper function that encodes DER signature from R and S bytes as is
+func encodeDERSignature(r []byte, s []byte) []byte {
+
+	if r[0]&0x80 != 0 {
+		r = append([]byte{0x00}, r...)
+	}
+	if s[0]&0x80 != 0 {
+		s = append([]byte{0x00}, s...)
+	}
+
+	b := []byte{0x30, byte(4 + len(r) + len(s)), 0x02, byte(len(r))}
+	b = append(b, r...)
+	b = append(b, 0x02, byte(len(s)))
+
+	return append(b, s...)
+
+}
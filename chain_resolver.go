@@ -0,0 +1,464 @@
+package factomdid
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/FactomProject/factom"
+)
+
+// ChainResolver resolves DID documents from DID chain entries fetched with Fetcher.
+// Entries are applied in chain order, invalid entries (e.g. with invalid signature, signed by revoked key
+// or with insufficient key priority) and replayed entries are ignored, as Factom DID spec requires
+type ChainResolver struct {
+	fetcher Fetcher
+	network string
+}
+
+// chainState is DID document being resolved from DID chain entries
+type chainState struct {
+	did         *DID
+	version     string
+	deactivated bool
+	processed   map[string]bool
+}
+
+// NewChainResolver creates ChainResolver of DIDs on network (NetworkMainnet, NetworkTestnet or NetworkUnspecified)
+func NewChainResolver(fetcher Fetcher, network string) *ChainResolver {
+
+	return &ChainResolver{fetcher: fetcher, network: network}
+
+}
+
+// Resolve resolves current DID document, returns error if DID doesn't exist, is invalid or deactivated
+func (r *ChainResolver) Resolve(did string) (*DID, error) {
+
+	return r.resolve(did, nil)
+
+}
+
+// ResolveAt resolves DID document as it was at time t, entries written after t are not applied
+func (r *ChainResolver) ResolveAt(did string, t time.Time) (*DID, error) {
+
+	return r.resolve(did, &t)
+
+}
+
+// helper function that fetches DID chain and applies its entries written before at (if not nil)
+func (r *ChainResolver) resolve(id string, at *time.Time) (*DID, error) {
+
+	if r.fetcher == nil {
+		return nil, fmt.Errorf("Fetcher is empty")
+	}
+
+	network, chainID, err := parseDID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if network != NetworkUnspecified && r.network != NetworkUnspecified && network != r.network {
+		return nil, fmt.Errorf("Can't resolve %s, resolver is on %s", id, r.network)
+	}
+
+	entries, err := r.fetcher.FetchChainEntries(chainID)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Height < entries[j].Height
+	})
+
+	if len(entries) == 0 || (at != nil && entries[0].Timestamp.After(*at)) {
+		return nil, fmt.Errorf("DID %s not found", id)
+	}
+
+	state, err := newChainState(chainID, entries[0].Entry)
+	if err != nil {
+		return nil, fmt.Errorf("DID %s is invalid: %v", id, err)
+	}
+
+	for _, v := range entries[1:] {
+		if at != nil && v.Timestamp.After(*at) {
+			break
+		}
+		// invalid entries are skipped
+		_ = state.apply(v.Entry)
+		if state.deactivated {
+			return nil, fmt.Errorf("DID %s is deactivated", id)
+		}
+	}
+
+	state.did.Network = network
+	if network == NetworkUnspecified {
+		state.did.Network = r.network
+	}
+
+	return state.did, nil
+
+}
+
+// helper function that parses DIDManagement entry, the first entry of DID chain
+func newChainState(chainID string, entry *factom.Entry) (*chainState, error) {
+
+	if entry == nil || len(entry.ExtIDs) < 2 || string(entry.ExtIDs[0]) != EntryTypeCreate {
+		return nil, fmt.Errorf("The first entry must be %s entry", EntryTypeCreate)
+	}
+
+	if factom.ChainIDFromFields(entry.ExtIDs) != chainID {
+		return nil, fmt.Errorf("The first entry ExtIDs don't match ChainID %s", chainID)
+	}
+
+	// decoder is selected by entry schema version of the entry
+	schema, err := GetEntrySchema(string(entry.ExtIDs[1]))
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := schema.DecodeManagement(entry.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	if !versionRegexp.MatchString(s.DIDMethodVersion) {
+		return nil, fmt.Errorf("Invalid DID method version %s", s.DIDMethodVersion)
+	}
+
+	did := &DID{}
+	did.ID = strings.Join([]string{DIDMethodName, chainID}, ":")
+	did.ExtIDs = entry.ExtIDs
+
+	for i := range s.ManagementKey {
+		key, err := managementKeyFromSchema(s.ManagementKey[i], did.ID)
+		if err != nil {
+			return nil, err
+		}
+		did.ManagementKeys = append(did.ManagementKeys, key)
+	}
+
+	for i := range s.DIDKey {
+		key, err := didKeyFromSchema(s.DIDKey[i], did.ID)
+		if err != nil {
+			return nil, err
+		}
+		did.DIDKeys = append(did.DIDKeys, key)
+	}
+
+	for i := range s.Service {
+		service, err := serviceFromSchema(s.Service[i], did.ID)
+		if err != nil {
+			return nil, err
+		}
+		did.Services = append(did.Services, service)
+	}
+
+	err = did.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	state := &chainState{did: did, version: s.DIDMethodVersion, processed: make(map[string]bool)}
+	state.processed[hex.EncodeToString(entry.Hash())] = true
+
+	return state, nil
+
+}
+
+// helper function that applies DID chain entry, returns error if entry is invalid and is not applied
+func (s *chainState) apply(entry *factom.Entry) error {
+
+	hash := hex.EncodeToString(entry.Hash())
+	if s.processed[hash] {
+		return fmt.Errorf("Entry %s is replayed", hash)
+	}
+	s.processed[hash] = true
+
+	if len(entry.ExtIDs) < 2 {
+		return fmt.Errorf("Entry must have entry type and entry schema ExtIDs")
+	}
+
+	// entries of one chain may be written with different entry schemas, decoder is selected by the entry
+	schema, err := GetEntrySchema(string(entry.ExtIDs[1]))
+	if err != nil {
+		return err
+	}
+
+	switch string(entry.ExtIDs[0]) {
+	case EntryTypeUpdate:
+		return s.applyUpdate(entry, schema)
+	case EntryTypeDeactivation:
+		return s.applyDeactivation(entry, schema)
+	case EntryTypeVersionUpgrade:
+		return s.applyVersionUpgrade(entry, schema)
+	}
+
+	return fmt.Errorf("Unknown entry type %s", entry.ExtIDs[0])
+
+}
+
+// helper function that applies DIDUpdate entry
+func (s *chainState) applyUpdate(entry *factom.Entry, schema EntrySchema) error {
+
+	update, err := schema.DecodeUpdate(entry.Content)
+	if err != nil {
+		return err
+	}
+
+	signingKey, err := s.checkSignedEntry(entry)
+	if err != nil {
+		return err
+	}
+
+	updated := s.did.Copy()
+	var reqPriority = math.MaxInt32
+
+	for _, v := range update.Revoke.ManagementKey {
+		alias, err := schemaIDAlias(v.ID, s.did.ID)
+		if err != nil {
+			return err
+		}
+		for _, key := range updated.ManagementKeys {
+			if key.Alias == alias && key.PriorityRequirement != nil {
+				reqPriority = min(*key.PriorityRequirement, reqPriority)
+			}
+		}
+		_, err = updated.RevokeManagementKey(alias)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, v := range update.Revoke.DIDKey {
+		alias, err := schemaIDAlias(v.ID, s.did.ID)
+		if err != nil {
+			return err
+		}
+		for _, key := range updated.DIDKeys {
+			if key.Alias == alias && key.PriorityRequirement != nil {
+				reqPriority = min(*key.PriorityRequirement, reqPriority)
+			}
+		}
+		_, err = updated.RevokeDIDKey(alias)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, v := range update.Revoke.Service {
+		alias, err := schemaIDAlias(v.ID, s.did.ID)
+		if err != nil {
+			return err
+		}
+		for _, service := range updated.Services {
+			if service.Alias == alias && service.PriorityRequirement != nil {
+				reqPriority = min(*service.PriorityRequirement, reqPriority)
+			}
+		}
+		_, err = updated.RevokeService(alias)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, v := range update.Add.ManagementKey {
+		key, err := managementKeyFromSchema(v, s.did.ID)
+		if err != nil {
+			return err
+		}
+		if key.PriorityRequirement != nil {
+			reqPriority = min(*key.PriorityRequirement, reqPriority)
+		}
+		updated.ManagementKeys = append(updated.ManagementKeys, key)
+	}
+
+	for _, v := range update.Add.DIDKey {
+		key, err := didKeyFromSchema(v, s.did.ID)
+		if err != nil {
+			return err
+		}
+		if key.PriorityRequirement != nil {
+			reqPriority = min(*key.PriorityRequirement, reqPriority)
+		}
+		updated.DIDKeys = append(updated.DIDKeys, key)
+	}
+
+	for _, v := range update.Add.Service {
+		service, err := serviceFromSchema(v, s.did.ID)
+		if err != nil {
+			return err
+		}
+		if service.PriorityRequirement != nil {
+			reqPriority = min(*service.PriorityRequirement, reqPriority)
+		}
+		updated.Services = append(updated.Services, service)
+	}
+
+	if signingKey.Priority > reqPriority {
+		return fmt.Errorf("The update requires a key with priority <= %d, but the signing key priority = %d", reqPriority, signingKey.Priority)
+	}
+
+	// at least one ManagementKey with priority 0 must remain, aliases must be unique
+	err = updated.Validate()
+	if err != nil {
+		return err
+	}
+
+	s.did = updated
+
+	return nil
+
+}
+
+// helper function that applies DIDDeactivation entry
+func (s *chainState) applyDeactivation(entry *factom.Entry, schema EntrySchema) error {
+
+	err := schema.ValidateContent(EntryTypeDeactivation, entry.Content)
+	if err != nil {
+		return err
+	}
+
+	signingKey, err := s.checkSignedEntry(entry)
+	if err != nil {
+		return err
+	}
+
+	if signingKey.Priority != 0 {
+		return fmt.Errorf("DID deactivation requires ManagementKey with 0 priority")
+	}
+
+	s.deactivated = true
+
+	return nil
+
+}
+
+// helper function that applies DIDMethodVersionUpgrade entry, the version can only increase
+func (s *chainState) applyVersionUpgrade(entry *factom.Entry, schema EntrySchema) error {
+
+	upgrade, err := schema.DecodeVersionUpgrade(entry.Content)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.checkSignedEntry(entry)
+	if err != nil {
+		return err
+	}
+
+	if !versionRegexp.MatchString(upgrade.DIDMethodVersion) {
+		return fmt.Errorf("Invalid DID method version %s", upgrade.DIDMethodVersion)
+	}
+
+	if compareVersions(upgrade.DIDMethodVersion, s.version) <= 0 {
+		return fmt.Errorf("DID method version %s is not greater than %s", upgrade.DIDMethodVersion, s.version)
+	}
+
+	s.version = upgrade.DIDMethodVersion
+
+	return nil
+
+}
+
+// helper function that checks ExtIDs and signature of entry signed with ManagementKey,
+// returns the signing key
+func (s *chainState) checkSignedEntry(entry *factom.Entry) (*ManagementKey, error) {
+
+	if len(entry.ExtIDs) != 4 {
+		return nil, fmt.Errorf("%s entry must have 4 ExtIDs", entry.ExtIDs[0])
+	}
+
+	alias, err := schemaIDAlias(string(entry.ExtIDs[2]), s.did.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var signingKey *ManagementKey
+	for _, v := range s.did.ManagementKeys {
+		if v.Alias == alias {
+			signingKey = v
+		}
+	}
+
+	if signingKey == nil {
+		return nil, fmt.Errorf("ManagementKey %s not found", entry.ExtIDs[2])
+	}
+
+	message := bytes.Join([][]byte{entry.ExtIDs[0], entry.ExtIDs[1], entry.ExtIDs[2], entry.Content}, nil)
+
+	valid, err := verifyEntrySignature(&signingKey.AbstractKey, message, entry.ExtIDs[3])
+	if err != nil {
+		return nil, err
+	}
+
+	if !valid {
+		return nil, fmt.Errorf("Invalid entry signature")
+	}
+
+	return signingKey, nil
+
+}
+
+// helper function that verifies entry signature, malleable signatures are rejected if key suite supports it
+func verifyEntrySignature(key *AbstractKey, message []byte, signature []byte) (bool, error) {
+
+	suite, err := GetKeySuite(key.KeyType)
+	if err != nil {
+		return false, err
+	}
+
+	if _, ok := suite.(StrictKeySuite); ok {
+		return key.VerifyStrict(message, signature)
+	}
+
+	return key.Verify(message, signature)
+
+}
+
+// helper function that returns alias of on-chain ID "did:factom:<chainID>#alias", which must belong to DID
+func schemaIDAlias(id string, DID string) (string, error) {
+
+	did, alias, err := SplitDIDURL(id)
+	if err != nil {
+		return "", err
+	}
+
+	_, chainID, _ := parseDID(did)
+	_, didChainID, err := parseDID(DID)
+	if err != nil {
+		return "", err
+	}
+
+	if chainID != didChainID {
+		return "", fmt.Errorf("%s doesn't belong to %s", id, DID)
+	}
+
+	return alias, nil
+
+}
+
+// helper function that compares "major.minor.patch" versions, returns -1, 0 or 1
+func compareVersions(a string, b string) int {
+
+	pa := strings.Split(a, ".")
+	pb := strings.Split(b, ".")
+
+	for i := 0; i < len(pa) && i < len(pb); i++ {
+		na, _ := strconv.Atoi(pa[i])
+		nb, _ := strconv.Atoi(pb[i])
+		if na != nb {
+			if na < nb {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	return 0
+
+}
@@ -0,0 +1,71 @@
+package factomdid
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/FactomProject/factom"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChainState(t *testing.T) {
+
+	did, _ := addTestKeys(t, NewDID(), "default-did-key", KeyTypeEdDSA, KeyPurposePublic)
+	create, err := did.Create()
+	assert.NoError(t, err)
+	lower, err := did.UpgradeVersion("0.1.0", "default-mgmt-key")
+	assert.NoError(t, err)
+	upgrade, err := did.UpgradeVersion("0.3.0", "default-mgmt-key")
+	assert.NoError(t, err)
+
+	// version can only increase
+	state, err := newChainState(did.GetChainID(), create)
+	assert.NoError(t, err)
+	assert.Error(t, state.apply(lower))
+	assert.NoError(t, state.apply(upgrade))
+	assert.Equal(t, "0.3.0", state.version)
+
+	// replayed entry
+	assert.Error(t, state.apply(upgrade))
+
+	// entry which has no ExtIDs
+	assert.Error(t, state.apply(&factom.Entry{ChainID: did.GetChainID()}))
+
+}
+
+func TestSchemaIDAlias(t *testing.T) {
+
+	did := "did:factom:301a57c2e753d061928cf6b6a692ea052885d75d2af5640e9b5cbc8897bbf7d5"
+
+	alias, err := schemaIDAlias(did+"#key-1", did)
+	assert.NoError(t, err)
+	assert.Equal(t, "key-1", alias)
+
+	alias, err = schemaIDAlias("did:factom:testnet:301a57c2e753d061928cf6b6a692ea052885d75d2af5640e9b5cbc8897bbf7d5#key-1", did)
+	assert.NoError(t, err)
+	assert.Equal(t, "key-1", alias)
+
+	_, err = schemaIDAlias(NewDID().ID+"#key-1", did)
+	assert.Error(t, err)
+
+	_, err = schemaIDAlias(did, did)
+	assert.Error(t, err)
+
+}
+
+func TestCompareVersions(t *testing.T) {
+
+	for _, c := range []struct {
+		a string
+		b string
+		r int
+	}{
+		{"0.2.0", "0.2.0", 0},
+		{"0.2.0", "0.3.0", -1},
+		{"0.10.0", "0.9.0", 1},
+		{"1.0.0", "0.99.99", 1},
+	} {
+		assert.Equal(t, c.r, compareVersions(c.a, c.b), fmt.Sprintf("%s %s", c.a, c.b))
+	}
+
+}
@@ -0,0 +1,147 @@
+package factomdid
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/FactomProject/factom"
+)
+
+// CommitReveal contains signed commit and reveal factomd API requests of DID entry.
+// Commit is "commit-chain" for the first entry of DID chain and "commit-entry" otherwise,
+// Reveal is "reveal-chain" or "reveal-entry" accordingly, Cost is Entry Credit cost paid by the commit.
+// Requests can be marshaled to JSON and submitted to factomd API v2 as is
+type CommitReveal struct {
+	ChainID   string               `json:"chainId" form:"chainId" query:"chainId"`
+	EntryHash string               `json:"entryHash" form:"entryHash" query:"entryHash"`
+	NewChain  bool                 `json:"newChain" form:"newChain" query:"newChain"`
+	Cost      int                  `json:"cost" form:"cost" query:"cost"`
+	Commit    *factom.JSON2Request `json:"commit" form:"commit" query:"commit"`
+	Reveal    *factom.JSON2Request `json:"reveal" form:"reveal" query:"reveal"`
+}
+
+// CreateCommitReveal generates DIDManagement entry and signs chain commit with Entry Credit address.
+// ChainID of the new chain is checked to match DID ChainID
+func (did *DID) CreateCommitReveal(ec *factom.ECAddress, opts ...EntryOption) (*CommitReveal, error) {
+
+	fe, err := did.Create(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	chain := factom.NewChain(fe)
+
+	if chain.ChainID != did.GetChainID() {
+		return nil, fmt.Errorf("DID ExtIDs don't match DID ChainID %s", did.GetChainID())
+	}
+
+	return newChainCommitReveal(chain, ec)
+
+}
+
+// UpdateCommitReveal generates DIDUpdate entry and signs entry commit with Entry Credit address
+func (did *DID) UpdateCommitReveal(updatedDID *DID, signingKeyAlias string, ec *factom.ECAddress, opts ...EntryOption) (*CommitReveal, error) {
+
+	fe, err := did.Update(updatedDID, signingKeyAlias, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return newEntryCommitReveal(fe, ec)
+
+}
+
+// DeactivateCommitReveal generates DIDDeactivation entry and signs entry commit with Entry Credit address
+func (did *DID) DeactivateCommitReveal(signingKeyAlias string, ec *factom.ECAddress, opts ...EntryOption) (*CommitReveal, error) {
+
+	fe, err := did.Deactivate(signingKeyAlias, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return newEntryCommitReveal(fe, ec)
+
+}
+
+// UpgradeVersionCommitReveal generates DIDMethodVersionUpgrade entry and signs entry commit with Entry Credit address
+func (did *DID) UpgradeVersionCommitReveal(version string, signingKeyAlias string, ec *factom.ECAddress, opts ...EntryOption) (*CommitReveal, error) {
+
+	fe, err := did.UpgradeVersion(version, signingKeyAlias, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return newEntryCommitReveal(fe, ec)
+
+}
+
+// helper function that composes commit-chain and reveal-chain requests
+func newChainCommitReveal(chain *factom.Chain, ec *factom.ECAddress) (*CommitReveal, error) {
+
+	if ec == nil {
+		return nil, fmt.Errorf("Entry Credit address is empty")
+	}
+
+	cost, err := EntryCost(chain.FirstEntry)
+	if err != nil {
+		return nil, err
+	}
+
+	commit, err := factom.ComposeChainCommit(chain, ec)
+	if err != nil {
+		return nil, err
+	}
+
+	reveal, err := factom.ComposeChainReveal(chain)
+	if err != nil {
+		return nil, err
+	}
+
+	cr := &CommitReveal{}
+	cr.ChainID = chain.ChainID
+	cr.EntryHash = hex.EncodeToString(chain.FirstEntry.Hash())
+	cr.NewChain = true
+	cr.Cost = cost + ChainCreationCost
+	cr.Commit = commit
+	cr.Reveal = reveal
+
+	return cr, nil
+
+}
+
+// helper function that composes commit-entry and reveal-entry requests
+func newEntryCommitReveal(fe *factom.Entry, ec *factom.ECAddress) (*CommitReveal, error) {
+
+	if ec == nil {
+		return nil, fmt.Errorf("Entry Credit address is empty")
+	}
+
+	if fe.ChainID == "" {
+		return nil, fmt.Errorf("Entry ChainID is empty")
+	}
+
+	cost, err := EntryCost(fe)
+	if err != nil {
+		return nil, err
+	}
+
+	commit, err := factom.ComposeEntryCommit(fe, ec)
+	if err != nil {
+		return nil, err
+	}
+
+	reveal, err := factom.ComposeEntryReveal(fe)
+	if err != nil {
+		return nil, err
+	}
+
+	cr := &CommitReveal{}
+	cr.ChainID = fe.ChainID
+	cr.EntryHash = hex.EncodeToString(fe.Hash())
+	cr.Cost = cost
+	cr.Commit = commit
+	cr.Reveal = reveal
+
+	return cr, nil
+
+}
@@ -0,0 +1,142 @@
+package factomdid
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/FactomProject/factom"
+	"github.com/stretchr/testify/assert"
+)
+
+// helper function that decodes hex param of factomd API request
+func decodeRequestParam(t *testing.T, req *factom.JSON2Request, name string) []byte {
+
+	params := make(map[string]string)
+	assert.NoError(t, json.Unmarshal(req.Params, &params))
+
+	b, err := hex.DecodeString(params[name])
+	assert.NoError(t, err)
+
+	return b
+
+}
+
+// helper function that computes sha256(sha256(data))
+func shad(data []byte) []byte {
+
+	h1 := sha256.Sum256(data)
+	h2 := sha256.Sum256(h1[:])
+
+	return h2[:]
+
+}
+
+// helper function that computes Factom entry hash sha256(sha512(data) + data)
+func entryHash(data []byte) []byte {
+
+	h1 := sha512.Sum512(data)
+	h2 := sha256.Sum256(append(h1[:], data...))
+
+	return h2[:]
+
+}
+
+func TestCreateCommitReveal(t *testing.T) {
+
+	ec, _ := factom.MakeECAddress(bytes.Repeat([]byte{1}, 32))
+	did, _ := addTestKeys(t, NewDID(), "default-did-key", KeyTypeEdDSA, KeyPurposePublic)
+
+	// no Entry Credit address
+	cr, err := did.CreateCommitReveal(nil)
+	assert.Nil(t, cr)
+	assert.Error(t, err)
+
+	cr, err = did.CreateCommitReveal(ec)
+	assert.NoError(t, err)
+	assert.True(t, cr.NewChain)
+	assert.Equal(t, did.GetChainID(), cr.ChainID)
+	assert.Equal(t, "commit-chain", cr.Commit.Method)
+	assert.Equal(t, "reveal-chain", cr.Reveal.Method)
+
+	// revealed entry is the first entry of DID chain
+	reveal := decodeRequestParam(t, cr.Reveal, "entry")
+	fe, _ := did.Create()
+	fe.ChainID = did.GetChainID()
+	expected, _ := fe.MarshalBinary()
+	assert.Equal(t, expected, reveal)
+	assert.Equal(t, cr.EntryHash, hex.EncodeToString(entryHash(reveal)))
+
+	// version, timestamp, chain ID hash, weld, entry hash, EC cost, EC public key, signature
+	commit := decodeRequestParam(t, cr.Commit, "message")
+	assert.Equal(t, 200, len(commit))
+	chainID, _ := hex.DecodeString(cr.ChainID)
+	assert.Equal(t, shad(chainID), commit[7:39])
+	assert.Equal(t, shad(append(fe.Hash(), chainID...)), commit[39:71])
+	assert.Equal(t, fe.Hash(), commit[71:103])
+	assert.Equal(t, byte(cr.Cost), commit[103])
+	assert.Equal(t, ec.PubBytes(), commit[104:136])
+	assert.True(t, ed25519.Verify(commit[104:136], commit[:104], commit[136:]))
+
+	// ExtIDs that don't match DID ChainID
+	did.ExtIDs[2] = []byte("other-nonce")
+	cr, err = did.CreateCommitReveal(ec)
+	assert.Nil(t, cr)
+	assert.Error(t, err)
+
+}
+
+func TestEntryCommitReveal(t *testing.T) {
+
+	ec, _ := factom.MakeECAddress(bytes.Repeat([]byte{1}, 32))
+	did, _ := addTestKeys(t, NewDID(), "default-did-key", KeyTypeEdDSA, KeyPurposePublic)
+
+	updatedDID := did.Copy()
+	service, _ := NewService("demo", "Demo", "https://demo.com")
+	updatedDID.AddService(service)
+
+	update, err := did.UpdateCommitReveal(updatedDID, "default-mgmt-key", ec)
+	assert.NoError(t, err)
+	deactivate, err := did.DeactivateCommitReveal("default-mgmt-key", ec)
+	assert.NoError(t, err)
+	upgrade, err := did.UpgradeVersionCommitReveal("0.3.0", "default-mgmt-key", ec)
+	assert.NoError(t, err)
+
+	for _, cr := range []*CommitReveal{update, deactivate, upgrade} {
+
+		assert.False(t, cr.NewChain)
+		assert.Equal(t, did.GetChainID(), cr.ChainID)
+		assert.Equal(t, "commit-entry", cr.Commit.Method)
+		assert.Equal(t, "reveal-entry", cr.Reveal.Method)
+
+		// version, chain ID, ExtIDs, content
+		reveal := decodeRequestParam(t, cr.Reveal, "entry")
+		assert.Equal(t, did.GetChainID(), hex.EncodeToString(reveal[1:33]))
+		hash := entryHash(reveal)
+		assert.Equal(t, cr.EntryHash, hex.EncodeToString(hash))
+
+		// version, timestamp, entry hash, EC cost, EC public key, signature
+		commit := decodeRequestParam(t, cr.Commit, "message")
+		assert.Equal(t, 136, len(commit))
+		assert.Equal(t, hash, commit[7:39])
+		assert.Equal(t, byte(cr.Cost), commit[39])
+		assert.Equal(t, ec.PubBytes(), commit[40:72])
+		assert.True(t, ed25519.Verify(commit[40:72], commit[:40], commit[72:]))
+
+	}
+
+	// invalid signing key
+	cr, err := did.DeactivateCommitReveal("not-existent-mgmt-key", ec)
+	assert.Nil(t, cr)
+	assert.Error(t, err)
+
+	// no Entry Credit address
+	cr, err = did.UpgradeVersionCommitReveal("0.3.0", "default-mgmt-key", nil)
+	assert.Nil(t, cr)
+	assert.Error(t, err)
+
+}
@@ -0,0 +1,84 @@
+package factomdid
+
+import (
+	"fmt"
+
+	"github.com/FactomProject/factom"
+)
+
+const (
+	// EntryHeaderSize is size of Factom Entry header (version, ChainID, ExtIDs size), it's not paid and not counted in MaxEntrySize
+	EntryHeaderSize = 35
+	// ChainCreationCost is Entry Credit cost of new chain, paid in addition to the cost of its first entry
+	ChainCreationCost = 10
+)
+
+// EntryCost calculates Entry Credit cost of Factom Entry with factom.EntryCost: 1 EC per started KB of content and ExtIDs, at least 1 EC
+func EntryCost(entry *factom.Entry) (int, error) {
+
+	if entry == nil {
+		return 0, fmt.Errorf("Entry is empty")
+	}
+
+	cost, err := factom.EntryCost(entry)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(cost), nil
+
+}
+
+// CreateCost calculates Entry Credit cost of DID creation: new chain and DIDManagement entry
+func (did *DID) CreateCost(opts ...EntryOption) (int, error) {
+
+	fe, err := did.Create(opts...)
+	if err != nil {
+		return 0, err
+	}
+
+	cost, err := EntryCost(fe)
+	if err != nil {
+		return 0, err
+	}
+
+	return cost + ChainCreationCost, nil
+
+}
+
+// UpdateCost calculates Entry Credit cost of DIDUpdate entry.
+// ECDSA signature size may differ by a few bytes between signings, use CommitReveal.Cost for the exact cost of the submitted entry
+func (did *DID) UpdateCost(updatedDID *DID, signingKeyAlias string, opts ...EntryOption) (int, error) {
+
+	fe, err := did.Update(updatedDID, signingKeyAlias, opts...)
+	if err != nil {
+		return 0, err
+	}
+
+	return EntryCost(fe)
+
+}
+
+// DeactivateCost calculates Entry Credit cost of DIDDeactivation entry
+func (did *DID) DeactivateCost(signingKeyAlias string, opts ...EntryOption) (int, error) {
+
+	fe, err := did.Deactivate(signingKeyAlias, opts...)
+	if err != nil {
+		return 0, err
+	}
+
+	return EntryCost(fe)
+
+}
+
+// UpgradeVersionCost calculates Entry Credit cost of DIDMethodVersionUpgrade entry
+func (did *DID) UpgradeVersionCost(version string, signingKeyAlias string, opts ...EntryOption) (int, error) {
+
+	fe, err := did.UpgradeVersion(version, signingKeyAlias, opts...)
+	if err != nil {
+		return 0, err
+	}
+
+	return EntryCost(fe)
+
+}
@@ -0,0 +1,90 @@
+package factomdid
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/FactomProject/factom"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEntryCost(t *testing.T) {
+
+	// one ExtID of 8 bytes is 10 bytes of payload
+	for _, c := range []struct {
+		contentSize int
+		cost        int
+	}{
+		{0, 1},
+		{1014, 1},
+		{1015, 2},
+		{2038, 2},
+		{2039, 3},
+		{10230, 10},
+	} {
+
+		entry := &factom.Entry{}
+		entry.ChainID = "301a57c2e753d061928cf6b6a692ea052885d75d2af5640e9b5cbc8897bbf7d5"
+		entry.ExtIDs = [][]byte{[]byte("DIDEntry")}
+		entry.Content = bytes.Repeat([]byte{1}, c.contentSize)
+
+		cost, err := EntryCost(entry)
+		assert.NoError(t, err)
+		assert.Equal(t, c.cost, cost)
+
+		binary, _ := entry.MarshalBinary()
+		assert.Equal(t, EntryHeaderSize+calculateEntrySize(entry), len(binary))
+
+	}
+
+	// entry size limit
+	entry := &factom.Entry{}
+	entry.ChainID = "301a57c2e753d061928cf6b6a692ea052885d75d2af5640e9b5cbc8897bbf7d5"
+	entry.ExtIDs = [][]byte{[]byte("DIDEntry")}
+	entry.Content = bytes.Repeat([]byte{1}, 10231)
+	_, err := EntryCost(entry)
+	assert.Error(t, err)
+
+	_, err = EntryCost(nil)
+	assert.Error(t, err)
+
+}
+
+func TestDIDCost(t *testing.T) {
+
+	ec, _ := factom.MakeECAddress(bytes.Repeat([]byte{1}, 32))
+	did, _ := addTestKeys(t, NewDID(), "default-did-key", KeyTypeEdDSA, KeyPurposePublic)
+
+	cost, err := did.CreateCost()
+	assert.NoError(t, err)
+	assert.Equal(t, 11, cost)
+
+	cr, _ := did.CreateCommitReveal(ec)
+	assert.Equal(t, cost, cr.Cost)
+
+	updatedDID := did.Copy()
+	for i := 0; i < 20; i++ {
+		service, _ := NewService(string(rune('a'+i))+"-service", "Demo", "https://demo.com/"+string(bytes.Repeat([]byte{'a'}, 50)))
+		updatedDID.AddService(service)
+	}
+
+	cost, err = did.UpdateCost(updatedDID, "default-mgmt-key")
+	assert.NoError(t, err)
+	assert.Equal(t, 5, cost)
+
+	cr, _ = did.UpdateCommitReveal(updatedDID, "default-mgmt-key", ec)
+	assert.Equal(t, cost, cr.Cost)
+
+	cost, err = did.DeactivateCost("default-mgmt-key")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, cost)
+
+	cost, err = did.UpgradeVersionCost("0.3.0", "default-mgmt-key")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, cost)
+
+	// invalid signing key
+	_, err = did.DeactivateCost("not-existent-mgmt-key")
+	assert.Error(t, err)
+
+}
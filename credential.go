@@ -0,0 +1,332 @@
+package factomdid
+
+import (
+	"fmt"
+	"time"
+)
+
+// VerifiableCredential describes W3C Verifiable Credential issued by Factom DID
+type VerifiableCredential struct {
+	Context           []string               `json:"@context" form:"@context" query:"@context"`
+	ID                string                 `json:"id,omitempty" form:"id" query:"id"`
+	Type              []string               `json:"type" form:"type" query:"type"`
+	Issuer            string                 `json:"issuer" form:"issuer" query:"issuer"`
+	IssuanceDate      string                 `json:"issuanceDate" form:"issuanceDate" query:"issuanceDate"`
+	ExpirationDate    string                 `json:"expirationDate,omitempty" form:"expirationDate" query:"expirationDate"`
+	CredentialSubject map[string]interface{} `json:"credentialSubject" form:"credentialSubject" query:"credentialSubject"`
+	Proof             *Proof                 `json:"proof,omitempty" form:"proof" query:"proof"`
+}
+
+// CredentialOption configures verification of verifiable credentials and presentations
+type CredentialOption func(*credentialOptions)
+
+type credentialOptions struct {
+	atIssuanceTime       bool
+	withoutHolderBinding bool
+}
+
+// credentialJWTClaims are JWT-VC claims, see VC Data Model "JWT and JWS Considerations"
+type credentialJWTClaims struct {
+	JWTClaims
+	VC *VerifiableCredential `json:"vc"`
+}
+
+const (
+	// VCContext is JSON-LD context of W3C Verifiable Credentials
+	VCContext = "https://www.w3.org/2018/credentials/v1"
+	// VCType is type of every verifiable credential
+	VCType = "VerifiableCredential"
+)
+
+// AtIssuanceTime makes verification resolve issuer DID document as it was at issuance time,
+// so credentials stay valid after the issuer key is revoked. Resolver must be HistoricalResolver
+func AtIssuanceTime() CredentialOption {
+	return func(o *credentialOptions) {
+		o.atIssuanceTime = true
+	}
+}
+
+// WithoutHolderBinding makes presentation verification accept credentials whose credentialSubject.id
+// isn't the presentation holder, e.g. credentials about another subject presented on its behalf
+func WithoutHolderBinding() CredentialOption {
+	return func(o *credentialOptions) {
+		o.withoutHolderBinding = true
+	}
+}
+
+// NewCredential generates new unsigned verifiable credential of credentialType issued now by issuer DID
+func NewCredential(issuer string, credentialType string, subject map[string]interface{}) (*VerifiableCredential, error) {
+
+	_, _, err := parseDID(issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	if credentialType == "" {
+		return nil, fmt.Errorf("Credential type is empty")
+	}
+
+	if len(subject) == 0 {
+		return nil, fmt.Errorf("Credential subject is empty")
+	}
+
+	vc := &VerifiableCredential{}
+	vc.Context = []string{VCContext}
+	vc.Type = []string{VCType}
+	if credentialType != VCType {
+		vc.Type = append(vc.Type, credentialType)
+	}
+	vc.Issuer = issuer
+	vc.IssuanceDate = time.Now().UTC().Format(time.RFC3339)
+	vc.CredentialSubject = subject
+
+	return vc, nil
+
+}
+
+// SetID sets ID of verifiable credential
+func (vc *VerifiableCredential) SetID(id string) *VerifiableCredential {
+
+	vc.ID = id
+
+	return vc
+
+}
+
+// SetIssuanceDate sets issuance date of verifiable credential
+func (vc *VerifiableCredential) SetIssuanceDate(t time.Time) *VerifiableCredential {
+
+	vc.IssuanceDate = t.UTC().Format(time.RFC3339)
+
+	return vc
+
+}
+
+// SetExpirationDate sets expiration date of verifiable credential
+func (vc *VerifiableCredential) SetExpirationDate(t time.Time) *VerifiableCredential {
+
+	vc.ExpirationDate = t.UTC().Format(time.RFC3339)
+
+	return vc
+
+}
+
+// IssueCredential signs verifiable credential with DIDKey of the issuer and returns a copy with embedded proof
+// (JcsJsonWebSignature2020 by default, see WithProofType).
+// DIDKey must have publicKey purpose (assertionMethod)
+func (didkey *DIDKey) IssueCredential(vc *VerifiableCredential, opts ...ProofOption) (*VerifiableCredential, error) {
+
+	err := didkey.checkIssuer(vc)
+	if err != nil {
+		return nil, err
+	}
+
+	signed := *vc
+	signed.Proof, err = didkey.createProof(Proof{ProofPurpose: ProofPurposeAssertionMethod}, signed.marshalWithProof, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &signed, nil
+
+}
+
+// IssueCredentialJWT signs verifiable credential with DIDKey of the issuer and returns JWT-VC.
+// DIDKey must have publicKey purpose (assertionMethod)
+func (didkey *DIDKey) IssueCredentialJWT(vc *VerifiableCredential) (string, error) {
+
+	err := didkey.checkIssuer(vc)
+	if err != nil {
+		return "", err
+	}
+
+	if !didkey.HasPurpose(KeyPurposePublic) {
+		return "", fmt.Errorf("DIDKey %s must have %s purpose to issue credentials", didkey.Alias, KeyPurposePublic)
+	}
+
+	issuanceDate, expirationDate, err := vc.dates()
+	if err != nil {
+		return "", err
+	}
+
+	claims := &credentialJWTClaims{}
+	claims.Issuer = vc.Issuer
+	claims.ID = vc.ID
+	claims.NotBefore = issuanceDate.Unix()
+	if !expirationDate.IsZero() {
+		claims.ExpiresAt = expirationDate.Unix()
+	}
+	if subject, ok := vc.CredentialSubject["id"].(string); ok {
+		claims.Subject = subject
+	}
+
+	unsigned := *vc
+	unsigned.Proof = nil
+	claims.VC = &unsigned
+
+	return didkey.SignJWT(claims)
+
+}
+
+// VerifyCredential verifies embedded proof of verifiable credential, issuer DID is resolved using resolver.
+// Returns the issuer key which signed the credential
+func VerifyCredential(vc *VerifiableCredential, resolver Resolver, opts ...CredentialOption) (*DIDKey, error) {
+
+	o := &credentialOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if vc == nil {
+		return nil, fmt.Errorf("Credential is empty")
+	}
+
+	issuanceDate, expirationDate, err := vc.dates()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	if now.Before(issuanceDate) {
+		return nil, fmt.Errorf("Credential is not valid yet")
+	}
+
+	if !expirationDate.IsZero() && !now.Before(expirationDate) {
+		return nil, fmt.Errorf("Credential is expired")
+	}
+
+	if o.atIssuanceTime {
+		resolver, err = resolverAt(resolver, issuanceDate)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	key, did, err := verifyProof(vc.Proof, ProofPurposeAssertionMethod, resolver, vc.marshalWithProof)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = did.Resolve(vc.Issuer)
+	if err != nil {
+		return nil, fmt.Errorf("Credential issuer %s is not DID of signing key %s", vc.Issuer, vc.Proof.VerificationMethod)
+	}
+
+	return key, nil
+
+}
+
+// VerifyCredentialJWT verifies JWT-VC, issuer DID is resolved using resolver.
+// Returns verifiable credential (without proof) and the issuer key which signed it
+func VerifyCredentialJWT(token string, resolver Resolver, opts ...CredentialOption) (*VerifiableCredential, *DIDKey, error) {
+
+	o := &credentialOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if o.atIssuanceTime {
+
+		// issuance time is taken from unverified claims, the signature is verified against keys valid at that time
+		unverified := &credentialJWTClaims{}
+		err := unmarshalUnverifiedJWT(token, unverified)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if unverified.NotBefore == 0 {
+			return nil, nil, fmt.Errorf("JWT-VC has no nbf claim")
+		}
+
+		resolver, err = resolverAt(resolver, time.Unix(unverified.NotBefore, 0))
+		if err != nil {
+			return nil, nil, err
+		}
+
+	}
+
+	claims := &credentialJWTClaims{}
+	key, err := VerifyJWT(token, resolver, KeyPurposePublic, claims)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	vc := claims.VC
+	if vc == nil {
+		return nil, nil, fmt.Errorf("JWT-VC has no vc claim")
+	}
+
+	if claims.Issuer == "" || claims.NotBefore == 0 {
+		return nil, nil, fmt.Errorf("JWT-VC must have iss and nbf claims")
+	}
+
+	// JWT claims take precedence over credential properties, which must match them if present
+	if vc.Issuer != "" && vc.Issuer != claims.Issuer {
+		return nil, nil, fmt.Errorf("JWT-VC iss doesn't match credential issuer")
+	}
+	if vc.ID != "" && vc.ID != claims.ID {
+		return nil, nil, fmt.Errorf("JWT-VC jti doesn't match credential id")
+	}
+
+	vc.Issuer = claims.Issuer
+	vc.ID = claims.ID
+	vc.IssuanceDate = time.Unix(claims.NotBefore, 0).UTC().Format(time.RFC3339)
+	if claims.ExpiresAt != 0 {
+		vc.ExpirationDate = time.Unix(claims.ExpiresAt, 0).UTC().Format(time.RFC3339)
+	}
+	vc.Proof = nil
+
+	return vc, key, nil
+
+}
+
+// helper function that checks that verifiable credential is issued by DID of DIDKey
+func (didkey *DIDKey) checkIssuer(vc *VerifiableCredential) error {
+
+	if vc == nil {
+		return fmt.Errorf("Credential is empty")
+	}
+
+	if didkey.Controller == "" {
+		return fmt.Errorf("DIDKey %s has no controller, add it to DID document first", didkey.Alias)
+	}
+
+	_, err := (&DID{ID: didkey.Controller}).Resolve(vc.Issuer)
+	if err != nil {
+		return fmt.Errorf("Credential issuer %s is not DID of DIDKey %s", vc.Issuer, didkey.Alias)
+	}
+
+	return nil
+
+}
+
+// helper function that parses issuance and expiration dates, expiration date is zero if not set
+func (vc *VerifiableCredential) dates() (time.Time, time.Time, error) {
+
+	var expirationDate time.Time
+
+	issuanceDate, err := time.Parse(time.RFC3339, vc.IssuanceDate)
+	if err != nil {
+		return issuanceDate, expirationDate, fmt.Errorf("Invalid credential issuanceDate: %v", err)
+	}
+
+	if vc.ExpirationDate != "" {
+		expirationDate, err = time.Parse(time.RFC3339, vc.ExpirationDate)
+		if err != nil {
+			return issuanceDate, expirationDate, fmt.Errorf("Invalid credential expirationDate: %v", err)
+		}
+	}
+
+	return issuanceDate, expirationDate, nil
+
+}
+
+// helper function that marshals verifiable credential with the proof, canonical (RFC 8785) JSON is signed payload of embedded proof
+func (vc VerifiableCredential) marshalWithProof(proof *Proof) ([]byte, error) {
+
+	vc.Proof = proof
+
+	return MarshalCanonicalJSON(&vc)
+
+}
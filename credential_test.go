@@ -0,0 +1,184 @@
+package factomdid
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testHistoricalResolver resolves DID document as it was before revokedAt, and current DID document after
+type testHistoricalResolver struct {
+	before    *DID
+	current   *DID
+	revokedAt time.Time
+}
+
+func (r *testHistoricalResolver) Resolve(did string) (*DID, error) {
+	return r.current.Resolve(did)
+}
+
+func (r *testHistoricalResolver) ResolveAt(did string, t time.Time) (*DID, error) {
+	if t.Before(r.revokedAt) {
+		return r.before.Resolve(did)
+	}
+	return r.current.Resolve(did)
+}
+
+func TestNewCredential(t *testing.T) {
+
+	did := NewDID()
+
+	vc, err := NewCredential(did.ID, "KYCCredential", map[string]interface{}{"id": "did:factom:subject", "kyc": true})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{VCContext}, vc.Context)
+	assert.Equal(t, []string{VCType, "KYCCredential"}, vc.Type)
+	assert.Equal(t, did.ID, vc.Issuer)
+	assert.NotEmpty(t, vc.IssuanceDate)
+
+	_, err = NewCredential("did:example:123", "KYCCredential", map[string]interface{}{"kyc": true})
+	assert.Error(t, err)
+
+	_, err = NewCredential(did.ID, "", map[string]interface{}{"kyc": true})
+	assert.Error(t, err)
+
+	_, err = NewCredential(did.ID, "KYCCredential", nil)
+	assert.Error(t, err)
+
+}
+
+func TestIssueCredential(t *testing.T) {
+
+	did, key := addTestKeys(t, NewDID(), "issuer-key", KeyTypeEdDSA, KeyPurposePublic)
+
+	vc, _ := NewCredential(did.ID, "KYCCredential", map[string]interface{}{"id": "did:factom:subject", "kyc": true, "level": 2})
+	vc.SetID("urn:uuid:1").SetExpirationDate(time.Now().Add(time.Hour))
+
+	signed, err := key.IssueCredential(vc)
+	assert.NoError(t, err)
+	assert.Nil(t, vc.Proof)
+	assert.Equal(t, ProofTypeJcsJSONWebSignature2020, signed.Proof.Type)
+	assert.Equal(t, ProofPurposeAssertionMethod, signed.Proof.ProofPurpose)
+	assert.Equal(t, did.ID+"#issuer-key", signed.Proof.VerificationMethod)
+
+	// verify credential transferred as JSON
+	data, _ := json.Marshal(signed)
+	received := &VerifiableCredential{}
+	json.Unmarshal(data, received)
+
+	issuerKey, err := VerifyCredential(received, did.Public())
+	assert.NoError(t, err)
+	assert.Equal(t, key.Alias, issuerKey.Alias)
+
+	// JsonWebSignature2020 (URDNA2015) proofs are not supported
+	jws := *received
+	jwsProof := *received.Proof
+	jwsProof.Type = "JsonWebSignature2020"
+	jws.Proof = &jwsProof
+	_, err = VerifyCredential(&jws, did.Public())
+	assert.Error(t, err)
+	_, err = key.IssueCredential(vc, WithProofType("JsonWebSignature2020"))
+	assert.Error(t, err)
+
+	// tampered subject
+	received.CredentialSubject["level"] = 3
+	_, err = VerifyCredential(received, did)
+	assert.Error(t, err)
+
+	// tampered proof purpose
+	tampered := *signed
+	proof := *signed.Proof
+	proof.ProofPurpose = ProofPurposeAuthentication
+	tampered.Proof = &proof
+	_, err = VerifyCredential(&tampered, did)
+	assert.Error(t, err)
+
+	// expired
+	expired, _ := key.IssueCredential(vc.SetExpirationDate(time.Now().Add(-time.Second)))
+	_, err = VerifyCredential(expired, did)
+	assert.Error(t, err)
+
+	// no proof
+	_, err = VerifyCredential(vc, did)
+	assert.Error(t, err)
+
+	// issuer is not DID of the key
+	other, _ := NewCredential(NewDID().ID, "KYCCredential", map[string]interface{}{"kyc": true})
+	_, err = key.IssueCredential(other)
+	assert.Error(t, err)
+
+	// key must have publicKey purpose
+	authKey, _ := NewDIDKey("auth-key", KeyTypeEdDSA)
+	authKey.AddPurpose(KeyPurposeAuthentication)
+	did.AddDIDKey(authKey)
+	_, err = authKey.IssueCredential(vc)
+	assert.Error(t, err)
+	_, err = authKey.IssueCredentialJWT(vc)
+	assert.Error(t, err)
+
+}
+
+func TestIssueCredentialJWT(t *testing.T) {
+
+	did, key := addTestKeys(t, NewDID(), "issuer-key", KeyTypeEdDSA, KeyPurposePublic)
+
+	vc, _ := NewCredential(did.ID, "KYCCredential", map[string]interface{}{"id": "did:factom:subject", "kyc": true})
+	vc.SetID("urn:uuid:1").SetIssuanceDate(time.Now().Add(-time.Minute)).SetExpirationDate(time.Now().Add(time.Hour))
+
+	token, err := key.IssueCredentialJWT(vc)
+	assert.NoError(t, err)
+
+	verified, issuerKey, err := VerifyCredentialJWT(token, did)
+	assert.NoError(t, err)
+	assert.Equal(t, key.Alias, issuerKey.Alias)
+	assert.Equal(t, vc, verified)
+
+	// expired
+	vc.SetExpirationDate(time.Now().Add(-time.Second))
+	token, _ = key.IssueCredentialJWT(vc)
+	_, _, err = VerifyCredentialJWT(token, did)
+	assert.Error(t, err)
+
+	// plain JWT is not JWT-VC
+	token, _ = key.SignJWT(&JWTClaims{Issuer: did.ID})
+	_, _, err = VerifyCredentialJWT(token, did)
+	assert.Error(t, err)
+
+}
+
+func TestVerifyCredentialAtIssuanceTime(t *testing.T) {
+
+	did, key := addTestKeys(t, NewDID(), "issuer-key", KeyTypeEdDSA, KeyPurposePublic)
+	newKey, _ := NewDIDKey("new-issuer-key", KeyTypeECDSA)
+	newKey.AddPurpose(KeyPurposePublic)
+
+	vc, _ := NewCredential(did.ID, "KYCCredential", map[string]interface{}{"kyc": true})
+	vc.SetIssuanceDate(time.Now().Add(-time.Hour))
+	signed, _ := key.IssueCredential(vc)
+	token, _ := key.IssueCredentialJWT(vc)
+
+	// issuer key is revoked after issuance
+	updated := did.Copy()
+	updated.AddDIDKey(newKey)
+	updated.RevokeDIDKey(key.Alias)
+	resolver := &testHistoricalResolver{before: did, current: updated, revokedAt: time.Now().Add(-time.Minute)}
+
+	_, err := VerifyCredential(signed, resolver)
+	assert.Error(t, err)
+	_, _, err = VerifyCredentialJWT(token, resolver)
+	assert.Error(t, err)
+
+	_, err = VerifyCredential(signed, resolver, AtIssuanceTime())
+	assert.NoError(t, err)
+	_, _, err = VerifyCredentialJWT(token, resolver, AtIssuanceTime())
+	assert.NoError(t, err)
+
+	// resolver must support resolution at time
+	_, err = VerifyCredential(signed, ResolverFunc(func(id string) (*DID, error) {
+		return nil, fmt.Errorf("DID %s not found", id)
+	}), AtIssuanceTime())
+	assert.Error(t, err)
+
+}
@@ -1,8 +1,9 @@
 package factomdid
 
 import (
-	"encoding/json"
+	"crypto/rand"
 	"fmt"
+	"io"
 	"math"
 	"strings"
 
@@ -43,39 +44,93 @@ const (
 
 	// DIDMethodName is method name for Factom DID, used in DID Document
 	DIDMethodName = "did:factom"
-	// MaxEntrySize is maximum size of Factom Entry
+	// MaxEntrySize is maximum size of Factom Entry excluding 35 bytes entry header
 	MaxEntrySize = 10240
+	// NonceSize is size of random nonce ExtID of the first entry of DID chain
+	NonceSize = 64
+	// MinNonceSize is minimum size of nonce accepted by NewDIDWithNonce
+	MinNonceSize = 32
+	// MaxNonceSize is maximum size of nonce accepted by NewDIDWithNonce
+	MaxNonceSize = 256
 
 	// Versions
 
 	// EntrySchemaV100 is version 1.0.0 of entry schema
 	EntrySchemaV100 = "1.0.0"
+	// EntrySchemaV100Ext is entry schema 1.0.0 extended with ECDSASecp256r1VerificationKey and X25519KeyAgreementKey
+	// key types and keyAgreement purpose.
+	// It isn't defined by Factom DID spec, other implementations don't accept its entries. Its version is namespaced
+	// (build metadata of 1.0.0), so it never clashes with entry schema versions of the spec
+	EntrySchemaV100Ext = "1.0.0+factomdid-ext"
 	// DIDMethodSpecV020 is version 0.2.0 of DID specification
 	DIDMethodSpecV020 = "0.2.0"
 
 	// Latest versions
 
-	// LatestEntrySchema is latest available entry schema
+	// LatestEntrySchema is latest entry schema defined by Factom DID spec, used by default
 	LatestEntrySchema = EntrySchemaV100
 	// LatestDIDMethodSpec is latest available DID specification version
 	LatestDIDMethodSpec = DIDMethodSpecV020
 )
 
-// NewDID generates new blank DID document.
+// NewDID generates new blank DID document with random nonce from crypto/rand.
 // DID.ExtIDs is a helper field that stores ExtIDs to be written on-chain to get expected ChainID for new DID chain.
 // DID.ExtIDs is not a part of DID Document (JSON)
 func NewDID() *DID {
 
+	d, err := NewDIDFromReader(rand.Reader)
+	if err != nil {
+		// crypto/rand never fails on supported platforms
+		panic(err)
+	}
+
+	return d
+
+}
+
+// NewDIDFromReader generates new blank DID document with NonceSize bytes nonce read from r.
+// Use deterministic reader for reproducible test vectors only, DID chain ID is predictable otherwise
+func NewDIDFromReader(r io.Reader, opts ...EntryOption) (*DID, error) {
+
+	if r == nil {
+		return nil, fmt.Errorf("Nonce reader is empty")
+	}
+
+	nonce, err := generateNonce(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewDIDWithNonce(nonce, opts...)
+
+}
+
+// NewDIDWithNonce generates new blank DID document with the given nonce of MinNonceSize-MaxNonceSize bytes.
+// Nonce defines DID chain ID, so it must be unpredictable unless DID is a test vector.
+// Entry schema of DIDManagement entry (LatestEntrySchema by default) is a part of chain ID, see WithEntrySchema
+func NewDIDWithNonce(nonce []byte, opts ...EntryOption) (*DID, error) {
+
+	err := validateNonce(nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	o := newEntryOptions(opts)
+	_, err = GetEntrySchema(o.entrySchema)
+	if err != nil {
+		return nil, err
+	}
+
 	d := &DID{}
 	d.ExtIDs = append(d.ExtIDs, []byte(EntryTypeCreate))
-	d.ExtIDs = append(d.ExtIDs, []byte(LatestEntrySchema))
-	d.ExtIDs = append(d.ExtIDs, generateNonce())
+	d.ExtIDs = append(d.ExtIDs, []byte(o.entrySchema))
+	d.ExtIDs = append(d.ExtIDs, append([]byte(nil), nonce...))
 
 	// d.ExtIDs is not nil, so no need to check for error
-	chainID, _ := calculateChainID(d.ExtIDs)
+	chainID, _ := calculateChainID(o.entrySchema, d.ExtIDs[2:])
 	d.ID = strings.Join([]string{DIDMethodName, chainID}, ":")
 
-	return d
+	return d, nil
 
 }
 
@@ -123,7 +178,12 @@ func (did *DID) AddDIDKey(key *DIDKey) (*DID, error) {
 	key.Controller = did.ID
 
 	// exclude PrivateKey from validation in case you have PublicKey only to verify signatures
-	err := validate.StructExcept(key, "PrivateKey")
+	err := validate.StructExcept(key, "AbstractKey.PrivateKey")
+	if err != nil {
+		return nil, err
+	}
+
+	err = key.checkPurpose()
 	if err != nil {
 		return nil, err
 	}
@@ -146,7 +206,12 @@ func (did *DID) AddManagementKey(key *ManagementKey) (*DID, error) {
 	key.Controller = did.ID
 
 	// exclude PrivateKey from validation in case you have PublicKey only to verify signatures
-	err := validate.StructExcept(key, "PrivateKey")
+	err := validate.StructExcept(key, "AbstractKey.PrivateKey")
+	if err != nil {
+		return nil, err
+	}
+
+	err = key.checkKeyType()
 	if err != nil {
 		return nil, err
 	}
@@ -252,8 +317,9 @@ func (did *DID) RevokeService(alias string) (*DID, error) {
 
 }
 
-// Create generates DIDManagement Factom Entry from DID document
-func (did *DID) Create() (*factom.Entry, error) {
+// Create generates DIDManagement Factom Entry from DID document.
+// Entry schema of DIDManagement entry is set by DID.ExtIDs, WithEntrySchema may only confirm it
+func (did *DID) Create(opts ...EntryOption) (*factom.Entry, error) {
 
 	// validate DID document
 	err := did.Validate()
@@ -261,6 +327,24 @@ func (did *DID) Create() (*factom.Entry, error) {
 		return nil, err
 	}
 
+	if len(did.ExtIDs) < 2 {
+		return nil, fmt.Errorf("DID ExtIDs are empty")
+	}
+
+	// entry schema is a part of DID chain ID, so it can't be changed after the DID was generated
+	o := &entryOptions{entrySchema: string(did.ExtIDs[1])}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.entrySchema != string(did.ExtIDs[1]) {
+		return nil, fmt.Errorf("DID chain ID is calculated with entry schema %s, can't create it with entry schema %s", did.ExtIDs[1], o.entrySchema)
+	}
+
+	schema, err := GetEntrySchema(o.entrySchema)
+	if err != nil {
+		return nil, err
+	}
+
 	// fill entry schema
 	s := &DIDManagementEntrySchema{}
 	sD := &DIDKeySchema{}
@@ -295,7 +379,7 @@ func (did *DID) Create() (*factom.Entry, error) {
 
 	fe := &factom.Entry{}
 	fe.ExtIDs = did.ExtIDs
-	fe.Content, err = json.Marshal(s)
+	fe.Content, err = schema.EncodeManagement(s)
 
 	if err != nil {
 		return nil, err
@@ -310,7 +394,7 @@ func (did *DID) Create() (*factom.Entry, error) {
 }
 
 // Update compares existing and updated DID documents and generates DIDUpdate Factom Entry signed with ManagementKey
-func (did *DID) Update(updatedDID *DID, signingKeyAlias string) (*factom.Entry, error) {
+func (did *DID) Update(updatedDID *DID, signingKeyAlias string, opts ...EntryOption) (*factom.Entry, error) {
 
 	// validate existing DID document
 	err := did.Validate()
@@ -318,6 +402,12 @@ func (did *DID) Update(updatedDID *DID, signingKeyAlias string) (*factom.Entry,
 		return nil, err
 	}
 
+	o := newEntryOptions(opts)
+	schema, err := GetEntrySchema(o.entrySchema)
+	if err != nil {
+		return nil, err
+	}
+
 	// validate updated DID document
 	err = updatedDID.Validate()
 	if err != nil {
@@ -499,7 +589,7 @@ func (did *DID) Update(updatedDID *DID, signingKeyAlias string) (*factom.Entry,
 		}
 	}
 
-	err = validate.StructPartial(signingKey, "Alias", "PrivateKey")
+	err = validate.StructPartial(signingKey, "AbstractKey.Alias", "AbstractKey.PrivateKey")
 
 	if err != nil {
 		return nil, err
@@ -510,13 +600,14 @@ func (did *DID) Update(updatedDID *DID, signingKeyAlias string) (*factom.Entry,
 		return nil, fmt.Errorf("The update requires a key with priority <= %d, but the provided signing key priority = %d", reqPriority, signingKey.Priority)
 	}
 
-	entryContent, err := json.Marshal(update)
+	entryContent, err := schema.EncodeUpdate(update)
 	if err != nil {
 		return nil, err
 	}
 
 	signingKeyFullID := strings.Join([]string{did.ID, signingKey.Alias}, "#")
-	signature, err := signingKey.Sign([]byte(strings.Join([]string{EntryTypeUpdate, LatestEntrySchema, signingKeyFullID, string(entryContent)}, "")))
+	// entries are always signed with the spec-defined mode
+	signature, err := signingKey.SignWithMode([]byte(strings.Join([]string{EntryTypeUpdate, o.entrySchema, signingKeyFullID, string(entryContent)}, "")), SignatureModePrehashed)
 
 	if err != nil {
 		return nil, err
@@ -525,7 +616,7 @@ func (did *DID) Update(updatedDID *DID, signingKeyAlias string) (*factom.Entry,
 	fe := &factom.Entry{}
 	fe.ChainID = did.GetChainID()
 	fe.ExtIDs = append(fe.ExtIDs, []byte(EntryTypeUpdate))
-	fe.ExtIDs = append(fe.ExtIDs, []byte(LatestEntrySchema))
+	fe.ExtIDs = append(fe.ExtIDs, []byte(o.entrySchema))
 	fe.ExtIDs = append(fe.ExtIDs, []byte(signingKeyFullID))
 	fe.ExtIDs = append(fe.ExtIDs, signature)
 
@@ -540,7 +631,7 @@ func (did *DID) Update(updatedDID *DID, signingKeyAlias string) (*factom.Entry,
 }
 
 // Deactivate generates DIDDeactivation Factom Entry signed with ManagementKey (priority=0 key required)
-func (did *DID) Deactivate(signingKeyAlias string) (*factom.Entry, error) {
+func (did *DID) Deactivate(signingKeyAlias string, opts ...EntryOption) (*factom.Entry, error) {
 
 	// validate existing DID document
 	err := did.Validate()
@@ -548,6 +639,12 @@ func (did *DID) Deactivate(signingKeyAlias string) (*factom.Entry, error) {
 		return nil, err
 	}
 
+	o := newEntryOptions(opts)
+	schema, err := GetEntrySchema(o.entrySchema)
+	if err != nil {
+		return nil, err
+	}
+
 	// find ManagementKey
 	signingKey := &ManagementKey{}
 
@@ -561,7 +658,7 @@ func (did *DID) Deactivate(signingKeyAlias string) (*factom.Entry, error) {
 		}
 	}
 
-	err = validate.StructPartial(signingKey, "Alias", "PrivateKey")
+	err = validate.StructPartial(signingKey, "AbstractKey.Alias", "AbstractKey.PrivateKey")
 
 	if err != nil {
 		return nil, err
@@ -572,18 +669,94 @@ func (did *DID) Deactivate(signingKeyAlias string) (*factom.Entry, error) {
 	}
 
 	signingKeyFullID := strings.Join([]string{did.ID, signingKey.Alias}, "#")
-	signature, err := signingKey.Sign([]byte(strings.Join([]string{EntryTypeDeactivation, LatestEntrySchema, signingKeyFullID}, "")))
+	// entries are always signed with the spec-defined mode
+	signature, err := signingKey.SignWithMode([]byte(strings.Join([]string{EntryTypeDeactivation, o.entrySchema, signingKeyFullID}, "")), SignatureModePrehashed)
 
 	if err != nil {
 		return nil, err
 	}
 
 	fe := &factom.Entry{}
+	fe.ChainID = did.GetChainID()
 	fe.ExtIDs = append(fe.ExtIDs, []byte(EntryTypeDeactivation))
-	fe.ExtIDs = append(fe.ExtIDs, []byte(LatestEntrySchema))
+	fe.ExtIDs = append(fe.ExtIDs, []byte(o.entrySchema))
+	fe.ExtIDs = append(fe.ExtIDs, []byte(signingKeyFullID))
+	fe.ExtIDs = append(fe.ExtIDs, signature)
+
+	err = schema.ValidateContent(EntryTypeDeactivation, fe.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	if size := calculateEntrySize(fe); size > MaxEntrySize {
+		return nil, fmt.Errorf("You have exceeded the entry size limit")
+	}
+
+	return fe, nil
+
+}
+
+// UpgradeVersion generates DIDMethodVersionUpgrade Factom Entry signed with ManagementKey.
+// version is the new DID method specification version, e.g. "0.3.0"
+func (did *DID) UpgradeVersion(version string, signingKeyAlias string, opts ...EntryOption) (*factom.Entry, error) {
+
+	// validate existing DID document
+	err := did.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	o := newEntryOptions(opts)
+	schema, err := GetEntrySchema(o.entrySchema)
+	if err != nil {
+		return nil, err
+	}
+
+	if !versionRegexp.MatchString(version) {
+		return nil, fmt.Errorf("Invalid DID method version %s", version)
+	}
+
+	// find ManagementKey
+	signingKey := &ManagementKey{}
+
+	if len(did.ManagementKeys) == 0 {
+		return nil, fmt.Errorf("No ManagementKeys found in this DID document")
+	}
+
+	for _, v := range did.ManagementKeys {
+		if v.Alias == signingKeyAlias {
+			signingKey = v
+		}
+	}
+
+	err = validate.StructPartial(signingKey, "AbstractKey.Alias", "AbstractKey.PrivateKey")
+
+	if err != nil {
+		return nil, err
+	}
+
+	entryContent, err := schema.EncodeVersionUpgrade(&DIDMethodVersionUpgradeEntrySchema{DIDMethodVersion: version})
+	if err != nil {
+		return nil, err
+	}
+
+	signingKeyFullID := strings.Join([]string{did.ID, signingKey.Alias}, "#")
+	// entries are always signed with the spec-defined mode
+	signature, err := signingKey.SignWithMode([]byte(strings.Join([]string{EntryTypeVersionUpgrade, o.entrySchema, signingKeyFullID, string(entryContent)}, "")), SignatureModePrehashed)
+
+	if err != nil {
+		return nil, err
+	}
+
+	fe := &factom.Entry{}
+	fe.ChainID = did.GetChainID()
+	fe.ExtIDs = append(fe.ExtIDs, []byte(EntryTypeVersionUpgrade))
+	fe.ExtIDs = append(fe.ExtIDs, []byte(o.entrySchema))
 	fe.ExtIDs = append(fe.ExtIDs, []byte(signingKeyFullID))
 	fe.ExtIDs = append(fe.ExtIDs, signature)
 
+	fe.Content = entryContent
+
 	if size := calculateEntrySize(fe); size > MaxEntrySize {
 		return nil, fmt.Errorf("You have exceeded the entry size limit")
 	}
@@ -609,7 +782,12 @@ func (did *DID) Validate() error {
 	}
 	var hasAtLeastOneZeroPriorityKey bool
 	for i := range did.ManagementKeys {
-		err = validate.Struct(did.ManagementKeys[i])
+		// PrivateKey is not required, so public-only DID documents are valid as well
+		err = validate.StructExcept(did.ManagementKeys[i], "AbstractKey.PrivateKey")
+		if err != nil {
+			return err
+		}
+		err = did.ManagementKeys[i].checkKeyType()
 		if err != nil {
 			return err
 		}
@@ -627,7 +805,11 @@ func (did *DID) Validate() error {
 		return fmt.Errorf("DID document must have at least one DIDKey")
 	}
 	for i := range did.DIDKeys {
-		err = validate.Struct(did.DIDKeys[i])
+		err = validate.StructExcept(did.DIDKeys[i], "AbstractKey.PrivateKey")
+		if err != nil {
+			return err
+		}
+		err = did.DIDKeys[i].checkPurpose()
 		if err != nil {
 			return err
 		}
@@ -676,6 +858,24 @@ func (did *DID) checkUnique() error {
 
 }
 
+// Public makes a copy of DID Document with all private keys stripped.
+// The copy is safe to be logged or returned from an API
+func (did *DID) Public() *DID {
+
+	public := did.Copy()
+
+	for i := range public.ManagementKeys {
+		public.ManagementKeys[i] = public.ManagementKeys[i].Public()
+	}
+
+	for i := range public.DIDKeys {
+		public.DIDKeys[i] = public.DIDKeys[i].Public()
+	}
+
+	return public
+
+}
+
 // Copy makes a copy of DID Document for update
 func (did *DID) Copy() *DID {
 
@@ -0,0 +1,258 @@
+package factomdid
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// DIDAuth is DID Auth challenge-response helper for logins.
+// Server issues challenge with NewChallenge, client signs it with authentication DIDKey (DIDKey.SignChallenge)
+// and server checks response with Verify
+type DIDAuth struct {
+	domain   string
+	resolver Resolver
+	store    NonceStore
+	ttl      time.Duration
+}
+
+// AuthChallenge is random challenge issued by server of Domain.
+// Client must check that Domain is the server it logs in to before signing the challenge
+type AuthChallenge struct {
+	Domain    string `json:"domain" form:"domain" query:"domain"`
+	Nonce     string `json:"nonce" form:"nonce" query:"nonce"`
+	ExpiresAt int64  `json:"expiresAt" form:"expiresAt" query:"expiresAt"`
+}
+
+// AuthResponse is challenge signed by client's DIDKey
+type AuthResponse struct {
+	Domain    string `json:"domain" form:"domain" query:"domain"`
+	Nonce     string `json:"nonce" form:"nonce" query:"nonce"`
+	KeyID     string `json:"keyId" form:"keyId" query:"keyId"`
+	Signature string `json:"signature" form:"signature" query:"signature"`
+}
+
+// NonceStore stores nonces of issued challenges, so every challenge can be used only once
+type NonceStore interface {
+	// Add stores nonce of issued challenge until expiresAt
+	Add(nonce string, expiresAt time.Time) error
+	// Use removes nonce and returns its expiry time, returns error if nonce wasn't issued or is already used.
+	// Use must be atomic, so concurrent responses with the same nonce can't both succeed
+	Use(nonce string) (time.Time, error)
+}
+
+// InMemoryNonceStore is NonceStore for single server, expired nonces are purged on Add
+type InMemoryNonceStore struct {
+	nonces map[string]time.Time
+	mtx    sync.Mutex
+}
+
+const (
+	// DefaultAuthChallengeTTL is default lifetime of DID Auth challenge
+	DefaultAuthChallengeTTL = 5 * time.Minute
+	// authNonceSize is size of random challenge nonce in bytes
+	authNonceSize = 32
+	// authPayloadPrefix separates signed DID Auth challenges from other messages signed by DID keys
+	authPayloadPrefix = "DIDAuth"
+)
+
+// NewDIDAuth creates DID Auth helper of server domain, resolver resolves DID documents of clients.
+// ttl is lifetime of challenges, DefaultAuthChallengeTTL is used if ttl is 0
+func NewDIDAuth(domain string, resolver Resolver, store NonceStore, ttl time.Duration) (*DIDAuth, error) {
+
+	if domain == "" || len(domain) > math.MaxUint16 {
+		return nil, fmt.Errorf("Domain must be 1-%d bytes", math.MaxUint16)
+	}
+
+	if resolver == nil {
+		return nil, fmt.Errorf("Resolver is empty")
+	}
+
+	if store == nil {
+		return nil, fmt.Errorf("NonceStore is empty")
+	}
+
+	if ttl < 0 {
+		return nil, fmt.Errorf("Challenge TTL must not be negative")
+	}
+
+	if ttl == 0 {
+		ttl = DefaultAuthChallengeTTL
+	}
+
+	return &DIDAuth{domain: domain, resolver: resolver, store: store, ttl: ttl}, nil
+
+}
+
+// NewChallenge generates random challenge and stores its nonce
+func (auth *DIDAuth) NewChallenge() (*AuthChallenge, error) {
+
+	b := make([]byte, authNonceSize)
+	_, err := rand.Read(b)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(auth.ttl)
+
+	challenge := &AuthChallenge{}
+	challenge.Domain = auth.domain
+	challenge.Nonce = base64.RawURLEncoding.EncodeToString(b)
+	challenge.ExpiresAt = expiresAt.Unix()
+
+	err = auth.store.Add(challenge.Nonce, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return challenge, nil
+
+}
+
+// Verify verifies response to the challenge and returns the key which signed it.
+// The nonce is used up even if verification fails, so every challenge can be answered only once.
+// Checks that challenge is issued by the server domain and not expired, and that the key is not revoked
+// and has authentication purpose on resolved DID document
+func (auth *DIDAuth) Verify(response *AuthResponse) (*DIDKey, error) {
+
+	if response == nil {
+		return nil, fmt.Errorf("Response is empty")
+	}
+
+	expiresAt, err := auth.store.Use(response.Nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.Domain != auth.domain {
+		return nil, fmt.Errorf("Invalid response domain %s, must be %s", response.Domain, auth.domain)
+	}
+
+	if !time.Now().Before(expiresAt) {
+		return nil, fmt.Errorf("Challenge is expired")
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(response.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid response signature: %v", err)
+	}
+
+	// revoked keys are not present in resolved DID document
+	key, err := ResolveDIDKey(auth.resolver, response.KeyID, KeyPurposeAuthentication)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := authPayload(&AuthChallenge{Domain: auth.domain, Nonce: response.Nonce, ExpiresAt: expiresAt.Unix()})
+
+	valid, err := key.Verify(payload, signature)
+	if err != nil {
+		return nil, err
+	}
+
+	if !valid {
+		return nil, fmt.Errorf("Invalid response signature")
+	}
+
+	return key, nil
+
+}
+
+// SignChallenge signs DID Auth challenge with DIDKey, the key must have authentication purpose.
+// Signed payload binds the nonce to the challenge domain and expiry time, see authPayload
+func (didkey *DIDKey) SignChallenge(challenge *AuthChallenge) (*AuthResponse, error) {
+
+	if challenge == nil || challenge.Nonce == "" {
+		return nil, fmt.Errorf("Challenge is empty")
+	}
+
+	if challenge.Domain == "" || len(challenge.Domain) > math.MaxUint16 {
+		return nil, fmt.Errorf("Challenge domain must be 1-%d bytes", math.MaxUint16)
+	}
+
+	if !didkey.HasPurpose(KeyPurposeAuthentication) {
+		return nil, fmt.Errorf("DIDKey %s must have %s purpose to sign challenges", didkey.Alias, KeyPurposeAuthentication)
+	}
+
+	kid, err := didkey.KeyID()
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := didkey.Sign(authPayload(challenge))
+	if err != nil {
+		return nil, err
+	}
+
+	response := &AuthResponse{}
+	response.Domain = challenge.Domain
+	response.Nonce = challenge.Nonce
+	response.KeyID = kid
+	response.Signature = base64.StdEncoding.EncodeToString(signature)
+
+	return response, nil
+
+}
+
+// helper function that returns signed payload of challenge:
+// "DIDAuth" || uint16 length of domain || domain || nonce || uint64 expiresAt, integers are big-endian
+func authPayload(challenge *AuthChallenge) []byte {
+
+	payload := []byte(authPayloadPrefix)
+	payload = binary.BigEndian.AppendUint16(payload, uint16(len(challenge.Domain)))
+	payload = append(payload, challenge.Domain...)
+	payload = append(payload, challenge.Nonce...)
+	payload = binary.BigEndian.AppendUint64(payload, uint64(challenge.ExpiresAt))
+
+	return payload
+
+}
+
+// NewInMemoryNonceStore creates empty InMemoryNonceStore
+func NewInMemoryNonceStore() *InMemoryNonceStore {
+	return &InMemoryNonceStore{nonces: make(map[string]time.Time)}
+}
+
+// Add stores nonce until expiresAt
+func (store *InMemoryNonceStore) Add(nonce string, expiresAt time.Time) error {
+
+	store.mtx.Lock()
+	defer store.mtx.Unlock()
+
+	now := time.Now()
+	for k, v := range store.nonces {
+		if !now.Before(v) {
+			delete(store.nonces, k)
+		}
+	}
+
+	if _, ok := store.nonces[nonce]; ok {
+		return fmt.Errorf("Nonce is already issued")
+	}
+
+	store.nonces[nonce] = expiresAt
+
+	return nil
+
+}
+
+// Use removes nonce and returns its expiry time
+func (store *InMemoryNonceStore) Use(nonce string) (time.Time, error) {
+
+	store.mtx.Lock()
+	defer store.mtx.Unlock()
+
+	expiresAt, ok := store.nonces[nonce]
+	if !ok {
+		return time.Time{}, fmt.Errorf("Unknown or already used nonce")
+	}
+
+	delete(store.nonces, nonce)
+
+	return expiresAt, nil
+
+}
@@ -0,0 +1,154 @@
+package factomdid
+
+import (
+	"encoding/base64"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDIDAuth(t *testing.T) {
+
+	did, key := addTestKeys(t, NewDID(), "holder-key", KeyTypeECDSA, KeyPurposeAuthentication)
+	auth, err := NewDIDAuth("example.com", did.Public(), NewInMemoryNonceStore(), 0)
+	assert.NoError(t, err)
+
+	challenge, err := auth.NewChallenge()
+	assert.NoError(t, err)
+	assert.Equal(t, "example.com", challenge.Domain)
+	assert.NotEmpty(t, challenge.Nonce)
+	assert.True(t, challenge.ExpiresAt > time.Now().Unix())
+
+	response, err := key.SignChallenge(challenge)
+	assert.NoError(t, err)
+	assert.Equal(t, did.ID+"#holder-key", response.KeyID)
+	assert.Equal(t, "example.com", response.Domain)
+
+	// signature is made over prefixed payload, not the bare nonce
+	signature, _ := base64.StdEncoding.DecodeString(response.Signature)
+	valid, _ := key.Verify([]byte(response.Nonce), signature)
+	assert.False(t, valid)
+	valid, _ = key.Verify(authPayload(challenge), signature)
+	assert.True(t, valid)
+
+	signer, err := auth.Verify(response)
+	assert.NoError(t, err)
+	assert.Equal(t, key.Alias, signer.Alias)
+
+	// nonce reuse
+	_, err = auth.Verify(response)
+	assert.Error(t, err)
+
+	// challenge not issued by server
+	response, _ = key.SignChallenge(&AuthChallenge{Domain: "example.com", Nonce: "forged"})
+	_, err = auth.Verify(response)
+	assert.Error(t, err)
+
+	// challenge relayed by another domain
+	phishing, _ := NewDIDAuth("phishing.example.com", did.Public(), NewInMemoryNonceStore(), 0)
+	challenge, _ = auth.NewChallenge()
+	relayed := *challenge
+	relayed.Domain = "phishing.example.com"
+	response, _ = key.SignChallenge(&relayed)
+	_, err = phishing.Verify(response)
+	assert.Error(t, err)
+	_, err = auth.Verify(response)
+	assert.Error(t, err)
+	challenge, _ = auth.NewChallenge()
+	response, _ = key.SignChallenge(&relayed)
+	response.Nonce = challenge.Nonce
+	response.Domain = "example.com"
+	_, err = auth.Verify(response)
+	assert.Error(t, err)
+
+	// challenge without domain
+	_, err = key.SignChallenge(&AuthChallenge{Nonce: "nonce"})
+	assert.Error(t, err)
+
+	// invalid signature burns the nonce
+	challenge, _ = auth.NewChallenge()
+	response, _ = key.SignChallenge(challenge)
+	other, _ := key.SignChallenge(&AuthChallenge{Domain: "example.com", Nonce: "other"})
+	forged := *response
+	forged.Signature = other.Signature
+	_, err = auth.Verify(&forged)
+	assert.Error(t, err)
+	_, err = auth.Verify(response)
+	assert.Error(t, err)
+
+	// key without authentication purpose
+	publicKey, _ := NewDIDKey("public-key", KeyTypeEdDSA)
+	publicKey.AddPurpose(KeyPurposePublic)
+	did.AddDIDKey(publicKey)
+	_, err = publicKey.SignChallenge(challenge)
+	assert.Error(t, err)
+
+	// revoked key
+	challenge, _ = auth.NewChallenge()
+	response, _ = key.SignChallenge(challenge)
+	revoked := did.Copy()
+	revoked.RevokeDIDKey(key.Alias)
+	auth, _ = NewDIDAuth("example.com", revoked, auth.store, 0)
+	_, err = auth.Verify(response)
+	assert.Error(t, err)
+
+	// invalid params
+	_, err = NewDIDAuth("", did, NewInMemoryNonceStore(), 0)
+	assert.Error(t, err)
+	_, err = NewDIDAuth("example.com", nil, NewInMemoryNonceStore(), 0)
+	assert.Error(t, err)
+	_, err = NewDIDAuth("example.com", did, nil, 0)
+	assert.Error(t, err)
+	_, err = NewDIDAuth("example.com", did, NewInMemoryNonceStore(), -time.Second)
+	assert.Error(t, err)
+
+}
+
+func TestDIDAuthExpiry(t *testing.T) {
+
+	did, key := addTestKeys(t, NewDID(), "holder-key", KeyTypeECDSA, KeyPurposeAuthentication)
+	auth, _ := NewDIDAuth("example.com", did, NewInMemoryNonceStore(), time.Millisecond)
+
+	challenge, _ := auth.NewChallenge()
+	response, _ := key.SignChallenge(challenge)
+	time.Sleep(5 * time.Millisecond)
+
+	_, err := auth.Verify(response)
+	assert.Error(t, err)
+
+}
+
+func TestInMemoryNonceStore(t *testing.T) {
+
+	store := NewInMemoryNonceStore()
+
+	assert.NoError(t, store.Add("nonce", time.Now().Add(time.Minute)))
+	assert.Error(t, store.Add("nonce", time.Now().Add(time.Minute)))
+
+	// only one concurrent use succeeds
+	var wg sync.WaitGroup
+	var mtx sync.Mutex
+	var used int
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := store.Use("nonce"); err == nil {
+				mtx.Lock()
+				used++
+				mtx.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 1, used)
+
+	// expired nonces are purged
+	store.Add("expired", time.Now().Add(-time.Second))
+	store.Add("another", time.Now().Add(time.Minute))
+	_, err := store.Use("expired")
+	assert.Error(t, err)
+
+}
@@ -1,15 +1,15 @@
 package factomdid
 
 import (
-	"encoding/pem"
+	"fmt"
 	"strings"
 
-	"github.com/FactomProject/btcutil/base58"
 	"gopkg.in/go-playground/validator.v9"
 )
 
 // DIDKey is a key used to sign updates for an existing DID
-// DIDKey.Purpose may be publicKey, authentication or both
+// DIDKey.Purpose may be publicKey, authentication or both.
+// X25519 DIDKey is a key agreement key, its only purpose is keyAgreement
 type DIDKey struct {
 	AbstractKey
 	Purpose []DIDKeyPurpose `json:"purpose" form:"purpose" query:"purpose" validate:"len=1|len=2,unique,required,dive"`
@@ -17,7 +17,7 @@ type DIDKey struct {
 
 // DIDKeyPurpose shows what purpose(s) the key serves
 type DIDKeyPurpose struct {
-	Purpose string `json:"purpose" form:"purpose" query:"purpose" validate:"required,oneof=publicKey authentication"`
+	Purpose string `json:"purpose" form:"purpose" query:"purpose" validate:"required,oneof=publicKey authentication keyAgreement"`
 }
 
 const (
@@ -25,12 +25,15 @@ const (
 	KeyPurposeAuthentication = "authentication"
 	// KeyPurposePublic is publicKey purpose
 	KeyPurposePublic = "publicKey"
+	// KeyPurposeKeyAgreement is keyAgreement purpose, used by X25519 keys only
+	KeyPurposeKeyAgreement = "keyAgreement"
 	// OnChainPubKeyName is name of public key on-chain
 	OnChainPubKeyName = "publicKeyBase58"
 )
 
-// NewDIDKey generates new DIDKey with alias and keyType
-func NewDIDKey(alias string, keyType string) (*DIDKey, error) {
+// NewDIDKey generates new DIDKey with alias and keyType.
+// Key generation can be configured with KeyOption, e.g. WithRSABits(4096)
+func NewDIDKey(alias string, keyType string, opts ...KeyOption) (*DIDKey, error) {
 
 	key := &DIDKey{}
 	key.Alias = alias
@@ -42,7 +45,10 @@ func NewDIDKey(alias string, keyType string) (*DIDKey, error) {
 		return nil, err
 	}
 
-	key.generateRandomKeys()
+	err = key.generateRandomKeys(opts...)
+	if err != nil {
+		return nil, err
+	}
 
 	return key, nil
 
@@ -65,11 +71,58 @@ func (didkey *DIDKey) AddPurpose(purpose string) (*DIDKey, error) {
 
 }
 
+// HasPurpose returns true if DIDKey has the purpose
+func (didkey *DIDKey) HasPurpose(purpose string) bool {
+
+	for i := range didkey.Purpose {
+		if didkey.Purpose[i].Purpose == purpose {
+			return true
+		}
+	}
+
+	return false
+
+}
+
+// helper function that checks that keyAgreement purpose is used by key agreement keys (X25519) only and vice versa
+func (didkey *DIDKey) checkPurpose() error {
+
+	if isKeyAgreementKeyType(didkey.KeyType) {
+		if len(didkey.Purpose) != 1 || didkey.Purpose[0].Purpose != KeyPurposeKeyAgreement {
+			return fmt.Errorf("%s DIDKey must have %s purpose only", didkey.KeyType, KeyPurposeKeyAgreement)
+		}
+		return nil
+	}
+
+	if didkey.HasPurpose(KeyPurposeKeyAgreement) {
+		return fmt.Errorf("%s purpose requires key agreement DIDKey, e.g. %s", KeyPurposeKeyAgreement, KeyTypeX25519)
+	}
+
+	return nil
+
+}
+
+// Public returns a copy of DIDKey without PrivateKey
+func (didkey *DIDKey) Public() *DIDKey {
+
+	public := *didkey
+	public.AbstractKey = didkey.AbstractKey.public()
+	public.Purpose = append([]DIDKeyPurpose(nil), didkey.Purpose...)
+
+	return &public
+
+}
+
 // helper function to convert DIDKey into DIDKeySchema
 func (didkey *DIDKey) toSchema(DID string) (*DIDKeySchema, error) {
 
-	// validate DIDKey
-	err := validate.Struct(didkey)
+	// validate DIDKey, PrivateKey is not written on-chain
+	err := validate.StructExcept(didkey, "AbstractKey.PrivateKey")
+	if err != nil {
+		return nil, err
+	}
+
+	err = didkey.checkPurpose()
 	if err != nil {
 		return nil, err
 	}
@@ -84,10 +137,14 @@ func (didkey *DIDKey) toSchema(DID string) (*DIDKeySchema, error) {
 		s.Purpose = append(s.Purpose, didkey.Purpose[i].Purpose)
 	}
 
-	if didkey.KeyType == KeyTypeRSA {
-		s.PublicKeyPem = string(pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: didkey.PublicKey}))
-	} else {
-		s.PublicKeyBase58 = base58.Encode(didkey.PublicKey)
+	suite, err := GetKeySuite(didkey.KeyType)
+	if err != nil {
+		return nil, err
+	}
+
+	err = setOnChainPublicKey(suite, didkey.PublicKey, &s.PublicKeyBase58, &s.PublicKeyPem)
+	if err != nil {
+		return nil, err
 	}
 
 	return s, nil
@@ -110,3 +167,45 @@ func (didkey *DIDKey) toRevokeIDSchema(DID string) (*RevokeIDSchema, error) {
 	return s, nil
 
 }
+
+// helper function to convert on-chain DIDKeySchema of DID into public DIDKey
+func didKeyFromSchema(s *DIDKeySchema, DID string) (*DIDKey, error) {
+
+	alias, err := schemaIDAlias(s.ID, DID)
+	if err != nil {
+		return nil, err
+	}
+
+	key := &DIDKey{}
+	key.Alias = alias
+	key.KeyType = s.Type
+	key.Controller = s.Controller
+	key.PriorityRequirement = s.PriorityRequirement
+
+	for i := range s.Purpose {
+		key.Purpose = append(key.Purpose, DIDKeyPurpose{Purpose: s.Purpose[i]})
+	}
+
+	suite, err := GetKeySuite(s.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	key.PublicKey, err = getOnChainPublicKey(suite, s.PublicKeyBase58, s.PublicKeyPem)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validate.StructExcept(key, "AbstractKey.PrivateKey")
+	if err != nil {
+		return nil, err
+	}
+
+	err = key.checkPurpose()
+	if err != nil {
+		return nil, err
+	}
+
+	return key, nil
+
+}
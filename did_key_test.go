@@ -24,6 +24,11 @@ func TestNewDIDKey(t *testing.T) {
 	assert.NotEmpty(t, k3.PrivateKey)
 	assert.NotEmpty(t, k3.PublicKey)
 
+	kP256, err := NewDIDKey("test", KeyTypeECDSAP256)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, kP256.PrivateKey)
+	assert.NotEmpty(t, kP256.PublicKey)
+
 	// invalid KeyType
 	k4, err := NewDIDKey("test", "WrongKeyType")
 	assert.Error(t, err)
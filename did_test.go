@@ -2,8 +2,10 @@ package factomdid
 
 import (
 	"bytes"
+	"crypto/rand"
 	"testing"
 
+	"github.com/FactomProject/factom"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -16,6 +18,48 @@ func TestNewDID(t *testing.T) {
 	assert.NotEmpty(t, did.ExtIDs[1])
 	assert.NotEmpty(t, did.String())
 	assert.Equal(t, 64, len(did.GetChainID()))
+	assert.Equal(t, factom.ChainIDFromFields(did.ExtIDs), did.GetChainID())
+
+}
+
+func TestNewDIDWithNonce(t *testing.T) {
+
+	nonce := bytes.Repeat([]byte{1}, MinNonceSize)
+
+	did, err := NewDIDWithNonce(nonce)
+	assert.NoError(t, err)
+	assert.Equal(t, nonce, did.ExtIDs[2])
+	assert.Equal(t, factom.ChainIDFromFields(did.ExtIDs), did.GetChainID())
+
+	// the same nonce gives the same DID
+	did2, _ := NewDIDWithNonce(nonce)
+	assert.Equal(t, did.ID, did2.ID)
+
+	// nonce is copied
+	nonce[0] = 2
+	assert.Equal(t, byte(1), did.ExtIDs[2][0])
+
+	// invalid nonce size
+	_, err = NewDIDWithNonce(nil)
+	assert.Error(t, err)
+	_, err = NewDIDWithNonce(make([]byte, MinNonceSize-1))
+	assert.Error(t, err)
+	_, err = NewDIDWithNonce(make([]byte, MaxNonceSize+1))
+	assert.Error(t, err)
+
+}
+
+func TestNewDIDFromReader(t *testing.T) {
+
+	did, err := NewDIDFromReader(bytes.NewReader(bytes.Repeat([]byte{1}, NonceSize)))
+	assert.NoError(t, err)
+	assert.Equal(t, bytes.Repeat([]byte{1}, NonceSize), did.ExtIDs[2])
+
+	_, err = NewDIDFromReader(bytes.NewReader(nil))
+	assert.Error(t, err)
+
+	_, err = NewDIDFromReader(nil)
+	assert.Error(t, err)
 
 }
 
@@ -198,16 +242,34 @@ func TestCreate(t *testing.T) {
 	didKey, _ := NewDIDKey("default-did-key", KeyTypeECDSA)
 	didKey.AddPurpose(KeyPurposePublic)
 	mgmtKey, _ := NewManagementKey("default-mgmt-key", KeyTypeECDSA, 0)
+	p256Key, _ := NewManagementKey("p256-mgmt-key", KeyTypeECDSAP256, 1)
 	service, _ := NewService("demo", "Demo", "https://demo.com")
 
 	did.AddDIDKey(didKey)
 	did.AddManagementKey(mgmtKey)
+	did.AddManagementKey(p256Key)
 	did.AddService(service)
 
+	// P-256 key isn't allowed by entry schema 1.0.0
 	fe, err := did.Create()
+	assert.Nil(t, fe)
+	assert.Equal(t, []string{"/managementKey/1/type"}, schemaErrorPaths(t, err))
+
+	did.RevokeManagementKey("p256-mgmt-key")
+	fe, err = did.Create()
 	assert.NotNil(t, fe)
 	assert.NoError(t, err)
 
+	// entry schema 1.0.0+factomdid-ext allows P-256 keys
+	did, err = NewDIDFromReader(rand.Reader, WithEntrySchema(EntrySchemaV100Ext))
+	assert.NoError(t, err)
+	did.AddDIDKey(didKey)
+	did.AddManagementKey(mgmtKey)
+	did.AddManagementKey(p256Key)
+	fe, err = did.Create()
+	assert.NoError(t, err)
+	assert.NoError(t, ValidateEntry(fe))
+
 }
 
 func TestDeactivate(t *testing.T) {
@@ -286,6 +348,35 @@ func TestUpdate(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestUpgradeVersion(t *testing.T) {
+
+	did, _ := addTestKeys(t, NewDID(), "default-did-key", KeyTypeECDSA, KeyPurposePublic)
+
+	// try to upgrade with non existent key
+	fe, err := did.UpgradeVersion("0.3.0", "not-existent-mgmt-key")
+	assert.Nil(t, fe)
+	assert.Error(t, err)
+
+	// try to upgrade to invalid version
+	fe, err = did.UpgradeVersion("v1", "default-mgmt-key")
+	assert.Nil(t, fe)
+	assert.Error(t, err)
+
+	fe, err = did.UpgradeVersion("0.3.0", "default-mgmt-key")
+	assert.NoError(t, err)
+	assert.Equal(t, did.GetChainID(), fe.ChainID)
+	assert.Equal(t, []byte(EntryTypeVersionUpgrade), fe.ExtIDs[0])
+	assert.Equal(t, `{"didMethodVersion":"0.3.0"}`, string(fe.Content))
+
+	// check signature
+	e := bytes.Join(append(fe.ExtIDs[:3], fe.ExtIDs[4:]...), nil)
+	m := bytes.Join([][]byte{e, fe.Content}, nil)
+	v, err := did.ManagementKeys[0].Verify(m, fe.ExtIDs[3])
+	assert.True(t, v)
+	assert.NoError(t, err)
+
+}
+
 func TestValidate(t *testing.T) {
 
 	var err error
@@ -338,3 +429,59 @@ func TestValidate(t *testing.T) {
 	assert.Error(t, err)
 
 }
+
+func TestPublic(t *testing.T) {
+
+	did := NewDID()
+
+	didKey, _ := NewDIDKey("did-key", KeyTypeEdDSA)
+	didKey.AddPurpose(KeyPurposePublic)
+	mgmtKey, _ := NewManagementKey("mgmt-key", KeyTypeECDSA, 0)
+
+	did.AddDIDKey(didKey)
+	did.AddManagementKey(mgmtKey)
+
+	public := did.Public()
+	assert.Equal(t, did.ID, public.ID)
+	assert.Empty(t, public.DIDKeys[0].PrivateKey)
+	assert.Empty(t, public.ManagementKeys[0].PrivateKey)
+	assert.Equal(t, did.DIDKeys[0].PublicKey, public.DIDKeys[0].PublicKey)
+
+	// origin DID document keeps private keys
+	assert.NotEmpty(t, did.DIDKeys[0].PrivateKey)
+	assert.NotEmpty(t, did.ManagementKeys[0].PrivateKey)
+
+	// public-only DID document is valid
+	assert.NoError(t, public.Validate())
+
+	// public-only DID document can be used for verification
+	signature, _ := did.DIDKeys[0].Sign([]byte("Test"))
+	v, err := public.DIDKeys[0].Verify([]byte("Test"), signature)
+	assert.True(t, v)
+	assert.NoError(t, err)
+
+	// public-only DID document can't sign updates
+	fe, err := public.Deactivate("mgmt-key")
+	assert.Nil(t, fe)
+	assert.Error(t, err)
+
+	// public-only DID keys can be added
+	public2 := NewDID()
+	_, err = public2.AddDIDKey(didKey.Public())
+	assert.NoError(t, err)
+	_, err = public2.AddManagementKey(mgmtKey.Public())
+	assert.NoError(t, err)
+
+	// public copies don't share purposes and priority requirements with origin keys
+	didKey.SetPriorityRequirement(1)
+	mgmtKey.SetPriorityRequirement(1)
+	publicDIDKey := didKey.Public()
+	publicMgmtKey := mgmtKey.Public()
+	publicDIDKey.AddPurpose(KeyPurposeAuthentication)
+	*publicDIDKey.PriorityRequirement = 2
+	*publicMgmtKey.PriorityRequirement = 2
+	assert.Len(t, didKey.Purpose, 1)
+	assert.Equal(t, 1, *didKey.PriorityRequirement)
+	assert.Equal(t, 1, *mgmtKey.PriorityRequirement)
+
+}
@@ -0,0 +1,581 @@
+package factomdid
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	// ServiceTypeDIDCommMessaging is type of Service with DIDComm messaging endpoint
+	ServiceTypeDIDCommMessaging = "DIDCommMessaging"
+	// DIDCommEncryptedType is "typ" of encrypted DIDComm message
+	DIDCommEncryptedType = "application/didcomm-encrypted+json"
+	// DIDCommProfileV2 is media type profile of DIDComm v2, value of DIDCommMessaging service "accept"
+	DIDCommProfileV2 = "didcomm/v2"
+)
+
+const (
+	didCommRoutingKeys = "routingKeys"
+	didCommAccept      = "accept"
+)
+
+// PackAnoncrypt encrypts payload for recipients without revealing the sender (ECDH-ES+A256KW, A256CBC-HS512).
+// Recipients (to) are DIDs, whose all keyAgreement keys can decrypt the message, or DID URLs of keyAgreement keys,
+// they are resolved using resolver
+func PackAnoncrypt(payload []byte, resolver Resolver, to ...string) (*JWE, error) {
+	return pack(nil, payload, resolver, to)
+}
+
+// PackAuthcrypt encrypts payload for recipients like PackAnoncrypt, authenticating the sender (ECDH-1PU+A256KW, A256CBC-HS512).
+// DIDKey must be X25519 key agreement key with private key, added to sender's DID document
+func (didkey *DIDKey) PackAuthcrypt(payload []byte, resolver Resolver, to ...string) (*JWE, error) {
+
+	if didkey.KeyType != KeyTypeX25519 || !didkey.HasPurpose(KeyPurposeKeyAgreement) || !didkey.HasPrivateKey() {
+		return nil, fmt.Errorf("Sender DIDKey must be %s key with %s purpose and private key", KeyTypeX25519, KeyPurposeKeyAgreement)
+	}
+
+	return pack(didkey, payload, resolver, to)
+
+}
+
+// Unpack decrypts JWE with keyAgreement keys of DID document, the keys must have private keys.
+// Sender of authcrypt message is resolved using resolver and returned, the key must still have keyAgreement purpose.
+// Sender is nil for anoncrypt messages
+func (did *DID) Unpack(jwe *JWE, resolver Resolver) ([]byte, *DIDKey, error) {
+
+	if jwe == nil {
+		return nil, nil, fmt.Errorf("JWE is empty")
+	}
+
+	headerJSON, err := base64.RawURLEncoding.DecodeString(jwe.Protected)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Invalid JWE protected header: %v", err)
+	}
+
+	header := &JWEHeader{}
+	err = json.Unmarshal(headerJSON, header)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Invalid JWE protected header: %v", err)
+	}
+
+	if header.Alg != JWEAlgorithmECDHESA256KW && header.Alg != JWEAlgorithmECDH1PUA256KW {
+		return nil, nil, fmt.Errorf("Unsupported JWE alg %s", header.Alg)
+	}
+
+	if header.Enc != JWEEncryptionA256CBCHS512 {
+		return nil, nil, fmt.Errorf("Unsupported JWE enc %s", header.Enc)
+	}
+
+	if header.Epk == nil {
+		return nil, nil, fmt.Errorf("JWE epk is empty")
+	}
+
+	epk, err := (&AbstractKey{KeyType: KeyTypeX25519}).FromJWK(header.Epk)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Invalid JWE epk: %v", err)
+	}
+
+	// apv binds the recipients list
+	var kids []string
+	for _, r := range jwe.Recipients {
+		if r == nil || r.Header == nil {
+			return nil, nil, fmt.Errorf("JWE recipient kid is empty")
+		}
+		kids = append(kids, r.Header.Kid)
+	}
+	if header.Apv != recipientsAPV(kids) {
+		return nil, nil, fmt.Errorf("JWE apv doesn't match recipients")
+	}
+
+	var sender *DIDKey
+	if header.Alg == JWEAlgorithmECDH1PUA256KW {
+
+		if header.Skid == "" || header.Apu != base64.RawURLEncoding.EncodeToString([]byte(header.Skid)) {
+			return nil, nil, fmt.Errorf("JWE skid is empty or doesn't match apu")
+		}
+
+		sender, err = ResolveDIDKey(resolver, header.Skid, KeyPurposeKeyAgreement)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if sender.KeyType != KeyTypeX25519 {
+			return nil, nil, fmt.Errorf("JWE sender key must be %s key", KeyTypeX25519)
+		}
+
+	}
+
+	iv, err := base64.RawURLEncoding.DecodeString(jwe.IV)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Invalid JWE iv: %v", err)
+	}
+
+	ciphertext, err := base64.RawURLEncoding.DecodeString(jwe.Ciphertext)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Invalid JWE ciphertext: %v", err)
+	}
+
+	tag, err := base64.RawURLEncoding.DecodeString(jwe.Tag)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Invalid JWE tag: %v", err)
+	}
+
+	apu, _ := base64.RawURLEncoding.DecodeString(header.Apu)
+	apv, _ := base64.RawURLEncoding.DecodeString(header.Apv)
+
+	for _, r := range jwe.Recipients {
+
+		key := did.keyAgreementKey(r.Header.Kid)
+		if key == nil {
+			continue
+		}
+
+		encryptedKey, err := base64.RawURLEncoding.DecodeString(r.EncryptedKey)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Invalid JWE encrypted_key: %v", err)
+		}
+
+		z, err := sharedSecretZ(header.Alg, key.PrivateKey, epk.PublicKey, sender)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		var cctag []byte
+		if header.Alg == JWEAlgorithmECDH1PUA256KW {
+			cctag = tag
+		}
+
+		cek, err := unwrapKey(concatKDF(z, header.Alg, apu, apv, cctag, a256kwKeySize), encryptedKey)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		payload, err := decryptA256CBCHS512(cek, iv, ciphertext, tag, []byte(jwe.Protected))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return payload, sender, nil
+
+	}
+
+	return nil, nil, fmt.Errorf("JWE is not encrypted for %s", did.ID)
+
+}
+
+// DIDCommEndpoints returns endpoints of DIDCommMessaging services with their routingKeys and accept.
+// URI endpoints get routingKeys and accept of service custom fields, endpoint maps have their own
+func (did *DID) DIDCommEndpoints() ([]*W3CServiceEndpoint, error) {
+
+	var endpoints []*W3CServiceEndpoint
+
+	for _, service := range did.Services {
+
+		if service.ServiceType != ServiceTypeDIDCommMessaging {
+			continue
+		}
+
+		fields, err := service.customFields()
+		if err != nil {
+			return nil, err
+		}
+
+		serviceEndpoints, err := didCommEndpoints(service.Endpoint, fields)
+		if err != nil {
+			return nil, err
+		}
+
+		endpoints = append(endpoints, serviceEndpoints...)
+
+	}
+
+	return endpoints, nil
+
+}
+
+// helper function that builds DIDComm endpoints of service endpoint (URI, map or array of URIs and maps),
+// routingKeys and accept custom fields are added to URI endpoints
+func didCommEndpoints(endpoint interface{}, fields map[string]json.RawMessage) ([]*W3CServiceEndpoint, error) {
+
+	endpoint, err := normalizeServiceEndpoint(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	items, ok := endpoint.([]interface{})
+	if !ok {
+		items = []interface{}{endpoint}
+	}
+
+	var endpoints []*W3CServiceEndpoint
+
+	for _, item := range items {
+
+		var e *W3CServiceEndpoint
+
+		switch v := item.(type) {
+		case string:
+			e, err = didCommEndpoint(v, fields)
+		case map[string]interface{}:
+			e, err = didCommEndpointMap(v)
+		default:
+			err = fmt.Errorf("DIDComm service endpoint must be URI, map or array of URIs and maps")
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		endpoints = append(endpoints, e)
+
+	}
+
+	return endpoints, nil
+
+}
+
+// helper function that builds DIDComm endpoint of service endpoint URI and routingKeys and accept custom fields
+func didCommEndpoint(uri string, fields map[string]json.RawMessage) (*W3CServiceEndpoint, error) {
+
+	endpoint := &W3CServiceEndpoint{URI: uri}
+
+	if v, ok := fields[didCommRoutingKeys]; ok {
+		err := json.Unmarshal(v, &endpoint.RoutingKeys)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if v, ok := fields[didCommAccept]; ok {
+		err := json.Unmarshal(v, &endpoint.Accept)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return endpoint, nil
+
+}
+
+// helper function that decodes DIDComm service endpoint map, its uri, routingKeys and accept are validated
+func didCommEndpointMap(m map[string]interface{}) (*W3CServiceEndpoint, error) {
+
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]json.RawMessage
+	err = json.Unmarshal(data, &fields)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkDIDCommFields(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := &W3CServiceEndpoint{}
+	err = json.Unmarshal(data, endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	if checkServiceEndpointURI(endpoint.URI) != nil {
+		return nil, fmt.Errorf("DIDComm service endpoint map must have valid uri")
+	}
+
+	return endpoint, nil
+
+}
+
+// NewDIDCommService generates new DIDCommMessaging service.
+// Routing keys are DID URLs of mediators' keyAgreement keys, accept lists supported media type profiles (e.g. "didcomm/v2")
+func NewDIDCommService(alias string, endpoint string, routingKeys []string, accept []string) (*Service, error) {
+
+	service, err := NewService(alias, ServiceTypeDIDCommMessaging, endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]interface{})
+	if len(routingKeys) > 0 {
+		fields[didCommRoutingKeys] = routingKeys
+	}
+	if len(accept) > 0 {
+		fields[didCommAccept] = accept
+	}
+
+	return service.SetCustomFields(fields)
+
+}
+
+// helper function that validates routingKeys and accept custom fields of DIDCommMessaging service
+func checkDIDCommFields(fields map[string]json.RawMessage) error {
+
+	if v, ok := fields[didCommRoutingKeys]; ok {
+		var keys []string
+		if err := json.Unmarshal(v, &keys); err != nil {
+			return fmt.Errorf("DIDComm routingKeys must be array of DID URLs")
+		}
+		for _, key := range keys {
+			s := strings.SplitN(key, "#", 2)
+			if len(s) != 2 || s[1] == "" || !strings.HasPrefix(s[0], "did:") || strings.Count(s[0], ":") < 2 {
+				return fmt.Errorf("DIDComm routing key %q must be DID URL with key fragment", key)
+			}
+		}
+	}
+
+	if v, ok := fields[didCommAccept]; ok {
+		var accept []string
+		if err := json.Unmarshal(v, &accept); err != nil {
+			return fmt.Errorf("DIDComm accept must be array of media type profiles")
+		}
+		for _, a := range accept {
+			if a == "" {
+				return fmt.Errorf("DIDComm accept must not contain empty media type profile")
+			}
+		}
+	}
+
+	return nil
+
+}
+
+// helper function that encrypts payload, sender is nil for anoncrypt
+func pack(sender *DIDKey, payload []byte, resolver Resolver, to []string) (*JWE, error) {
+
+	recipients, kids, err := resolveRecipients(resolver, to)
+	if err != nil {
+		return nil, err
+	}
+
+	suite, err := GetKeySuite(KeyTypeX25519)
+	if err != nil {
+		return nil, err
+	}
+
+	epkPublic, epkPrivate, err := suite.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	epk, err := suite.ToJWK(epkPublic)
+	if err != nil {
+		return nil, err
+	}
+
+	header := &JWEHeader{}
+	header.Typ = DIDCommEncryptedType
+	header.Alg = JWEAlgorithmECDHESA256KW
+	header.Enc = JWEEncryptionA256CBCHS512
+	header.Epk = epk
+	header.Apv = recipientsAPV(kids)
+
+	if sender != nil {
+		header.Alg = JWEAlgorithmECDH1PUA256KW
+		header.Skid, err = sender.KeyID()
+		if err != nil {
+			return nil, err
+		}
+		header.Apu = base64.RawURLEncoding.EncodeToString([]byte(header.Skid))
+	}
+
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		return nil, err
+	}
+
+	jwe := &JWE{}
+	jwe.Protected = base64.RawURLEncoding.EncodeToString(headerJSON)
+
+	cek := make([]byte, a256cbcHS512KeySize)
+	iv := make([]byte, 16)
+	_, err = rand.Read(cek)
+	if err != nil {
+		return nil, err
+	}
+	_, err = rand.Read(iv)
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext, tag, err := encryptA256CBCHS512(cek, iv, payload, []byte(jwe.Protected))
+	if err != nil {
+		return nil, err
+	}
+
+	jwe.IV = base64.RawURLEncoding.EncodeToString(iv)
+	jwe.Ciphertext = base64.RawURLEncoding.EncodeToString(ciphertext)
+	jwe.Tag = base64.RawURLEncoding.EncodeToString(tag)
+
+	apu := []byte(header.Skid)
+	apv, _ := base64.RawURLEncoding.DecodeString(header.Apv)
+
+	var senderPrivateKey []byte
+	var cctag []byte
+	if sender != nil {
+		senderPrivateKey = sender.PrivateKey
+		cctag = tag
+	}
+
+	for i, recipient := range recipients {
+
+		z, err := suite.(KeyAgreementSuite).SharedSecret(epkPrivate, recipient.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+
+		if sender != nil {
+			zs, err := suite.(KeyAgreementSuite).SharedSecret(senderPrivateKey, recipient.PublicKey)
+			if err != nil {
+				return nil, err
+			}
+			z = append(z, zs...)
+		}
+
+		encryptedKey, err := wrapKey(concatKDF(z, header.Alg, apu, apv, cctag, a256kwKeySize), cek)
+		if err != nil {
+			return nil, err
+		}
+
+		jwe.Recipients = append(jwe.Recipients, &JWERecipient{
+			Header:       &JWERecipientHeader{Kid: kids[i]},
+			EncryptedKey: base64.RawURLEncoding.EncodeToString(encryptedKey),
+		})
+
+	}
+
+	return jwe, nil
+
+}
+
+// helper function that computes ECDH shared secret of recipient: Ze for ECDH-ES, Ze || Zs for ECDH-1PU
+func sharedSecretZ(alg string, recipientPrivateKey []byte, epk []byte, sender *DIDKey) ([]byte, error) {
+
+	suite, err := GetKeySuite(KeyTypeX25519)
+	if err != nil {
+		return nil, err
+	}
+
+	z, err := suite.(KeyAgreementSuite).SharedSecret(recipientPrivateKey, epk)
+	if err != nil {
+		return nil, err
+	}
+
+	if alg == JWEAlgorithmECDH1PUA256KW {
+		zs, err := suite.(KeyAgreementSuite).SharedSecret(recipientPrivateKey, sender.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		z = append(z, zs...)
+	}
+
+	return z, nil
+
+}
+
+// helper function that resolves recipients into X25519 keyAgreement keys and their kids
+func resolveRecipients(resolver Resolver, to []string) ([]*DIDKey, []string, error) {
+
+	if resolver == nil {
+		return nil, nil, fmt.Errorf("Resolver is empty")
+	}
+
+	if len(to) == 0 {
+		return nil, nil, fmt.Errorf("Recipients are empty")
+	}
+
+	var keys []*DIDKey
+	var kids []string
+	seen := make(map[string]bool)
+
+	for _, recipient := range to {
+
+		id := recipient
+		alias := ""
+		if strings.Contains(recipient, "#") {
+			var err error
+			id, alias, err = SplitDIDURL(recipient)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
+		did, err := resolver.Resolve(id)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if did == nil {
+			return nil, nil, fmt.Errorf("DID %s not found", id)
+		}
+
+		found := false
+		for _, key := range did.DIDKeys {
+
+			if alias != "" && key.Alias != alias {
+				continue
+			}
+
+			if key.KeyType != KeyTypeX25519 || !key.HasPurpose(KeyPurposeKeyAgreement) {
+				continue
+			}
+
+			found = true
+			kid := strings.Join([]string{did.ID, key.Alias}, "#")
+			if !seen[kid] {
+				seen[kid] = true
+				keys = append(keys, key)
+				kids = append(kids, kid)
+			}
+
+		}
+
+		if !found {
+			return nil, nil, fmt.Errorf("%s has no %s %s key", recipient, KeyPurposeKeyAgreement, KeyTypeX25519)
+		}
+
+	}
+
+	return keys, kids, nil
+
+}
+
+// helper function that returns X25519 keyAgreement DIDKey with private key by kid
+func (did *DID) keyAgreementKey(kid string) *DIDKey {
+
+	id, alias, err := SplitDIDURL(kid)
+	if err != nil {
+		return nil
+	}
+
+	if _, err = did.Resolve(id); err != nil {
+		return nil
+	}
+
+	for _, key := range did.DIDKeys {
+		if key.Alias == alias && key.KeyType == KeyTypeX25519 && key.HasPurpose(KeyPurposeKeyAgreement) && key.HasPrivateKey() {
+			return key
+		}
+	}
+
+	return nil
+
+}
+
+// helper function that computes apv: base64url of SHA-256 of sorted recipient kids joined with "."
+func recipientsAPV(kids []string) string {
+
+	sorted := append([]string{}, kids...)
+	sort.Strings(sorted)
+	h := sha256.Sum256([]byte(strings.Join(sorted, ".")))
+
+	return base64.RawURLEncoding.EncodeToString(h[:])
+
+}
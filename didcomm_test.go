@@ -0,0 +1,190 @@
+package factomdid
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnoncrypt(t *testing.T) {
+
+	alice, _ := addTestKeys(t, NewDID(), "ka-key", KeyTypeX25519, KeyPurposeKeyAgreement)
+	bob, _ := addTestKeys(t, NewDID(), "ka-key", KeyTypeX25519, KeyPurposeKeyAgreement)
+	bobSecondKey, _ := NewKeyAgreementKey("ka-key-2")
+	bob.AddDIDKey(bobSecondKey)
+	eve, _ := addTestKeys(t, NewDID(), "ka-key", KeyTypeX25519, KeyPurposeKeyAgreement)
+	resolver := newTestResolver(alice.Public(), bob.Public(), eve.Public())
+
+	payload := []byte(`{"type":"https://didcomm.org/basicmessage/2.0/message","body":{"content":"Hello"}}`)
+
+	// all keyAgreement keys of both DIDs
+	jwe, err := PackAnoncrypt(payload, resolver, alice.ID, bob.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(jwe.Recipients))
+
+	for _, did := range []*DID{alice, bob} {
+		decrypted, sender, err := did.Unpack(jwe, resolver)
+		assert.NoError(t, err)
+		assert.Nil(t, sender)
+		assert.Equal(t, payload, decrypted)
+	}
+
+	// other DIDs can't decrypt
+	_, _, err = eve.Unpack(jwe, resolver)
+	assert.Error(t, err)
+
+	// public-only DID document can't decrypt
+	_, _, err = bob.Public().Unpack(jwe, resolver)
+	assert.Error(t, err)
+
+	// specific key
+	jwe, err = PackAnoncrypt(payload, resolver, bob.ID+"#ka-key-2")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(jwe.Recipients))
+	decrypted, _, err := bob.Unpack(jwe, resolver)
+	assert.NoError(t, err)
+	assert.Equal(t, payload, decrypted)
+
+	// recipient can't be removed
+	jwe, _ = PackAnoncrypt(payload, resolver, alice.ID, bob.ID)
+	jwe.Recipients = jwe.Recipients[1:]
+	_, _, err = bob.Unpack(jwe, resolver)
+	assert.Error(t, err)
+
+	// tampered ciphertext
+	jwe, _ = PackAnoncrypt(payload, resolver, bob.ID)
+	jwe.Ciphertext = jwe.Ciphertext[:len(jwe.Ciphertext)-2] + "AA"
+	_, _, err = bob.Unpack(jwe, resolver)
+	assert.Error(t, err)
+
+	// recipient without keyAgreement keys
+	noKA := NewDID()
+	authKey, _ := NewDIDKey("auth-key", KeyTypeEdDSA)
+	authKey.AddPurpose(KeyPurposeAuthentication)
+	noKA.AddDIDKey(authKey)
+	_, err = PackAnoncrypt(payload, newTestResolver(noKA), noKA.ID)
+	assert.Error(t, err)
+
+	// no recipients
+	_, err = PackAnoncrypt(payload, resolver)
+	assert.Error(t, err)
+
+}
+
+func TestAuthcrypt(t *testing.T) {
+
+	alice, aliceKey := addTestKeys(t, NewDID(), "ka-key", KeyTypeX25519, KeyPurposeKeyAgreement)
+	bob, _ := addTestKeys(t, NewDID(), "ka-key", KeyTypeX25519, KeyPurposeKeyAgreement)
+	resolver := newTestResolver(alice.Public(), bob.Public())
+
+	payload := []byte("message")
+
+	jwe, err := aliceKey.PackAuthcrypt(payload, resolver, bob.ID)
+	assert.NoError(t, err)
+
+	decrypted, sender, err := bob.Unpack(jwe, resolver)
+	assert.NoError(t, err)
+	assert.Equal(t, payload, decrypted)
+	assert.Equal(t, aliceKey.Alias, sender.Alias)
+	assert.Equal(t, aliceKey.PublicKey, sender.PublicKey)
+
+	// sender key revoked
+	revoked := alice.Copy()
+	revoked.RevokeDIDKey(aliceKey.Alias)
+	_, _, err = bob.Unpack(jwe, newTestResolver(revoked, bob))
+	assert.Error(t, err)
+
+	// sender DID can't be resolved
+	eve, eveKey := addTestKeys(t, NewDID(), "ka-key", KeyTypeX25519, KeyPurposeKeyAgreement)
+	jwe, _ = eveKey.PackAuthcrypt(payload, newTestResolver(eve, bob), bob.ID)
+	_, _, err = bob.Unpack(jwe, newTestResolver(alice, bob))
+	assert.Error(t, err)
+
+	// sender key must be key agreement key with private key
+	signingKey, _ := NewDIDKey("signing-key", KeyTypeEdDSA)
+	signingKey.AddPurpose(KeyPurposeAuthentication)
+	alice.AddDIDKey(signingKey)
+	_, err = signingKey.PackAuthcrypt(payload, resolver, bob.ID)
+	assert.Error(t, err)
+	_, err = aliceKey.Public().PackAuthcrypt(payload, resolver, bob.ID)
+	assert.Error(t, err)
+
+}
+
+func TestDIDCommEndpoints(t *testing.T) {
+
+	did := NewDID()
+	didcomm, _ := NewService("didcomm", ServiceTypeDIDCommMessaging, "https://example.com/didcomm")
+	kyc, _ := NewService("kyc", "KYC", "https://kyc.example.com")
+	did.AddService(didcomm)
+	did.AddService(kyc)
+
+	routed, _ := NewDIDCommService("routed", "https://agent.example.com", []string{"did:example:mediator#key-1"}, []string{DIDCommProfileV2})
+	did.AddService(routed)
+
+	endpoints, err := did.DIDCommEndpoints()
+	assert.NoError(t, err)
+	assert.Equal(t, []*W3CServiceEndpoint{
+		{URI: "https://example.com/didcomm"},
+		{URI: "https://agent.example.com", Accept: []string{DIDCommProfileV2}, RoutingKeys: []string{"did:example:mediator#key-1"}},
+	}, endpoints)
+
+	// endpoint maps have their own routingKeys and accept, URIs of array get the custom fields
+	mapped, _ := NewService("mapped", ServiceTypeDIDCommMessaging, map[string]interface{}{"uri": "https://map.example.com", "accept": []string{DIDCommProfileV2}})
+	multi, _ := NewService("multi", ServiceTypeDIDCommMessaging, []interface{}{"https://a.example.com", map[string]interface{}{"uri": "https://b.example.com"}})
+	multi.SetCustomFields(map[string]interface{}{"routingKeys": []string{"did:example:mediator#key-1"}})
+	did = NewDID()
+	did.AddService(mapped)
+	did.AddService(multi)
+
+	endpoints, err = did.DIDCommEndpoints()
+	assert.NoError(t, err)
+	assert.Equal(t, []*W3CServiceEndpoint{
+		{URI: "https://map.example.com", Accept: []string{DIDCommProfileV2}},
+		{URI: "https://a.example.com", RoutingKeys: []string{"did:example:mediator#key-1"}},
+		{URI: "https://b.example.com"},
+	}, endpoints)
+
+	// endpoint map must have valid uri, routingKeys and accept
+	for _, endpoint := range []interface{}{
+		map[string]interface{}{"accept": []string{DIDCommProfileV2}},
+		map[string]interface{}{"uri": "example.com"},
+		[]interface{}{map[string]interface{}{"uri": "https://b.example.com", "routingKeys": []string{"not-did-url"}}},
+	} {
+		_, err = NewService("didcomm", ServiceTypeDIDCommMessaging, endpoint)
+		assert.NoError(t, err)
+		mapped.Endpoint = endpoint
+		_, err = did.DIDCommEndpoints()
+		assert.Error(t, err, "%v", endpoint)
+	}
+
+	endpoints, err = NewDID().DIDCommEndpoints()
+	assert.NoError(t, err)
+	assert.Empty(t, endpoints)
+
+}
+
+func TestNewDIDCommService(t *testing.T) {
+
+	service, err := NewDIDCommService("didcomm", "https://agent.example.com", []string{"did:example:mediator#key-1"}, []string{DIDCommProfileV2})
+	assert.NoError(t, err)
+	assert.Equal(t, ServiceTypeDIDCommMessaging, service.ServiceType)
+	assert.Equal(t, `{"accept":["didcomm/v2"],"routingKeys":["did:example:mediator#key-1"]}`, string(service.CustomField))
+
+	service, err = NewDIDCommService("didcomm", "https://agent.example.com", nil, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, service.CustomField)
+
+	// routing keys must be DID URLs of keys
+	_, err = NewDIDCommService("didcomm", "https://agent.example.com", []string{"did:example:mediator"}, nil)
+	assert.Error(t, err)
+	_, err = NewDIDCommService("didcomm", "https://agent.example.com", []string{"https://mediator.example.com#key-1"}, nil)
+	assert.Error(t, err)
+	_, err = NewDIDCommService("didcomm", "https://agent.example.com", nil, []string{""})
+	assert.Error(t, err)
+
+	service, _ = NewDIDCommService("didcomm", "https://agent.example.com", nil, nil)
+	_, err = service.SetCustomFields(map[string]interface{}{"routingKeys": "did:example:mediator#key-1"})
+	assert.Error(t, err)
+
+}
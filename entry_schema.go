@@ -0,0 +1,228 @@
+package factomdid
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// EntrySchema encodes and decodes content of DID entries of one entry schema version.
+// Entry schema is selected by the second ExtID of DID entry, so DID chain may contain entries of several versions
+type EntrySchema interface {
+	// Version returns entry schema version written into the second ExtID, e.g. "1.0.0"
+	Version() string
+	// EncodeManagement encodes content of DIDManagement entry
+	EncodeManagement(s *DIDManagementEntrySchema) ([]byte, error)
+	// DecodeManagement decodes and validates content of DIDManagement entry
+	DecodeManagement(content []byte) (*DIDManagementEntrySchema, error)
+	// EncodeUpdate encodes content of DIDUpdate entry
+	EncodeUpdate(s *DIDUpdateEntrySchema) ([]byte, error)
+	// DecodeUpdate decodes and validates content of DIDUpdate entry
+	DecodeUpdate(content []byte) (*DIDUpdateEntrySchema, error)
+	// EncodeVersionUpgrade encodes content of DIDMethodVersionUpgrade entry
+	EncodeVersionUpgrade(s *DIDMethodVersionUpgradeEntrySchema) ([]byte, error)
+	// DecodeVersionUpgrade decodes and validates content of DIDMethodVersionUpgrade entry
+	DecodeVersionUpgrade(content []byte) (*DIDMethodVersionUpgradeEntrySchema, error)
+	// ValidateContent validates encoded content of entry of entryType
+	ValidateContent(entryType string, content []byte) error
+}
+
+// EntryOption configures generation of DID entries
+type EntryOption func(*entryOptions)
+
+type entryOptions struct {
+	entrySchema string
+}
+
+var entrySchemas = map[string]EntrySchema{
+	EntrySchemaV100:    &embeddedEntrySchema{version: EntrySchemaV100},
+	EntrySchemaV100Ext: &embeddedEntrySchema{version: EntrySchemaV100Ext},
+}
+var entrySchemasMtx sync.RWMutex
+
+// versions of entry schemas defined by the library
+var builtinEntrySchemas = make(map[string]bool)
+
+func init() {
+	for version := range entrySchemas {
+		builtinEntrySchemas[version] = true
+	}
+}
+
+// WithEntrySchema sets entry schema version of generated entry, LatestEntrySchema by default.
+// DIDManagement entry must use entry schema of DID.ExtIDs, since it's a part of DID chain ID
+func WithEntrySchema(version string) EntryOption {
+	return func(o *entryOptions) {
+		o.entrySchema = version
+	}
+}
+
+// helper function that applies EntryOptions over defaults
+func newEntryOptions(opts []EntryOption) *entryOptions {
+
+	o := &entryOptions{entrySchema: LatestEntrySchema}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+
+}
+
+// RegisterEntrySchema registers EntrySchema, so entries of its version may be generated and resolved
+func RegisterEntrySchema(schema EntrySchema) error {
+
+	if schema == nil || schema.Version() == "" {
+		return fmt.Errorf("EntrySchema must have non-empty Version")
+	}
+
+	entrySchemasMtx.Lock()
+	defer entrySchemasMtx.Unlock()
+
+	if _, ok := entrySchemas[schema.Version()]; ok {
+		return fmt.Errorf("EntrySchema %s is already registered", schema.Version())
+	}
+
+	entrySchemas[schema.Version()] = schema
+
+	return nil
+
+}
+
+// helper function that removes EntrySchema of version, used by tests to undo RegisterEntrySchema.
+// Entry schemas defined by the library are never removed
+func unregisterEntrySchema(version string) {
+
+	entrySchemasMtx.Lock()
+	defer entrySchemasMtx.Unlock()
+
+	if !builtinEntrySchemas[version] {
+		delete(entrySchemas, version)
+	}
+
+}
+
+// GetEntrySchema returns registered EntrySchema of version
+func GetEntrySchema(version string) (EntrySchema, error) {
+
+	entrySchemasMtx.RLock()
+	defer entrySchemasMtx.RUnlock()
+
+	schema, ok := entrySchemas[version]
+	if !ok {
+		return nil, fmt.Errorf("Unsupported entry schema %s", version)
+	}
+
+	return schema, nil
+
+}
+
+// EntrySchemaVersions returns sorted versions of registered entry schemas
+func EntrySchemaVersions() []string {
+
+	entrySchemasMtx.RLock()
+	defer entrySchemasMtx.RUnlock()
+
+	versions := make([]string, 0, len(entrySchemas))
+	for v := range entrySchemas {
+		versions = append(versions, v)
+	}
+	sort.Strings(versions)
+
+	return versions
+
+}
+
+// Entry schema with JSON content validated with embedded schemas/<version>
+type embeddedEntrySchema struct {
+	version string
+}
+
+func (s *embeddedEntrySchema) Version() string {
+	return s.version
+}
+
+func (s *embeddedEntrySchema) EncodeManagement(v *DIDManagementEntrySchema) ([]byte, error) {
+	return s.encode(EntryTypeCreate, v)
+}
+
+func (s *embeddedEntrySchema) DecodeManagement(content []byte) (*DIDManagementEntrySchema, error) {
+
+	v := &DIDManagementEntrySchema{}
+
+	err := s.decode(EntryTypeCreate, content, v)
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+
+}
+
+func (s *embeddedEntrySchema) EncodeUpdate(v *DIDUpdateEntrySchema) ([]byte, error) {
+	return s.encode(EntryTypeUpdate, v)
+}
+
+func (s *embeddedEntrySchema) DecodeUpdate(content []byte) (*DIDUpdateEntrySchema, error) {
+
+	v := &DIDUpdateEntrySchema{}
+
+	err := s.decode(EntryTypeUpdate, content, v)
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+
+}
+
+func (s *embeddedEntrySchema) EncodeVersionUpgrade(v *DIDMethodVersionUpgradeEntrySchema) ([]byte, error) {
+	return s.encode(EntryTypeVersionUpgrade, v)
+}
+
+func (s *embeddedEntrySchema) DecodeVersionUpgrade(content []byte) (*DIDMethodVersionUpgradeEntrySchema, error) {
+
+	v := &DIDMethodVersionUpgradeEntrySchema{}
+
+	err := s.decode(EntryTypeVersionUpgrade, content, v)
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+
+}
+
+func (s *embeddedEntrySchema) ValidateContent(entryType string, content []byte) error {
+	return validateEmbeddedSchema(entryType, s.version, content)
+}
+
+// helper function that encodes content as JSON and validates it
+func (s *embeddedEntrySchema) encode(entryType string, v interface{}) ([]byte, error) {
+
+	content, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.ValidateContent(entryType, content)
+	if err != nil {
+		return nil, err
+	}
+
+	return content, nil
+
+}
+
+// helper function that validates JSON content and decodes it into v
+func (s *embeddedEntrySchema) decode(entryType string, content []byte, v interface{}) error {
+
+	err := s.ValidateContent(entryType, content)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(content, v)
+
+}
@@ -0,0 +1,58 @@
+package factomdid
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testEntrySchemaVersion = "1.0.0-test"
+
+// custom entry schema for tests, entry schema 1.0.0 under another version, counts decoded entries
+type testEntrySchema struct {
+	embeddedEntrySchema
+	decoded int
+}
+
+func (s *testEntrySchema) Version() string {
+	return testEntrySchemaVersion
+}
+
+func (s *testEntrySchema) DecodeUpdate(content []byte) (*DIDUpdateEntrySchema, error) {
+
+	s.decoded++
+
+	return s.embeddedEntrySchema.DecodeUpdate(content)
+
+}
+
+// helper function that registers testEntrySchema for the duration of the test
+func registerTestEntrySchema(t *testing.T) *testEntrySchema {
+
+	s := &testEntrySchema{embeddedEntrySchema: embeddedEntrySchema{version: EntrySchemaV100}}
+	assert.NoError(t, RegisterEntrySchema(s))
+	t.Cleanup(func() { unregisterEntrySchema(testEntrySchemaVersion) })
+
+	return s
+
+}
+
+func TestRegisterEntrySchema(t *testing.T) {
+
+	schema, err := GetEntrySchema(LatestEntrySchema)
+	assert.NoError(t, err)
+	assert.Equal(t, LatestEntrySchema, schema.Version())
+
+	_, err = GetEntrySchema("9.9.9")
+	assert.Error(t, err)
+
+	registerTestEntrySchema(t)
+	assert.Contains(t, EntrySchemaVersions(), EntrySchemaV100)
+	assert.Contains(t, EntrySchemaVersions(), testEntrySchemaVersion)
+
+	// duplicate registration
+	assert.Error(t, RegisterEntrySchema(&testEntrySchema{}))
+	assert.Error(t, RegisterEntrySchema(&embeddedEntrySchema{version: EntrySchemaV100}))
+	assert.Error(t, RegisterEntrySchema(nil))
+
+}
@@ -0,0 +1,23 @@
+package factomdid
+
+import "testing"
+
+// test helpers exported for external tests, which resolve DIDs published to factomdidtest.Ledger
+// (factomdidtest imports factomdid, so internal tests can't use it)
+
+const TestEntrySchemaVersion = testEntrySchemaVersion
+
+var (
+	AddTestKeys      = addTestKeys
+	SchemaErrorPaths = schemaErrorPaths
+)
+
+// RegisterTestEntrySchema registers entry schema 1.0.0 under TestEntrySchemaVersion for the duration of the test,
+// returns function which returns the number of decoded update entries
+func RegisterTestEntrySchema(t *testing.T) func() int {
+
+	s := registerTestEntrySchema(t)
+
+	return func() int { return s.decoded }
+
+}
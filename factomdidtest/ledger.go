@@ -0,0 +1,189 @@
+// Package factomdidtest provides in-memory simulated Factom ledger for testing DID flows without a node
+package factomdidtest
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/FactomProject/factom"
+
+	factomdid "github.com/DeFacto-Team/go-factom-did"
+)
+
+// Ledger is in-memory simulated Factom ledger, it implements factomdid.Publisher and factomdid.Fetcher.
+// Published entries are written into the current block, NextBlock starts a new one.
+// Like on Factom anyone can write entries into any existing chain, WriteBlock writes block at any height
+type Ledger struct {
+	mtx       sync.RWMutex
+	height    int64
+	timestamp time.Time
+	blockTime time.Duration
+	chains    map[string][]*factomdid.ChainEntry
+}
+
+const (
+	// DefaultBlockTime is time between Factom directory blocks
+	DefaultBlockTime = 10 * time.Minute
+)
+
+// NewLedger creates empty ledger with the current block at height 0 and current time
+func NewLedger() *Ledger {
+
+	return &Ledger{
+		timestamp: time.Now().UTC().Truncate(time.Second),
+		blockTime: DefaultBlockTime,
+		chains:    make(map[string][]*factomdid.ChainEntry),
+	}
+
+}
+
+// Height returns height of the current block
+func (l *Ledger) Height() int64 {
+
+	l.mtx.RLock()
+	defer l.mtx.RUnlock()
+
+	return l.height
+
+}
+
+// Time returns timestamp of the current block
+func (l *Ledger) Time() time.Time {
+
+	l.mtx.RLock()
+	defer l.mtx.RUnlock()
+
+	return l.timestamp
+
+}
+
+// NextBlock starts new block after DefaultBlockTime, returns its height
+func (l *Ledger) NextBlock() int64 {
+
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	l.height++
+	l.timestamp = l.timestamp.Add(l.blockTime)
+
+	return l.height
+
+}
+
+// PublishChain writes the first entry of new chain into the current block, fails if the chain already exists
+func (l *Ledger) PublishChain(entry *factom.Entry) (*factomdid.PublishResult, error) {
+
+	if entry == nil {
+		return nil, fmt.Errorf("Entry is empty")
+	}
+
+	e := *entry
+	e.ChainID = factom.ChainIDFromFields(e.ExtIDs)
+
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	if _, ok := l.chains[e.ChainID]; ok {
+		return nil, fmt.Errorf("Chain %s already exists", e.ChainID)
+	}
+
+	return l.write(&e, l.height, l.timestamp)
+
+}
+
+// PublishEntry writes entry into existing chain in the current block
+func (l *Ledger) PublishEntry(entry *factom.Entry) (*factomdid.PublishResult, error) {
+
+	if entry == nil {
+		return nil, fmt.Errorf("Entry is empty")
+	}
+
+	e := *entry
+
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	if _, ok := l.chains[e.ChainID]; !ok {
+		return nil, fmt.Errorf("Chain %s not found", e.ChainID)
+	}
+
+	return l.write(&e, l.height, l.timestamp)
+
+}
+
+// WriteBlock writes entries into block at height, e.g. to simulate blocks received out of order.
+// Entry of unknown chain creates it, entries can't be written before the first entry of their chain
+func (l *Ledger) WriteBlock(height int64, timestamp time.Time, entries ...*factom.Entry) error {
+
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	for i := range entries {
+
+		e := *entries[i]
+
+		if _, ok := l.chains[e.ChainID]; !ok {
+			if chainID := factom.ChainIDFromFields(e.ExtIDs); e.ChainID != "" && e.ChainID != chainID {
+				return fmt.Errorf("Chain %s not found", e.ChainID)
+			}
+			e.ChainID = factom.ChainIDFromFields(e.ExtIDs)
+		}
+
+		_, err := l.write(&e, height, timestamp)
+		if err != nil {
+			return err
+		}
+
+	}
+
+	return nil
+
+}
+
+// FetchChainEntries returns all entries of the chain ordered by block height and position in block
+func (l *Ledger) FetchChainEntries(chainID string) ([]*factomdid.ChainEntry, error) {
+
+	l.mtx.RLock()
+	defer l.mtx.RUnlock()
+
+	chain, ok := l.chains[chainID]
+	if !ok {
+		return nil, fmt.Errorf("Chain %s not found", chainID)
+	}
+
+	entries := make([]*factomdid.ChainEntry, len(chain))
+	for i := range chain {
+		e := *chain[i]
+		entries[i] = &e
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Height < entries[j].Height
+	})
+
+	return entries, nil
+
+}
+
+// helper function that writes entry into block, ledger must be locked
+func (l *Ledger) write(entry *factom.Entry, height int64, timestamp time.Time) (*factomdid.PublishResult, error) {
+
+	_, err := factomdid.EntryCost(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	chain := l.chains[entry.ChainID]
+	if len(chain) > 0 && height < chain[0].Height {
+		return nil, fmt.Errorf("Entry can't be written before the first entry of chain %s", entry.ChainID)
+	}
+
+	hash := hex.EncodeToString(entry.Hash())
+	l.chains[entry.ChainID] = append(chain, &factomdid.ChainEntry{Entry: entry, EntryHash: hash, Height: height, Timestamp: timestamp})
+
+	return &factomdid.PublishResult{ChainID: entry.ChainID, EntryHash: hash}, nil
+
+}
@@ -0,0 +1,270 @@
+package factomdidtest
+
+import (
+	"testing"
+
+	"github.com/FactomProject/factom"
+	"github.com/stretchr/testify/assert"
+
+	factomdid "github.com/DeFacto-Team/go-factom-did"
+)
+
+// helper function that creates DID document with management keys of priority 0 and 1 and publishes it
+func newPublishedDID(t *testing.T, ledger *Ledger) *factomdid.DID {
+
+	did := factomdid.NewDID()
+
+	didKey, _ := factomdid.NewDIDKey("did-key", factomdid.KeyTypeEdDSA)
+	didKey.AddPurpose(factomdid.KeyPurposeAuthentication)
+	mgmtKey0, _ := factomdid.NewManagementKey("mgmt-key-0", factomdid.KeyTypeECDSA, 0)
+	mgmtKey1, _ := factomdid.NewManagementKey("mgmt-key-1", factomdid.KeyTypeEdDSA, 1)
+	mgmtKey0.SetPriorityRequirement(0)
+
+	did.AddDIDKey(didKey)
+	did.AddManagementKey(mgmtKey0)
+	did.AddManagementKey(mgmtKey1)
+
+	_, err := did.CreateAndPublish(ledger)
+	assert.NoError(t, err)
+
+	return did
+
+}
+
+// helper function that returns DID document with added DIDKey
+func withDIDKey(t *testing.T, did *factomdid.DID, alias string) *factomdid.DID {
+
+	updated := did.Copy()
+	key, _ := factomdid.NewDIDKey(alias, factomdid.KeyTypeEdDSA)
+	key.AddPurpose(factomdid.KeyPurposePublic)
+	_, err := updated.AddDIDKey(key)
+	assert.NoError(t, err)
+
+	return updated
+
+}
+
+// helper function that checks whether resolved DID document has DIDKey
+func hasDIDKey(did *factomdid.DID, alias string) bool {
+
+	for _, v := range did.DIDKeys {
+		if v.Alias == alias {
+			return true
+		}
+	}
+
+	return false
+
+}
+
+func TestLedgerResolve(t *testing.T) {
+
+	ledger := NewLedger()
+	resolver := factomdid.NewChainResolver(ledger, factomdid.NetworkTestnet)
+	did := newPublishedDID(t, ledger)
+	created := ledger.Time()
+
+	resolved, err := resolver.Resolve(did.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, did.Public().DIDKeys, resolved.DIDKeys)
+	assert.Equal(t, did.Public().ManagementKeys, resolved.ManagementKeys)
+	assert.Equal(t, factomdid.NetworkTestnet, resolved.Network)
+
+	ledger.NextBlock()
+	updated := withDIDKey(t, did, "new-key")
+	_, err = did.UpdateAndPublish(updated, "mgmt-key-1", ledger)
+	assert.NoError(t, err)
+
+	resolved, err = resolver.Resolve(did.ID)
+	assert.NoError(t, err)
+	assert.True(t, hasDIDKey(resolved, "new-key"))
+
+	// DID document as it was before the update
+	resolved, err = resolver.ResolveAt(did.ID, created)
+	assert.NoError(t, err)
+	assert.False(t, hasDIDKey(resolved, "new-key"))
+
+	// version upgrade
+	_, err = updated.UpgradeVersionAndPublish("0.3.0", "mgmt-key-1", ledger)
+	assert.NoError(t, err)
+
+	// deactivation
+	ledger.NextBlock()
+	_, err = updated.DeactivateAndPublish("mgmt-key-0", ledger)
+	assert.NoError(t, err)
+
+	_, err = resolver.Resolve(did.ID)
+	assert.Error(t, err)
+
+	// DID document can be resolved as it was before deactivation
+	resolved, err = resolver.ResolveAt(did.ID, created)
+	assert.NoError(t, err)
+
+	// DID on another network
+	_, err = resolver.Resolve("did:factom:mainnet:" + did.GetChainID())
+	assert.Error(t, err)
+
+	// unknown DID
+	_, err = resolver.Resolve(factomdid.NewDID().ID)
+	assert.Error(t, err)
+
+}
+
+func TestLedgerAttackerEntries(t *testing.T) {
+
+	ledger := NewLedger()
+	resolver := factomdid.NewChainResolver(ledger, factomdid.NetworkUnspecified)
+	did := newPublishedDID(t, ledger)
+
+	// attacker signs update with own key, but claims victim's ManagementKey
+	attacker := did.Copy()
+	attackerKey, _ := factomdid.NewManagementKey("mgmt-key-0", factomdid.KeyTypeECDSA, 0)
+	attackerKey.Controller = did.ID
+	attacker.ManagementKeys[0] = attackerKey
+	_, err := attacker.UpdateAndPublish(withDIDKey(t, attacker, "attacker-key"), "mgmt-key-0", ledger)
+	assert.NoError(t, err)
+
+	// attacker signs update with ManagementKey of another DID
+	attackerDID := newPublishedDID(t, ledger)
+	fe, err := attackerDID.Update(withDIDKey(t, attackerDID, "attacker-key"), "mgmt-key-0")
+	assert.NoError(t, err)
+	fe.ChainID = did.GetChainID()
+	_, err = ledger.PublishEntry(fe)
+	assert.NoError(t, err)
+
+	// key with priority 1 can't revoke key with priority requirement 0
+	updated := did.Copy()
+	updated.RevokeManagementKey("mgmt-key-0")
+	mgmtKey, _ := factomdid.NewManagementKey("mgmt-key-2", factomdid.KeyTypeEdDSA, 0)
+	updated.AddManagementKey(mgmtKey)
+	fe, err = did.Update(updated, "mgmt-key-0")
+	assert.NoError(t, err)
+	fe.ExtIDs[2] = []byte(did.ID + "#mgmt-key-1")
+	fe.ExtIDs[3], _ = did.ManagementKeys[1].Sign([]byte(string(fe.ExtIDs[0]) + string(fe.ExtIDs[1]) + string(fe.ExtIDs[2]) + string(fe.Content)))
+	_, err = ledger.PublishEntry(fe)
+	assert.NoError(t, err)
+
+	// deactivation with key of priority 1
+	fe, err = did.Deactivate("mgmt-key-0")
+	assert.NoError(t, err)
+	fe.ExtIDs[2] = []byte(did.ID + "#mgmt-key-1")
+	fe.ExtIDs[3], _ = did.ManagementKeys[1].Sign([]byte(string(fe.ExtIDs[0]) + string(fe.ExtIDs[1]) + string(fe.ExtIDs[2])))
+	_, err = ledger.PublishEntry(fe)
+	assert.NoError(t, err)
+
+	// garbage entry
+	_, err = ledger.PublishEntry(&factom.Entry{ChainID: did.GetChainID(), ExtIDs: [][]byte{[]byte("DIDUpdate")}, Content: []byte("{")})
+	assert.NoError(t, err)
+
+	resolved, err := resolver.Resolve(did.ID)
+	assert.NoError(t, err)
+	assert.False(t, hasDIDKey(resolved, "attacker-key"))
+	assert.Equal(t, did.Public().ManagementKeys, resolved.ManagementKeys)
+
+}
+
+func TestLedgerReplayedEntry(t *testing.T) {
+
+	ledger := NewLedger()
+	resolver := factomdid.NewChainResolver(ledger, factomdid.NetworkUnspecified)
+	did := newPublishedDID(t, ledger)
+
+	updated := withDIDKey(t, did, "new-key")
+	add, err := did.Update(updated, "mgmt-key-0")
+	assert.NoError(t, err)
+	_, err = ledger.PublishEntry(add)
+	assert.NoError(t, err)
+
+	revoked := updated.Copy()
+	revoked.RevokeDIDKey("new-key")
+	_, err = updated.UpdateAndPublish(revoked, "mgmt-key-0", ledger)
+	assert.NoError(t, err)
+
+	// attacker replays the entry which added revoked key
+	_, err = ledger.PublishEntry(add)
+	assert.NoError(t, err)
+
+	resolved, err := resolver.Resolve(did.ID)
+	assert.NoError(t, err)
+	assert.False(t, hasDIDKey(resolved, "new-key"))
+
+}
+
+func TestLedgerDuplicateChain(t *testing.T) {
+
+	ledger := NewLedger()
+	resolver := factomdid.NewChainResolver(ledger, factomdid.NetworkUnspecified)
+	did := newPublishedDID(t, ledger)
+
+	// the same DIDManagement entry with other keys can't create the chain again
+	other := did.Copy()
+	other.DIDKeys = nil
+	other.AddDIDKey(withDIDKey(t, other, "attacker-key").DIDKeys[0])
+	_, err := other.CreateAndPublish(ledger)
+	assert.Error(t, err)
+
+	// DIDManagement entry written into existing chain is not the first entry and is ignored
+	fe, err := other.Create()
+	assert.NoError(t, err)
+	fe.ChainID = did.GetChainID()
+	_, err = ledger.PublishEntry(fe)
+	assert.NoError(t, err)
+
+	resolved, err := resolver.Resolve(did.ID)
+	assert.NoError(t, err)
+	assert.False(t, hasDIDKey(resolved, "attacker-key"))
+
+	// chain which doesn't start with DIDManagement entry is not a DID
+	chain := factom.NewChain(&factom.Entry{ExtIDs: [][]byte{[]byte("test")}})
+	_, err = ledger.PublishChain(chain.FirstEntry)
+	assert.NoError(t, err)
+	_, err = resolver.Resolve("did:factom:" + chain.ChainID)
+	assert.Error(t, err)
+
+}
+
+func TestLedgerOutOfOrderBlocks(t *testing.T) {
+
+	ledger := NewLedger()
+	resolver := factomdid.NewChainResolver(ledger, factomdid.NetworkUnspecified)
+	did := newPublishedDID(t, ledger)
+
+	updated := withDIDKey(t, did, "new-key")
+	add, err := did.Update(updated, "mgmt-key-0")
+	assert.NoError(t, err)
+
+	revoked := updated.Copy()
+	revoked.RevokeDIDKey("new-key")
+	revoke, err := updated.Update(revoked, "mgmt-key-0")
+	assert.NoError(t, err)
+
+	// block 2 is received before block 1
+	t1 := ledger.Time().Add(DefaultBlockTime)
+	t2 := t1.Add(DefaultBlockTime)
+	assert.NoError(t, ledger.WriteBlock(2, t2, revoke))
+	assert.NoError(t, ledger.WriteBlock(1, t1, add))
+
+	entries, err := ledger.FetchChainEntries(did.GetChainID())
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(entries))
+	assert.Equal(t, int64(1), entries[1].Height)
+	assert.Equal(t, int64(2), entries[2].Height)
+
+	resolved, err := resolver.Resolve(did.ID)
+	assert.NoError(t, err)
+	assert.False(t, hasDIDKey(resolved, "new-key"))
+
+	resolved, err = resolver.ResolveAt(did.ID, t1)
+	assert.NoError(t, err)
+	assert.True(t, hasDIDKey(resolved, "new-key"))
+
+	// entries can't precede the first entry of the chain
+	ledger = NewLedger()
+	ledger.NextBlock()
+	did = newPublishedDID(t, ledger)
+	add, err = did.Update(withDIDKey(t, did, "new-key"), "mgmt-key-0")
+	assert.NoError(t, err)
+	assert.Error(t, ledger.WriteBlock(0, ledger.Time(), add))
+	assert.NoError(t, ledger.WriteBlock(1, ledger.Time(), add))
+
+}
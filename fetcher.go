@@ -0,0 +1,83 @@
+package factomdid
+
+import (
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/FactomProject/factom"
+)
+
+// ChainEntry is Factom entry with data of the block it was written in
+type ChainEntry struct {
+	Entry     *factom.Entry `json:"entry" form:"entry" query:"entry"`
+	EntryHash string        `json:"entryHash" form:"entryHash" query:"entryHash"`
+	Height    int64         `json:"height" form:"height" query:"height"`
+	Timestamp time.Time     `json:"timestamp" form:"timestamp" query:"timestamp"`
+}
+
+// Fetcher fetches entries of Factom chains
+type Fetcher interface {
+	// FetchChainEntries returns all entries of the chain ordered by block height and position in block,
+	// returns error if the chain doesn't exist
+	FetchChainEntries(chainID string) ([]*ChainEntry, error)
+}
+
+// FactomFetcher fetches entries from factomd (see factom.SetFactomdServer)
+type FactomFetcher struct{}
+
+const (
+	// zeroKeyMR is PrevKeyMR of the first entry block of chain
+	zeroKeyMR = "0000000000000000000000000000000000000000000000000000000000000000"
+)
+
+// NewFactomFetcher creates FactomFetcher
+func NewFactomFetcher() *FactomFetcher {
+	return &FactomFetcher{}
+}
+
+// FetchChainEntries walks entry blocks of the chain from its head and returns entries in chain order
+func (f *FactomFetcher) FetchChainEntries(chainID string) ([]*ChainEntry, error) {
+
+	head, _, err := factom.GetChainHead(chainID)
+	if err != nil {
+		return nil, err
+	}
+
+	if head == "" {
+		return nil, fmt.Errorf("Chain %s not found", chainID)
+	}
+
+	var blocks []*factom.EBlock
+
+	for keyMR := head; keyMR != zeroKeyMR; {
+		eb, err := factom.GetEBlock(keyMR)
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, eb)
+		keyMR = eb.Header.PrevKeyMR
+	}
+
+	var entries []*ChainEntry
+
+	for i := len(blocks) - 1; i >= 0; i-- {
+		for _, v := range blocks[i].EntryList {
+
+			entry, err := factom.GetEntry(v.EntryHash)
+			if err != nil {
+				return nil, err
+			}
+
+			if hex.EncodeToString(entry.Hash()) != v.EntryHash {
+				return nil, fmt.Errorf("Entry %s doesn't match its hash", v.EntryHash)
+			}
+
+			entries = append(entries, &ChainEntry{Entry: entry, EntryHash: v.EntryHash, Height: blocks[i].Header.DBHeight, Timestamp: time.Unix(v.Timestamp, 0)})
+
+		}
+	}
+
+	return entries, nil
+
+}
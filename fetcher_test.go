@@ -0,0 +1,87 @@
+package factomdid
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/FactomProject/factom"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFactomFetcher(t *testing.T) {
+
+	chain := factom.NewChain(&factom.Entry{ExtIDs: [][]byte{[]byte("test")}, Content: []byte("first")})
+	second := &factom.Entry{ChainID: chain.ChainID, Content: []byte("second")}
+	third := &factom.Entry{ChainID: chain.ChainID, Content: []byte("third")}
+
+	entries := map[string]*factom.Entry{}
+	for _, e := range []*factom.Entry{chain.FirstEntry, second, third} {
+		entries[hex.EncodeToString(e.Hash())] = e
+	}
+
+	// fake factomd with 2 entry blocks: "eb1" at height 10 (first, second) and "eb2" at height 12 (third)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		req := &factom.JSON2Request{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(req))
+		params := make(map[string]interface{})
+		json.Unmarshal(req.Params, &params)
+
+		var result interface{}
+		eb := &factom.EBlock{}
+
+		switch req.Method {
+		case "chain-head":
+			if params["chainid"] == chain.ChainID {
+				result = map[string]string{"chainhead": "eb2"}
+			} else {
+				result = map[string]string{"chainhead": ""}
+			}
+		case "entry-block":
+			eb.Header.ChainID = chain.ChainID
+			if params["keymr"] == "eb2" {
+				eb.Header.PrevKeyMR = "eb1"
+				eb.Header.DBHeight = 12
+				eb.EntryList = []factom.EBEntry{{EntryHash: hex.EncodeToString(third.Hash()), Timestamp: 1600000600}}
+			} else {
+				eb.Header.PrevKeyMR = zeroKeyMR
+				eb.Header.DBHeight = 10
+				eb.EntryList = []factom.EBEntry{{EntryHash: hex.EncodeToString(chain.FirstEntry.Hash()), Timestamp: 1600000000}, {EntryHash: hex.EncodeToString(second.Hash()), Timestamp: 1600000060}}
+			}
+			result = eb
+		case "entry":
+			result = entries[params["hash"].(string)]
+		}
+
+		resp := factom.NewJSON2Response()
+		resp.ID = req.ID
+		resp.Result, _ = json.Marshal(result)
+		json.NewEncoder(w).Encode(resp)
+
+	}))
+	defer server.Close()
+
+	factomdServer := factom.FactomdServer()
+	factom.SetFactomdServer(server.URL)
+	defer factom.SetFactomdServer(factomdServer)
+
+	fetched, err := NewFactomFetcher().FetchChainEntries(chain.ChainID)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(fetched))
+
+	assert.Equal(t, []byte("first"), fetched[0].Entry.Content)
+	assert.Equal(t, []byte("second"), fetched[1].Entry.Content)
+	assert.Equal(t, []byte("third"), fetched[2].Entry.Content)
+	assert.Equal(t, int64(10), fetched[1].Height)
+	assert.Equal(t, int64(12), fetched[2].Height)
+	assert.Equal(t, int64(1600000060), fetched[1].Timestamp.Unix())
+	assert.Equal(t, hex.EncodeToString(third.Hash()), fetched[2].EntryHash)
+
+	// chain doesn't exist
+	_, err = NewFactomFetcher().FetchChainEntries(NewDID().GetChainID())
+	assert.Error(t, err)
+
+}
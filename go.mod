@@ -1,15 +1,32 @@
 module github.com/DeFacto-Team/go-factom-did
 
-go 1.14
+go 1.20
 
 require (
 	github.com/FactomProject/btcutil v0.0.0-20160826074221-43986820ccd5
-	github.com/FactomProject/ed25519 v0.0.0-20150814230546-38002c4fe7b6 // indirect
 	github.com/FactomProject/factom v0.3.6-0.20201019072706-4724045d7aee
-	github.com/btcsuite/fastsha256 v0.0.0-20160815193821-637e65642941 // indirect
 	github.com/frankbraun/dcrd v0.0.2
-	github.com/go-playground/universal-translator v0.17.0 // indirect
-	github.com/leodido/go-urn v1.2.0 // indirect
+	github.com/santhosh-tekuri/jsonschema/v5 v5.3.1
 	github.com/stretchr/testify v1.6.1
+	golang.org/x/crypto v0.0.0-20200622213623-75b288015ac9
 	gopkg.in/go-playground/validator.v9 v9.31.0
 )
+
+require (
+	github.com/FactomProject/basen v0.0.0-20150613233007-fe3947df716e // indirect
+	github.com/FactomProject/btcutilecc v0.0.0-20130527213604-d3a63a5752ec // indirect
+	github.com/FactomProject/ed25519 v0.0.0-20150814230546-38002c4fe7b6 // indirect
+	github.com/FactomProject/go-bip32 v0.3.5 // indirect
+	github.com/FactomProject/go-bip39 v0.3.5 // indirect
+	github.com/FactomProject/go-bip44 v0.0.0-20190306062959-b541a96d8da9 // indirect
+	github.com/FactomProject/go-simplejson v0.5.0 // indirect
+	github.com/FactomProject/netki-go-partner-client v0.0.0-20160324224126-426acb535e66 // indirect
+	github.com/btcsuite/fastsha256 v0.0.0-20160815193821-637e65642941 // indirect
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/go-playground/locales v0.13.0 // indirect
+	github.com/go-playground/universal-translator v0.17.0 // indirect
+	github.com/leodido/go-urn v1.2.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/net v0.0.0-20200813134508-3edf25e44fcc // indirect
+	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c // indirect
+)
@@ -0,0 +1,195 @@
+package factomdid
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/FactomProject/factom"
+	"github.com/frankbraun/dcrd/dcrec/secp256k1"
+	"github.com/stretchr/testify/assert"
+)
+
+// goldenVectors is language-neutral file with fixed inputs and expected DID entries.
+// The file is a static fixture, tests never rewrite it
+type goldenVectors struct {
+	Description string         `json:"description"`
+	Nonce       string         `json:"nonce"`
+	Keys        []*goldenKey   `json:"keys"`
+	Entries     []*goldenEntry `json:"entries"`
+}
+
+type goldenKey struct {
+	Alias      string `json:"alias"`
+	KeyType    string `json:"keyType"`
+	PrivateKey string `json:"privateKey"`
+	PublicKey  string `json:"publicKey"`
+}
+
+type goldenEntry struct {
+	Name      string   `json:"name"`
+	ChainID   string   `json:"chainId"`
+	ExtIDs    []string `json:"extIds"`
+	Content   string   `json:"content"`
+	Entry     string   `json:"entry"`
+	EntryHash string   `json:"entryHash"`
+}
+
+// helper function that returns 32 bytes of b
+func goldenBytes(b byte) []byte {
+
+	r := make([]byte, 32)
+	for i := range r {
+		r[i] = b
+	}
+
+	return r
+
+}
+
+// helper function that sets fixed Ed25519 key pair derived from seed
+func setGoldenEd25519Key(key *AbstractKey, seed []byte) {
+
+	privateKey := ed25519.NewKeyFromSeed(seed)
+	key.PrivateKey = privateKey
+	key.PublicKey = privateKey.Public().(ed25519.PublicKey)
+
+}
+
+// helper function that sets fixed secp256k1 key pair
+func setGoldenSecp256k1Key(key *AbstractKey, privateKey []byte) {
+
+	priv, pub := secp256k1.PrivKeyFromBytes(secp256k1.S256(), privateKey)
+	key.PrivateKey = priv.Serialize()
+	key.PublicKey = pub.Serialize()
+
+}
+
+// helper function that generates DID entries of every type from fixed keys and nonce
+func newGoldenVectors(t *testing.T) *goldenVectors {
+
+	nonce := make([]byte, NonceSize)
+	for i := range nonce {
+		nonce[i] = byte(i)
+	}
+
+	did, err := NewDIDWithNonce(nonce)
+	assert.NoError(t, err)
+
+	mgmtKey0, _ := NewManagementKey("mgmt-key-0", KeyTypeEdDSA, 0)
+	setGoldenEd25519Key(&mgmtKey0.AbstractKey, goldenBytes(1))
+	mgmtKey0.SetPriorityRequirement(0)
+	mgmtKey1, _ := NewManagementKey("mgmt-key-1", KeyTypeECDSA, 1)
+	setGoldenSecp256k1Key(&mgmtKey1.AbstractKey, goldenBytes(2))
+
+	didKey, _ := NewDIDKey("did-key", KeyTypeEdDSA)
+	setGoldenEd25519Key(&didKey.AbstractKey, goldenBytes(3))
+	didKey.AddPurpose(KeyPurposePublic)
+	didKey.AddPurpose(KeyPurposeAuthentication)
+	didKey.SetPriorityRequirement(1)
+
+	service, _ := NewService("messages", "MessagingService", "https://example.com/messages")
+	service.SetPriorityRequirement(1)
+
+	did.AddManagementKey(mgmtKey0)
+	did.AddManagementKey(mgmtKey1)
+	did.AddDIDKey(didKey)
+	did.AddService(service)
+
+	vectors := &goldenVectors{Nonce: hex.EncodeToString(nonce)}
+	for _, key := range []*AbstractKey{&mgmtKey0.AbstractKey, &mgmtKey1.AbstractKey, &didKey.AbstractKey} {
+		vectors.Keys = append(vectors.Keys, &goldenKey{Alias: key.Alias, KeyType: key.KeyType, PrivateKey: hex.EncodeToString(key.PrivateKey), PublicKey: hex.EncodeToString(key.PublicKey)})
+	}
+
+	add := func(name string, fe *factom.Entry, err error) {
+		if !assert.NoError(t, err, name) {
+			return
+		}
+		if fe.ChainID == "" {
+			fe.ChainID = factom.ChainIDFromFields(fe.ExtIDs)
+		}
+		data, err := fe.MarshalBinary()
+		assert.NoError(t, err)
+		v := &goldenEntry{Name: name, ChainID: fe.ChainID, Content: string(fe.Content), Entry: hex.EncodeToString(data), EntryHash: hex.EncodeToString(fe.Hash())}
+		for _, extID := range fe.ExtIDs {
+			v.ExtIDs = append(v.ExtIDs, hex.EncodeToString(extID))
+		}
+		vectors.Entries = append(vectors.Entries, v)
+	}
+
+	fe, err := did.Create()
+	add("DIDManagement", fe, err)
+
+	// add and revoke sections
+	updated := did.Copy()
+	newKey, _ := NewDIDKey("new-key", KeyTypeECDSA)
+	setGoldenSecp256k1Key(&newKey.AbstractKey, goldenBytes(4))
+	newKey.AddPurpose(KeyPurposePublic)
+	updated.AddDIDKey(newKey)
+	updated.RevokeService("messages")
+	fe, err = did.Update(updated, "mgmt-key-0")
+	add("DIDUpdate", fe, err)
+
+	// revoke section only, signed with secp256k1 key
+	revoked := updated.Copy()
+	revoked.RevokeDIDKey("new-key")
+	fe, err = updated.Update(revoked, "mgmt-key-1")
+	add("DIDUpdate revoke only", fe, err)
+
+	fe, err = revoked.UpgradeVersion("0.3.0", "mgmt-key-1")
+	add("DIDMethodVersionUpgrade", fe, err)
+
+	fe, err = revoked.Deactivate("mgmt-key-0")
+	add("DIDDeactivation", fe, err)
+
+	return vectors
+
+}
+
+func TestGoldenVectors(t *testing.T) {
+
+	file := filepath.Join("testdata", "golden", EntrySchemaV100+".json")
+
+	vectors := newGoldenVectors(t)
+
+	// signatures are deterministic
+	again := newGoldenVectors(t)
+	assert.Equal(t, vectors, again)
+
+	data, err := ioutil.ReadFile(file)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	expected := &goldenVectors{}
+	assert.NoError(t, json.Unmarshal(data, expected))
+	assert.Equal(t, expected.Nonce, vectors.Nonce)
+	assert.Equal(t, expected.Keys, vectors.Keys)
+	assert.Equal(t, expected.Entries, vectors.Entries)
+
+	// expected entries are valid and are applied by ChainResolver in order
+	var state *chainState
+	for i, v := range expected.Entries {
+		fe := &factom.Entry{ChainID: v.ChainID, Content: []byte(v.Content)}
+		for _, extID := range v.ExtIDs {
+			b, _ := hex.DecodeString(extID)
+			fe.ExtIDs = append(fe.ExtIDs, b)
+		}
+		assert.Equal(t, v.EntryHash, hex.EncodeToString(fe.Hash()), v.Name)
+		assert.NoError(t, ValidateEntry(fe), v.Name)
+		if i == 0 {
+			state, err = newChainState(v.ChainID, fe)
+			if !assert.NoError(t, err) {
+				return
+			}
+			continue
+		}
+		assert.NoError(t, state.apply(fe), v.Name)
+	}
+	assert.True(t, state.deactivated)
+	assert.Equal(t, "0.3.0", state.version)
+
+}
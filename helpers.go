@@ -2,49 +2,60 @@ package factomdid
 
 import (
 	"fmt"
-	"math/rand"
-	"sync"
-	"time"
+	"io"
+	"regexp"
 
 	"github.com/FactomProject/factom"
 	"gopkg.in/go-playground/validator.v9"
 )
 
-var rng *rand.Rand
-var rngMtx sync.Mutex
 var validate *validator.Validate
+var versionRegexp = regexp.MustCompile(`^[0-9]+\.[0-9]+\.[0-9]+$`)
 
 func init() {
-	rng = rand.New(rand.NewSource(time.Now().UnixNano()))
 	validate = validator.New()
+	validate.RegisterValidation("keytype", validateKeyType)
+	validate.RegisterValidation("serviceendpoint", validateServiceEndpoint)
 }
 
-// generateNonce() generates random 32 bytes nonce
-func generateNonce() []byte {
-	nonce := make([]byte, 64)
-	rngMtx.Lock()
-	rng.Read(nonce)
-	rngMtx.Unlock()
+// generateNonce() reads NonceSize bytes nonce from r, e.g. crypto/rand.Reader
+func generateNonce(r io.Reader) ([]byte, error) {
+	nonce := make([]byte, NonceSize)
 
-	return nonce
+	_, err := io.ReadFull(r, nonce)
+	if err != nil {
+		return nil, fmt.Errorf("Can't generate nonce: %v", err)
+	}
+
+	return nonce, nil
+}
+
+// Validates nonce size
+func validateNonce(nonce []byte) error {
+	if len(nonce) < MinNonceSize || len(nonce) > MaxNonceSize {
+		return fmt.Errorf("Nonce must be %d-%d bytes, got %d bytes", MinNonceSize, MaxNonceSize, len(nonce))
+	}
+
+	return nil
 }
 
-// Calculates ChainID based on DID extID
-func calculateChainID(extIDs [][]byte) (string, error) {
+// Calculates ChainID based on DID extID and entry schema version of DIDManagement entry
+func calculateChainID(entrySchema string, extIDs [][]byte) (string, error) {
 	if len(extIDs) == 0 {
 		return "", fmt.Errorf("extIDs should not be empty")
 	}
 
-	prefix := [][]byte{[]byte(EntryTypeCreate), []byte(EntrySchemaV100)}
+	prefix := [][]byte{[]byte(EntryTypeCreate), []byte(entrySchema)}
 	return factom.ChainIDFromFields(append(prefix, extIDs...)), nil
 }
 
-// Calculates entry size
+// Calculates entry size like Factom network does: content and ExtIDs with 2 bytes size prefix each,
+// entry header (version, ChainID, ExtIDs size) is not counted
 func calculateEntrySize(entry *factom.Entry) int {
 	size := len(entry.Content)
 
 	for _, extid := range entry.ExtIDs {
-		size += len(extid)
+		size += 2 + len(extid)
 	}
 
 	return size
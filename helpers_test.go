@@ -1,21 +1,55 @@
 package factomdid
 
 import (
+	"bytes"
+	"crypto/rand"
 	"testing"
 
 	"github.com/FactomProject/factom"
 	"github.com/stretchr/testify/assert"
 )
 
+// helper function that adds DIDKey of alias, keyType and purpose and Ed25519 management key "default-mgmt-key"
+// to DID document, returns the DID document and the DIDKey
+func addTestKeys(t *testing.T, did *DID, alias string, keyType string, purpose string) (*DID, *DIDKey) {
+
+	didKey, err := NewDIDKey(alias, keyType)
+	assert.NoError(t, err)
+	didKey.AddPurpose(purpose)
+	mgmtKey, err := NewManagementKey("default-mgmt-key", KeyTypeEdDSA, 0)
+	assert.NoError(t, err)
+
+	_, err = did.AddDIDKey(didKey)
+	assert.NoError(t, err)
+	_, err = did.AddManagementKey(mgmtKey)
+	assert.NoError(t, err)
+
+	assert.NoError(t, did.Validate())
+
+	return did, didKey
+
+}
+
 func TestGenerateNonce(t *testing.T) {
 
-	n1 := generateNonce()
-	n2 := generateNonce()
+	n1, err := generateNonce(rand.Reader)
+	assert.NoError(t, err)
+	n2, err := generateNonce(rand.Reader)
+	assert.NoError(t, err)
 
-	assert.NotEmpty(t, n1)
-	assert.NotEmpty(t, n2)
+	assert.Equal(t, NonceSize, len(n1))
+	assert.Equal(t, NonceSize, len(n2))
 	assert.NotEqual(t, n1, n2)
 
+	// deterministic reader
+	n1, err = generateNonce(bytes.NewReader(bytes.Repeat([]byte{1}, NonceSize)))
+	assert.NoError(t, err)
+	assert.Equal(t, bytes.Repeat([]byte{1}, NonceSize), n1)
+
+	// reader is too short
+	_, err = generateNonce(bytes.NewReader(bytes.Repeat([]byte{1}, NonceSize-1)))
+	assert.Error(t, err)
+
 }
 
 func TestCalculateChainID(t *testing.T) {
@@ -23,11 +57,16 @@ func TestCalculateChainID(t *testing.T) {
 	d := &DID{}
 	d.ExtIDs = append(d.ExtIDs, []byte("a32bf0ca979d58cd6970def0fcc666c1f4c262532ed53851bf041be204d2bd92"))
 
-	chainID, err := calculateChainID(d.ExtIDs)
+	chainID, err := calculateChainID(EntrySchemaV100, d.ExtIDs)
 
 	assert.NoError(t, err)
 	assert.Equal(t, "301a57c2e753d061928cf6b6a692ea052885d75d2af5640e9b5cbc8897bbf7d5", chainID)
 
+	// entry schema version is a part of chain ID
+	chainID, err = calculateChainID("1.0.0+factomdid-ext", d.ExtIDs)
+	assert.NoError(t, err)
+	assert.NotEqual(t, "301a57c2e753d061928cf6b6a692ea052885d75d2af5640e9b5cbc8897bbf7d5", chainID)
+
 }
 
 func TestCalculateEntrySize(t *testing.T) {
@@ -38,6 +77,6 @@ func TestCalculateEntrySize(t *testing.T) {
 
 	s := calculateEntrySize(entry)
 
-	assert.Equal(t, 65, s)
+	assert.Equal(t, 67, s)
 
 }
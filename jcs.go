@@ -0,0 +1,349 @@
+package factomdid
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// CanonicalizeJSON canonicalizes JSON text according to RFC 8785 JSON Canonicalization Scheme (JCS):
+// object members are sorted by UTF-16 code units of their names, whitespace is removed,
+// strings and numbers are serialized like ECMAScript JSON.stringify.
+// Duplicate member names, invalid UTF-8, lone UTF-16 surrogates and numbers out of IEEE 754 double range are rejected
+func CanonicalizeJSON(data []byte) ([]byte, error) {
+
+	if !utf8.Valid(data) {
+		return nil, fmt.Errorf("JSON must be valid UTF-8")
+	}
+
+	// encoding/json silently replaces lone surrogates with U+FFFD
+	err := checkJSONSurrogates(data)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	buf := &bytes.Buffer{}
+
+	err = canonicalizeValue(decoder, buf)
+	if err != nil {
+		return nil, err
+	}
+
+	// nothing but whitespace may follow the value
+	_, err = decoder.Token()
+	if err != io.EOF {
+		return nil, fmt.Errorf("Invalid JSON, unexpected data after top-level value")
+	}
+
+	return buf.Bytes(), nil
+
+}
+
+// MarshalCanonicalJSON returns RFC 8785 canonical JSON encoding of v.
+// Use json.RawMessage to canonicalize JSON text
+func MarshalCanonicalJSON(v interface{}) ([]byte, error) {
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return CanonicalizeJSON(data)
+
+}
+
+// SignJSON signs RFC 8785 canonical JSON encoding of v, so the signature doesn't depend on member order and formatting.
+// Use json.RawMessage to sign JSON text
+func (key *AbstractKey) SignJSON(v interface{}) ([]byte, error) {
+
+	data, err := MarshalCanonicalJSON(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return key.Sign(data)
+
+}
+
+// VerifyJSON verifies signature of RFC 8785 canonical JSON encoding of v created by SignJSON
+func (key *AbstractKey) VerifyJSON(v interface{}, signature []byte) (bool, error) {
+
+	data, err := MarshalCanonicalJSON(v)
+	if err != nil {
+		return false, err
+	}
+
+	return key.Verify(data, signature)
+
+}
+
+// helper function that reads next JSON value from decoder and writes its canonical form into buf
+func canonicalizeValue(decoder *json.Decoder, buf *bytes.Buffer) error {
+
+	token, err := decoder.Token()
+	if err != nil {
+		return fmt.Errorf("Invalid JSON: %v", err)
+	}
+
+	switch v := token.(type) {
+	case json.Delim:
+		switch v {
+		case '{':
+			return canonicalizeObject(decoder, buf)
+		case '[':
+			return canonicalizeArray(decoder, buf)
+		}
+		return fmt.Errorf("Invalid JSON, unexpected %s", v)
+	case string:
+		writeCanonicalString(buf, v)
+	case json.Number:
+		s, err := canonicalNumber(v)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(s)
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+	case nil:
+		buf.WriteString("null")
+	}
+
+	return nil
+
+}
+
+// helper function that canonicalizes JSON object, opening '{' is already read
+func canonicalizeObject(decoder *json.Decoder, buf *bytes.Buffer) error {
+
+	members := make(map[string][]byte)
+	var names []string
+
+	for decoder.More() {
+
+		token, err := decoder.Token()
+		if err != nil {
+			return fmt.Errorf("Invalid JSON: %v", err)
+		}
+
+		name, ok := token.(string)
+		if !ok {
+			return fmt.Errorf("Invalid JSON, object member name must be a string")
+		}
+
+		if _, ok := members[name]; ok {
+			return fmt.Errorf("Invalid JSON, duplicate object member %s", name)
+		}
+
+		value := &bytes.Buffer{}
+		err = canonicalizeValue(decoder, value)
+		if err != nil {
+			return err
+		}
+
+		members[name] = value.Bytes()
+		names = append(names, name)
+
+	}
+
+	// closing '}'
+	_, err := decoder.Token()
+	if err != nil {
+		return fmt.Errorf("Invalid JSON: %v", err)
+	}
+
+	sort.Slice(names, func(i, j int) bool {
+		return lessUTF16(names[i], names[j])
+	})
+
+	buf.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		writeCanonicalString(buf, name)
+		buf.WriteByte(':')
+		buf.Write(members[name])
+	}
+	buf.WriteByte('}')
+
+	return nil
+
+}
+
+// helper function that canonicalizes JSON array, opening '[' is already read
+func canonicalizeArray(decoder *json.Decoder, buf *bytes.Buffer) error {
+
+	buf.WriteByte('[')
+
+	for i := 0; decoder.More(); i++ {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		err := canonicalizeValue(decoder, buf)
+		if err != nil {
+			return err
+		}
+	}
+
+	// closing ']'
+	_, err := decoder.Token()
+	if err != nil {
+		return fmt.Errorf("Invalid JSON: %v", err)
+	}
+
+	buf.WriteByte(']')
+
+	return nil
+
+}
+
+// helper function that serializes JSON string like ECMAScript JSON.stringify
+func writeCanonicalString(buf *bytes.Buffer, s string) {
+
+	buf.WriteByte('"')
+
+	for _, r := range s {
+		switch r {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(buf, `\u%04x`, r)
+			} else {
+				buf.WriteRune(r)
+			}
+		}
+	}
+
+	buf.WriteByte('"')
+
+}
+
+// helper function that checks that escaped UTF-16 surrogates of JSON strings are paired: high surrogate followed by low one.
+// Syntax errors are left to the decoder
+func checkJSONSurrogates(data []byte) error {
+
+	inString := false
+
+	for i := 0; i < len(data); i++ {
+
+		if !inString {
+			inString = data[i] == '"'
+			continue
+		}
+
+		switch data[i] {
+		case '"':
+			inString = false
+		case '\\':
+			r, ok := jsonEscapedRune(data[i:])
+			if !ok {
+				// skip escaped character
+				i++
+				continue
+			}
+			i += 5
+			if !utf16.IsSurrogate(r) {
+				continue
+			}
+			if r >= 0xdc00 {
+				return fmt.Errorf("Invalid JSON, lone UTF-16 surrogate \\u%04x", r)
+			}
+			low, ok := jsonEscapedRune(data[i+1:])
+			if !ok || low < 0xdc00 || low > 0xdfff {
+				return fmt.Errorf("Invalid JSON, lone UTF-16 surrogate \\u%04x", r)
+			}
+			i += 6
+		}
+
+	}
+
+	return nil
+
+}
+
+// helper function that decodes \uXXXX escape at the beginning of b
+func jsonEscapedRune(b []byte) (rune, bool) {
+
+	if len(b) < 6 || b[0] != '\\' || b[1] != 'u' {
+		return 0, false
+	}
+
+	r, err := strconv.ParseUint(string(b[2:6]), 16, 16)
+	if err != nil {
+		return 0, false
+	}
+
+	return rune(r), true
+
+}
+
+// helper function that serializes JSON number like ECMAScript Number.prototype.toString
+func canonicalNumber(n json.Number) (string, error) {
+
+	f, err := strconv.ParseFloat(string(n), 64)
+	if err != nil {
+		return "", fmt.Errorf("Invalid JSON number %s, must fit IEEE 754 double", n)
+	}
+
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "", fmt.Errorf("Invalid JSON number %s", n)
+	}
+
+	// -0 is serialized as 0
+	if f == 0 {
+		return "0", nil
+	}
+
+	abs := math.Abs(f)
+	if abs >= 1e-6 && abs < 1e21 {
+		return strconv.FormatFloat(f, 'f', -1, 64), nil
+	}
+
+	// exponent without leading zeros, e.g. 1e-7 instead of 1e-07
+	s := strconv.FormatFloat(f, 'e', -1, 64)
+	i := len(s) - 2
+	if s[i] == '0' && (s[i-1] == '-' || s[i-1] == '+') {
+		s = s[:i] + s[i+1:]
+	}
+
+	return s, nil
+
+}
+
+// helper function that compares strings by UTF-16 code units
+func lessUTF16(a string, b string) bool {
+
+	ua := utf16.Encode([]rune(a))
+	ub := utf16.Encode([]rune(b))
+
+	for i := 0; i < len(ua) && i < len(ub); i++ {
+		if ua[i] != ub[i] {
+			return ua[i] < ub[i]
+		}
+	}
+
+	return len(ua) < len(ub)
+
+}
@@ -0,0 +1,120 @@
+package factomdid
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanonicalizeJSON(t *testing.T) {
+
+	// RFC 8785 3.2.2
+	input := `{
+		"numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001],
+		"string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+		"literals": [null, true, false]
+	}`
+	output, err := CanonicalizeJSON([]byte(input))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`, string(output))
+
+	// RFC 8785 3.2.3, sorting by UTF-16 code units
+	input = `{
+		"\u20ac": "Euro Sign",
+		"\r": "Carriage Return",
+		"\ufb33": "Hebrew Letter Dalet With Dagesh",
+		"1": "One",
+		"\ud83d\ude00": "Emoji: Grinning Face",
+		"\u0080": "Control",
+		"\u00f6": "Latin Small Letter O With Diaeresis"
+	}`
+	output, err = CanonicalizeJSON([]byte(input))
+	assert.NoError(t, err)
+	assert.Equal(t, "{\"\\r\":\"Carriage Return\",\"1\":\"One\",\"\u0080\":\"Control\",\"\u00f6\":\"Latin Small Letter O With Diaeresis\",\"\u20ac\":\"Euro Sign\",\"\U0001f600\":\"Emoji: Grinning Face\",\"\ufb33\":\"Hebrew Letter Dalet With Dagesh\"}", string(output))
+
+	// escaped surrogate pair
+	output, err = CanonicalizeJSON([]byte(`["\ud83d\ude00","\\ud800"]`))
+	assert.NoError(t, err)
+	assert.Equal(t, "[\"\U0001f600\",\"\\\\ud800\"]", string(output))
+
+	// lone surrogates
+	for _, input := range []string{`"\ud800"`, `"\udc00"`, `"\ud800a"`, `"\ud800\n"`, `"\ud800\u0041"`, `"\ude00\ud83d"`, `{"\ud800":1}`} {
+		_, err = CanonicalizeJSON([]byte(input))
+		assert.Error(t, err, input)
+	}
+
+	// nested structures
+	output, err = CanonicalizeJSON([]byte(` { "b" : [ { "d" : 1 , "c" : [ ] } ] , "a" : { } } `))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"a":{},"b":[{"c":[],"d":1}]}`, string(output))
+
+	// invalid JSON
+	for _, input := range []string{``, `{`, `{"a":1,"a":2}`, `[1,]`, `{} {}`, `1e400`, "\"\xff\""} {
+		_, err = CanonicalizeJSON([]byte(input))
+		assert.Error(t, err, input)
+	}
+
+}
+
+func TestCanonicalNumber(t *testing.T) {
+
+	// RFC 8785 Appendix B
+	vectors := map[uint64]string{
+		0x0000000000000000: "0",
+		0x8000000000000000: "0",
+		0x0000000000000001: "5e-324",
+		0x8000000000000001: "-5e-324",
+		0x7fefffffffffffff: "1.7976931348623157e+308",
+		0xffefffffffffffff: "-1.7976931348623157e+308",
+		0x4340000000000000: "9007199254740992",
+		0xc340000000000000: "-9007199254740992",
+		0x4430000000000000: "295147905179352830000",
+		0x44b52d02c7e14af5: "9.999999999999997e+22",
+		0x44b52d02c7e14af6: "1e+23",
+		0x44b52d02c7e14af7: "1.0000000000000001e+23",
+		0x444b1ae4d6e2ef4e: "999999999999999700000",
+		0x444b1ae4d6e2ef4f: "999999999999999900000",
+		0x444b1ae4d6e2ef50: "1e+21",
+		0x3eb0c6f7a0b5ed8c: "9.999999999999997e-7",
+		0x3eb0c6f7a0b5ed8d: "0.000001",
+		0x41b3de4355555553: "333333333.3333332",
+		0x41b3de4355555554: "333333333.33333325",
+		0x41b3de4355555555: "333333333.3333333",
+		0x41b3de4355555556: "333333333.3333334",
+		0x41b3de4355555557: "333333333.33333343",
+		0xbecbf647612f3696: "-0.0000033333333333333333",
+		0x43143ff3c1cb0959: "1424953923781206.2",
+	}
+
+	for bits, expected := range vectors {
+		n := strconv.FormatFloat(math.Float64frombits(bits), 'g', -1, 64)
+		s, err := canonicalNumber(json.Number(n))
+		assert.NoError(t, err)
+		assert.Equal(t, expected, s, n)
+	}
+
+}
+
+func TestSignJSON(t *testing.T) {
+
+	key := &AbstractKey{KeyType: KeyTypeEdDSA}
+	key.generateRandomKeys()
+
+	signature, err := key.SignJSON(json.RawMessage(`{"b": 2, "a": 1}`))
+	assert.NoError(t, err)
+
+	// member order and formatting don't matter
+	valid, err := key.VerifyJSON(map[string]int{"a": 1, "b": 2}, signature)
+	assert.NoError(t, err)
+	assert.True(t, valid)
+
+	valid, _ = key.VerifyJSON(map[string]int{"a": 1, "b": 3}, signature)
+	assert.False(t, valid)
+
+	_, err = key.SignJSON(json.RawMessage(`{"a": 1, "a": 2}`))
+	assert.Error(t, err)
+
+}
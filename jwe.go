@@ -0,0 +1,251 @@
+package factomdid
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/binary"
+	"fmt"
+)
+
+// JWE is JSON Web Encryption (RFC 7516) in general JSON serialization with one entry per recipient key
+type JWE struct {
+	Protected  string          `json:"protected" form:"protected" query:"protected"`
+	Recipients []*JWERecipient `json:"recipients" form:"recipients" query:"recipients"`
+	IV         string          `json:"iv" form:"iv" query:"iv"`
+	Ciphertext string          `json:"ciphertext" form:"ciphertext" query:"ciphertext"`
+	Tag        string          `json:"tag" form:"tag" query:"tag"`
+}
+
+// JWERecipient is JWE recipient with content encryption key wrapped for recipient key "kid"
+type JWERecipient struct {
+	Header       *JWERecipientHeader `json:"header" form:"header" query:"header"`
+	EncryptedKey string              `json:"encrypted_key" form:"encrypted_key" query:"encrypted_key"`
+}
+
+// JWERecipientHeader is per-recipient unprotected JWE header
+type JWERecipientHeader struct {
+	Kid string `json:"kid" form:"kid" query:"kid"`
+}
+
+// JWEHeader is protected JWE header
+type JWEHeader struct {
+	Typ  string `json:"typ,omitempty" form:"typ" query:"typ"`
+	Alg  string `json:"alg" form:"alg" query:"alg"`
+	Enc  string `json:"enc" form:"enc" query:"enc"`
+	Epk  *JWK   `json:"epk" form:"epk" query:"epk"`
+	Apu  string `json:"apu,omitempty" form:"apu" query:"apu"`
+	Apv  string `json:"apv" form:"apv" query:"apv"`
+	Skid string `json:"skid,omitempty" form:"skid" query:"skid"`
+}
+
+const (
+
+	// JWE algorithms
+
+	// JWEAlgorithmECDHESA256KW is "ECDH-ES+A256KW" key agreement of anonymous encryption (anoncrypt)
+	JWEAlgorithmECDHESA256KW = "ECDH-ES+A256KW"
+	// JWEAlgorithmECDH1PUA256KW is "ECDH-1PU+A256KW" key agreement of authenticated encryption (authcrypt)
+	JWEAlgorithmECDH1PUA256KW = "ECDH-1PU+A256KW"
+	// JWEEncryptionA256CBCHS512 is "A256CBC-HS512" content encryption
+	JWEEncryptionA256CBCHS512 = "A256CBC-HS512"
+
+	// a256cbcHS512KeySize is size of A256CBC-HS512 content encryption key (MAC key || ENC key)
+	a256cbcHS512KeySize = 64
+	// a256kwKeySize is size of A256KW key encryption key
+	a256kwKeySize = 32
+)
+
+// RFC 3394 default initial value
+var keyWrapIV = []byte{0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6}
+
+// helper function that encrypts plaintext with A256CBC-HS512 (RFC 7518 5.2), returns ciphertext and tag
+func encryptA256CBCHS512(cek []byte, iv []byte, plaintext []byte, aad []byte) ([]byte, []byte, error) {
+
+	if len(cek) != a256cbcHS512KeySize || len(iv) != aes.BlockSize {
+		return nil, nil, fmt.Errorf("Invalid %s key or IV size", JWEEncryptionA256CBCHS512)
+	}
+
+	block, err := aes.NewCipher(cek[32:])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// PKCS #7 padding
+	padding := aes.BlockSize - len(plaintext)%aes.BlockSize
+	padded := make([]byte, len(plaintext), len(plaintext)+padding)
+	copy(padded, plaintext)
+	for i := 0; i < padding; i++ {
+		padded = append(padded, byte(padding))
+	}
+
+	ciphertext := make([]byte, len(padded))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(ciphertext, padded)
+
+	return ciphertext, a256cbcHS512Tag(cek[:32], iv, ciphertext, aad), nil
+
+}
+
+// helper function that checks tag and decrypts A256CBC-HS512 ciphertext
+func decryptA256CBCHS512(cek []byte, iv []byte, ciphertext []byte, tag []byte, aad []byte) ([]byte, error) {
+
+	if len(cek) != a256cbcHS512KeySize || len(iv) != aes.BlockSize {
+		return nil, fmt.Errorf("Invalid %s key or IV size", JWEEncryptionA256CBCHS512)
+	}
+
+	if !hmac.Equal(tag, a256cbcHS512Tag(cek[:32], iv, ciphertext, aad)) {
+		return nil, fmt.Errorf("Invalid JWE authentication tag")
+	}
+
+	if len(ciphertext) == 0 || len(ciphertext)%aes.BlockSize != 0 {
+		return nil, fmt.Errorf("Invalid JWE ciphertext size")
+	}
+
+	block, err := aes.NewCipher(cek[32:])
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext := make([]byte, len(ciphertext))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plaintext, ciphertext)
+
+	padding := int(plaintext[len(plaintext)-1])
+	if padding == 0 || padding > aes.BlockSize {
+		return nil, fmt.Errorf("Invalid JWE padding")
+	}
+	for _, b := range plaintext[len(plaintext)-padding:] {
+		if int(b) != padding {
+			return nil, fmt.Errorf("Invalid JWE padding")
+		}
+	}
+
+	return plaintext[:len(plaintext)-padding], nil
+
+}
+
+// helper function that computes A256CBC-HS512 tag: first 32 bytes of HMAC-SHA-512(AAD || IV || ciphertext || AL)
+func a256cbcHS512Tag(macKey []byte, iv []byte, ciphertext []byte, aad []byte) []byte {
+
+	al := make([]byte, 8)
+	binary.BigEndian.PutUint64(al, uint64(len(aad))*8)
+
+	mac := hmac.New(sha512.New, macKey)
+	mac.Write(aad)
+	mac.Write(iv)
+	mac.Write(ciphertext)
+	mac.Write(al)
+
+	return mac.Sum(nil)[:32]
+
+}
+
+// helper function that wraps key with AES key wrap (RFC 3394)
+func wrapKey(kek []byte, key []byte) ([]byte, error) {
+
+	if len(key) < 16 || len(key)%8 != 0 {
+		return nil, fmt.Errorf("Invalid size of key to wrap")
+	}
+
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+
+	n := len(key) / 8
+	a := make([]byte, 8)
+	copy(a, keyWrapIV)
+	r := make([]byte, len(key))
+	copy(r, key)
+
+	b := make([]byte, 16)
+	for j := 0; j < 6; j++ {
+		for i := 0; i < n; i++ {
+			copy(b, a)
+			copy(b[8:], r[i*8:(i+1)*8])
+			block.Encrypt(b, b)
+			t := uint64(n*j + i + 1)
+			binary.BigEndian.PutUint64(a, binary.BigEndian.Uint64(b[:8])^t)
+			copy(r[i*8:], b[8:])
+		}
+	}
+
+	return append(a, r...), nil
+
+}
+
+// helper function that unwraps key with AES key wrap (RFC 3394)
+func unwrapKey(kek []byte, wrapped []byte) ([]byte, error) {
+
+	if len(wrapped) < 24 || len(wrapped)%8 != 0 {
+		return nil, fmt.Errorf("Invalid size of wrapped key")
+	}
+
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+
+	n := len(wrapped)/8 - 1
+	a := make([]byte, 8)
+	copy(a, wrapped[:8])
+	r := make([]byte, n*8)
+	copy(r, wrapped[8:])
+
+	b := make([]byte, 16)
+	for j := 5; j >= 0; j-- {
+		for i := n - 1; i >= 0; i-- {
+			t := uint64(n*j + i + 1)
+			binary.BigEndian.PutUint64(b, binary.BigEndian.Uint64(a)^t)
+			copy(b[8:], r[i*8:(i+1)*8])
+			block.Decrypt(b, b)
+			copy(a, b[:8])
+			copy(r[i*8:], b[8:])
+		}
+	}
+
+	if subtle.ConstantTimeCompare(a, keyWrapIV) != 1 {
+		return nil, fmt.Errorf("Invalid wrapped key")
+	}
+
+	return r, nil
+
+}
+
+// helper function that derives keySize bytes key with Concat KDF (NIST SP 800-56A, RFC 7518 4.6.2).
+// tag is appended to SuppPubInfo for ECDH-1PU key wrapping mode
+func concatKDF(z []byte, alg string, apu []byte, apv []byte, tag []byte, keySize int) []byte {
+
+	lengthPrefixed := func(b []byte) []byte {
+		l := make([]byte, 4)
+		binary.BigEndian.PutUint32(l, uint32(len(b)))
+		return append(l, b...)
+	}
+
+	keyDataLen := make([]byte, 4)
+	binary.BigEndian.PutUint32(keyDataLen, uint32(keySize)*8)
+
+	otherInfo := lengthPrefixed([]byte(alg))
+	otherInfo = append(otherInfo, lengthPrefixed(apu)...)
+	otherInfo = append(otherInfo, lengthPrefixed(apv)...)
+	otherInfo = append(otherInfo, keyDataLen...)
+	if tag != nil {
+		otherInfo = append(otherInfo, lengthPrefixed(tag)...)
+	}
+
+	var key []byte
+	counter := make([]byte, 4)
+	for i := uint32(1); len(key) < keySize; i++ {
+		binary.BigEndian.PutUint32(counter, i)
+		h := sha256.New()
+		h.Write(counter)
+		h.Write(z)
+		h.Write(otherInfo)
+		key = h.Sum(key)
+	}
+
+	return key[:keySize]
+
+}
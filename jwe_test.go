@@ -0,0 +1,111 @@
+package factomdid
+
+import (
+	"crypto/ecdh"
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyWrap(t *testing.T) {
+
+	// RFC 3394 4.6, wrap 256 bits of key data with a 256-bit KEK
+	kek, _ := hex.DecodeString("000102030405060708090A0B0C0D0E0F101112131415161718191A1B1C1D1E1F")
+	key, _ := hex.DecodeString("00112233445566778899AABBCCDDEEFF000102030405060708090A0B0C0D0E0F")
+	expected, _ := hex.DecodeString("28C9F404C4B810F4CBCCB35CFB87F8263F5786E2D80ED326CBC7F0E71A99F43BFB988B9B7A02DD21")
+
+	wrapped, err := wrapKey(kek, key)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, wrapped)
+
+	unwrapped, err := unwrapKey(kek, wrapped)
+	assert.NoError(t, err)
+	assert.Equal(t, key, unwrapped)
+
+	// integrity check fails
+	wrapped[0] ^= 1
+	_, err = unwrapKey(kek, wrapped)
+	assert.Error(t, err)
+
+}
+
+func TestA256CBCHS512(t *testing.T) {
+
+	cek := make([]byte, a256cbcHS512KeySize)
+	iv := make([]byte, 16)
+	aad := []byte("aad")
+
+	for _, plaintext := range [][]byte{{}, []byte("message"), make([]byte, 32)} {
+
+		ciphertext, tag, err := encryptA256CBCHS512(cek, iv, plaintext, aad)
+		assert.NoError(t, err)
+
+		decrypted, err := decryptA256CBCHS512(cek, iv, ciphertext, tag, aad)
+		assert.NoError(t, err)
+		assert.Equal(t, plaintext, decrypted)
+
+		_, err = decryptA256CBCHS512(cek, iv, ciphertext, tag, []byte("another aad"))
+		assert.Error(t, err)
+
+	}
+
+}
+
+func TestA256CBCHS512KnownAnswer(t *testing.T) {
+
+	// RFC 7518 B.3, AES_256_CBC_HMAC_SHA_512
+	cek, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f" +
+		"202122232425262728292a2b2c2d2e2f303132333435363738393a3b3c3d3e3f")
+	iv, _ := hex.DecodeString("1af38c2dc2b96ffdd86694092341bc04")
+	aad := []byte("The second principle of Auguste Kerckhoffs")
+	plaintext := []byte("A cipher system must not be required to be secret, and it must be able to fall into the hands of the enemy without inconvenience")
+	expectedCiphertext, _ := hex.DecodeString("4affaaadb78c31c5da4b1b590d10ffbd3dd8d5d3024235" +
+		"26912da037ecbcc7bd822c301dd67c373bccb584ad3e9279c2e6d12a1374b77f077553df829410446b36ebd97066296ae6427ea75c2e0846a11a09ccf5370dc80bfecbad28c73f09b3a3b75e662a2594410ae496b2e2e6609e31e6e02cc837f053d21f37ff4f51950bbe2638d09dd7a4930930806d0703b1f6")
+	expectedTag, _ := hex.DecodeString("4dd3b4c088a7f45c216839645b2012bf2e6269a8c56a816dbc1b267761955bc5")
+
+	ciphertext, tag, err := encryptA256CBCHS512(cek, iv, plaintext, aad)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedCiphertext, ciphertext)
+	assert.Equal(t, expectedTag, tag)
+
+	decrypted, err := decryptA256CBCHS512(cek, iv, expectedCiphertext, expectedTag, aad)
+	assert.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+
+}
+
+func TestConcatKDFKnownAnswer(t *testing.T) {
+
+	decode := func(s string) []byte {
+		b, err := base64.RawURLEncoding.DecodeString(s)
+		assert.NoError(t, err)
+		return b
+	}
+
+	// RFC 7518 Appendix C, ECDH-ES with P-256 keys of Alice (ephemeral) and Bob
+	alice, err := ecdh.P256().NewPrivateKey(decode("0_NxaRPUMQoAJt50Gz8YiTr8gRTwyEaCumd-MToTmIo"))
+	assert.NoError(t, err)
+	bob, err := ecdh.P256().NewPrivateKey(decode("VEmDZpDXXK8p8N0Cndsxs924q6nS1RXFASRl6BfUqdw"))
+	assert.NoError(t, err)
+
+	ze, err := alice.ECDH(bob.PublicKey())
+	assert.NoError(t, err)
+	assert.Equal(t, "9e56d91d817135d372834283bf84269cfb316ea3da806a48f6daa7798cfe90c4", hex.EncodeToString(ze))
+
+	key := concatKDF(ze, "A128GCM", []byte("Alice"), []byte("Bob"), nil, 16)
+	assert.Equal(t, "VqqN6vgjbSBcIijNcacQGg", base64.RawURLEncoding.EncodeToString(key))
+
+	// draft-madden-jose-ecdh-1pu-04 Appendix A, ECDH-1PU with Alice's static key, Z = Ze || Zs
+	aliceStatic, err := ecdh.P256().NewPrivateKey(decode("Hndv7ZZjs_ke8o9zXYo3iq-Yr8SewI5vrqd0pAvEPqg"))
+	assert.NoError(t, err)
+
+	zs, err := aliceStatic.ECDH(bob.PublicKey())
+	assert.NoError(t, err)
+	assert.Equal(t, "e3ca3474384c9f62b30bfd4c688b3e7d4110a1b4badc3cc54ef7b81241efd50d", hex.EncodeToString(zs))
+
+	key = concatKDF(append(ze, zs...), "A256GCM", []byte("Alice"), []byte("Bob"), nil, 32)
+	assert.Equal(t, "6caf13723d14850ad4b42cd6dde935bffd2fff00a9ba70de05c203a5e1722ca7", hex.EncodeToString(key))
+
+}
@@ -0,0 +1,191 @@
+package factomdid
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// JWK is a public JSON Web Key (RFC 7517), used in W3C DID documents as publicKeyJwk
+type JWK struct {
+	Kty string `json:"kty" form:"kty" query:"kty"`
+	Crv string `json:"crv,omitempty" form:"crv" query:"crv"`
+	X   string `json:"x,omitempty" form:"x" query:"x"`
+	Y   string `json:"y,omitempty" form:"y" query:"y"`
+	N   string `json:"n,omitempty" form:"n" query:"n"`
+	E   string `json:"e,omitempty" form:"e" query:"e"`
+	Kid string `json:"kid,omitempty" form:"kid" query:"kid"`
+}
+
+const (
+
+	// JWK key types
+
+	// JWKKeyTypeEC is "EC" JWK key type
+	JWKKeyTypeEC = "EC"
+	// JWKKeyTypeOKP is "OKP" JWK key type
+	JWKKeyTypeOKP = "OKP"
+	// JWKKeyTypeRSA is "RSA" JWK key type
+	JWKKeyTypeRSA = "RSA"
+
+	// JWK curves
+
+	// JWKCurveP256 is "P-256" JWK curve
+	JWKCurveP256 = "P-256"
+	// JWKCurveSecp256k1 is "secp256k1" JWK curve
+	JWKCurveSecp256k1 = "secp256k1"
+	// JWKCurveEd25519 is "Ed25519" JWK curve
+	JWKCurveEd25519 = "Ed25519"
+	// JWKCurveX25519 is "X25519" JWK curve
+	JWKCurveX25519 = "X25519"
+)
+
+// ToJWK exports public key of AbstractKey as JWK
+func (key *AbstractKey) ToJWK() (*JWK, error) {
+
+	err := validate.StructPartial(key, "PublicKey", "KeyType")
+	if err != nil {
+		return nil, err
+	}
+
+	suite, err := GetKeySuite(key.KeyType)
+	if err != nil {
+		return nil, err
+	}
+
+	return suite.ToJWK(key.PublicKey)
+
+}
+
+// FromJWK imports public key from JWK into AbstractKey.
+// KeyType is inferred from JWK kty and crv, unless AbstractKey.KeyType is already set (e.g. to a custom key type).
+// PrivateKey is reset, as JWK holds public key only
+func (key *AbstractKey) FromJWK(jwk *JWK) (*AbstractKey, error) {
+
+	if jwk == nil {
+		return nil, fmt.Errorf("JWK is empty")
+	}
+
+	keyType := key.KeyType
+	if keyType == "" {
+		keyType = jwkKeyType(jwk)
+	}
+
+	suite, err := GetKeySuite(keyType)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, err := suite.FromJWK(jwk)
+	if err != nil {
+		return nil, err
+	}
+
+	key.KeyType = keyType
+	key.PublicKey = publicKey
+	key.PrivateKey = nil
+
+	return key, nil
+
+}
+
+// Thumbprint returns RFC 7638 JWK thumbprint (base64url SHA-256) of the public key, a stable key fingerprint
+func (key *AbstractKey) Thumbprint() (string, error) {
+
+	jwk, err := key.ToJWK()
+	if err != nil {
+		return "", err
+	}
+
+	return jwk.Thumbprint()
+
+}
+
+// Thumbprint returns RFC 7638 JWK thumbprint (base64url SHA-256)
+func (jwk *JWK) Thumbprint() (string, error) {
+
+	var members []string
+
+	// required members only, in lexicographic order
+	switch jwk.Kty {
+	case JWKKeyTypeEC:
+		members = []string{"crv", jwk.Crv, "kty", jwk.Kty, "x", jwk.X, "y", jwk.Y}
+	case JWKKeyTypeOKP:
+		members = []string{"crv", jwk.Crv, "kty", jwk.Kty, "x", jwk.X}
+	case JWKKeyTypeRSA:
+		members = []string{"e", jwk.E, "kty", jwk.Kty, "n", jwk.N}
+	default:
+		return "", fmt.Errorf("Unsupported JWK kty %s", jwk.Kty)
+	}
+
+	var b strings.Builder
+	b.WriteString("{")
+	for i := 0; i < len(members); i += 2 {
+		if members[i+1] == "" {
+			return "", fmt.Errorf("JWK member %s is required", members[i])
+		}
+		if i > 0 {
+			b.WriteString(",")
+		}
+		// members are base64url strings and names, so no JSON escaping is needed
+		b.WriteString(`"` + members[i] + `":"` + members[i+1] + `"`)
+	}
+	b.WriteString("}")
+
+	hashed := sha256.Sum256([]byte(b.String()))
+
+	return base64.RawURLEncoding.EncodeToString(hashed[:]), nil
+
+}
+
+// helper function that decodes EC JWK coordinates
+func decodeJWKCoordinates(jwk *JWK, crv string) (*big.Int, *big.Int, error) {
+
+	if jwk.Kty != JWKKeyTypeEC || jwk.Crv != crv {
+		return nil, nil, fmt.Errorf("JWK must be %s %s key", JWKKeyTypeEC, crv)
+	}
+
+	x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	y, err := base64.RawURLEncoding.DecodeString(jwk.Y)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(x) != 32 || len(y) != 32 {
+		return nil, nil, fmt.Errorf("JWK coordinates must be 32 bytes")
+	}
+
+	return new(big.Int).SetBytes(x), new(big.Int).SetBytes(y), nil
+
+}
+
+// helper function that decodes OKP JWK public key
+func decodeJWKOctetKey(jwk *JWK, crv string, size int) ([]byte, error) {
+
+	if jwk.Kty != JWKKeyTypeOKP || jwk.Crv != crv {
+		return nil, fmt.Errorf("JWK must be %s %s key", JWKKeyTypeOKP, crv)
+	}
+
+	x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(x) != size {
+		return nil, fmt.Errorf("JWK x must be %d bytes", size)
+	}
+
+	return x, nil
+
+}
+
+// helper function that encodes 32 bytes EC coordinate as base64url
+func encodeJWKCoordinate(i *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(i.FillBytes(make([]byte, 32)))
+}
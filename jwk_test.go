@@ -0,0 +1,122 @@
+package factomdid
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToJWK(t *testing.T) {
+
+	ecdsa, _ := NewDIDKey("ecdsa", KeyTypeECDSA)
+	jwk, err := ecdsa.ToJWK()
+	assert.NoError(t, err)
+	assert.Equal(t, JWKKeyTypeEC, jwk.Kty)
+	assert.Equal(t, JWKCurveSecp256k1, jwk.Crv)
+	assert.Equal(t, 43, len(jwk.X))
+	assert.Equal(t, 43, len(jwk.Y))
+
+	p256, _ := NewDIDKey("p256", KeyTypeECDSAP256)
+	jwk, err = p256.ToJWK()
+	assert.NoError(t, err)
+	assert.Equal(t, JWKKeyTypeEC, jwk.Kty)
+	assert.Equal(t, JWKCurveP256, jwk.Crv)
+	assert.Equal(t, 43, len(jwk.X))
+	assert.Equal(t, 43, len(jwk.Y))
+
+	eddsa, _ := NewDIDKey("eddsa", KeyTypeEdDSA)
+	jwk, err = eddsa.ToJWK()
+	assert.NoError(t, err)
+	assert.Equal(t, JWKKeyTypeOKP, jwk.Kty)
+	assert.Equal(t, JWKCurveEd25519, jwk.Crv)
+	assert.Empty(t, jwk.Y)
+
+	rsa, _ := NewDIDKey("rsa", KeyTypeRSA)
+	jwk, err = rsa.ToJWK()
+	assert.NoError(t, err)
+	assert.Equal(t, JWKKeyTypeRSA, jwk.Kty)
+	assert.Equal(t, "AQAB", jwk.E)
+	assert.NotEmpty(t, jwk.N)
+
+	// invalid public key
+	invalid := &AbstractKey{KeyType: KeyTypeECDSAP256, PublicKey: []byte("invalid")}
+	jwk, err = invalid.ToJWK()
+	assert.Nil(t, jwk)
+	assert.Error(t, err)
+
+}
+
+func TestFromJWK(t *testing.T) {
+
+	for _, keyType := range []string{KeyTypeECDSA, KeyTypeECDSAP256, KeyTypeEdDSA, KeyTypeRSA, KeyTypeX25519} {
+
+		key := &AbstractKey{KeyType: keyType}
+		key.generateRandomKeys()
+
+		jwk, err := key.ToJWK()
+		assert.NoError(t, err)
+
+		// key type is inferred from JWK
+		imported, err := (&AbstractKey{}).FromJWK(jwk)
+		assert.NoError(t, err)
+		assert.Equal(t, keyType, imported.KeyType)
+		assert.Equal(t, key.PublicKey, imported.PublicKey)
+		assert.Empty(t, imported.PrivateKey)
+
+	}
+
+	// JWK doesn't match key type
+	eddsa, _ := NewDIDKey("eddsa", KeyTypeEdDSA)
+	jwk, _ := eddsa.ToJWK()
+	imported, err := (&AbstractKey{KeyType: KeyTypeECDSA}).FromJWK(jwk)
+	assert.Nil(t, imported)
+	assert.Error(t, err)
+
+	// point is not on curve
+	p256, _ := NewDIDKey("p256", KeyTypeECDSAP256)
+	jwk, _ = p256.ToJWK()
+	jwk.Y = jwk.X
+	imported, err = (&AbstractKey{}).FromJWK(jwk)
+	assert.Nil(t, imported)
+	assert.Error(t, err)
+
+	// unknown kty
+	imported, err = (&AbstractKey{}).FromJWK(&JWK{Kty: "oct"})
+	assert.Nil(t, imported)
+	assert.Error(t, err)
+
+}
+
+func TestThumbprint(t *testing.T) {
+
+	// RFC 7638 3.1 example
+	jwk := &JWK{
+		Kty: JWKKeyTypeRSA,
+		N:   "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw",
+		E:   "AQAB",
+		Kid: "2011-04-29",
+	}
+
+	thumbprint, err := jwk.Thumbprint()
+	assert.NoError(t, err)
+	assert.Equal(t, "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs", thumbprint)
+
+	// imported RSA key has the same thumbprint
+	key, err := (&AbstractKey{}).FromJWK(jwk)
+	assert.NoError(t, err)
+	thumbprint, err = key.Thumbprint()
+	assert.NoError(t, err)
+	assert.Equal(t, "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs", thumbprint)
+
+	// thumbprint is stable for the same key
+	eddsa, _ := NewDIDKey("eddsa", KeyTypeEdDSA)
+	t1, err := eddsa.Thumbprint()
+	assert.NoError(t, err)
+	t2, _ := eddsa.Public().Thumbprint()
+	assert.Equal(t, t1, t2)
+
+	// required member is missing
+	_, err = (&JWK{Kty: JWKKeyTypeOKP, Crv: JWKCurveEd25519}).Thumbprint()
+	assert.Error(t, err)
+
+}
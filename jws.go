@@ -0,0 +1,468 @@
+package factomdid
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// JWSHeader is protected header of compact JWS signed by DIDKey
+type JWSHeader struct {
+	Alg  string   `json:"alg" form:"alg" query:"alg"`
+	Kid  string   `json:"kid" form:"kid" query:"kid"`
+	Typ  string   `json:"typ,omitempty" form:"typ" query:"typ"`
+	Crit []string `json:"crit,omitempty" form:"crit" query:"crit"`
+}
+
+// JWTClaims describes registered JWT claims (RFC 7519).
+// Embed it into custom claims struct passed to SignJWT and VerifyJWT
+type JWTClaims struct {
+	Issuer    string      `json:"iss,omitempty" form:"iss" query:"iss"`
+	Subject   string      `json:"sub,omitempty" form:"sub" query:"sub"`
+	Audience  JWTAudience `json:"aud,omitempty" form:"aud" query:"aud"`
+	ExpiresAt int64       `json:"exp,omitempty" form:"exp" query:"exp"`
+	NotBefore int64       `json:"nbf,omitempty" form:"nbf" query:"nbf"`
+	IssuedAt  int64       `json:"iat,omitempty" form:"iat" query:"iat"`
+	ID        string      `json:"jti,omitempty" form:"jti" query:"jti"`
+}
+
+// JWTAudience is "aud" JWT claim, which is either a single string or an array of strings
+type JWTAudience []string
+
+// JWTOption configures VerifyJWT
+type JWTOption func(*jwtOptions)
+
+type jwtOptions struct {
+	audience string
+	leeway   time.Duration
+}
+
+const (
+
+	// JWS algorithms
+
+	// JWSAlgorithmEdDSA is "EdDSA" JWS algorithm of Ed25519 keys
+	JWSAlgorithmEdDSA = "EdDSA"
+	// JWSAlgorithmES256K is "ES256K" JWS algorithm of ECDSA secp256k1 keys
+	JWSAlgorithmES256K = "ES256K"
+	// JWSAlgorithmES256 is "ES256" JWS algorithm of ECDSA P-256 keys
+	JWSAlgorithmES256 = "ES256"
+	// JWSAlgorithmRS256 is "RS256" JWS algorithm of RSA keys (PKCS #1 v1.5)
+	JWSAlgorithmRS256 = "RS256"
+	// JWSAlgorithmPS256 is "PS256" JWS algorithm of RSA keys (RSASSA-PSS)
+	JWSAlgorithmPS256 = "PS256"
+
+	// JWTType is "typ" header of JWT
+	JWTType = "JWT"
+)
+
+// WithAudience makes VerifyJWT require "aud" claim to contain audience
+func WithAudience(audience string) JWTOption {
+	return func(o *jwtOptions) {
+		o.audience = audience
+	}
+}
+
+// WithLeeway allows clock skew when VerifyJWT checks "exp" and "nbf" claims
+func WithLeeway(leeway time.Duration) JWTOption {
+	return func(o *jwtOptions) {
+		o.leeway = leeway
+	}
+}
+
+// KeyID returns DID URL of DIDKey "did:factom:<chainID>#alias", used as JWS "kid".
+// DIDKey must be added to DID document (see DID.AddDIDKey), so its Controller is set
+func (didkey *DIDKey) KeyID() (string, error) {
+
+	err := validate.StructPartial(didkey, "AbstractKey.Alias")
+	if err != nil {
+		return "", err
+	}
+
+	if didkey.Controller == "" {
+		return "", fmt.Errorf("DIDKey %s has no controller, add it to DID document first", didkey.Alias)
+	}
+
+	return strings.Join([]string{didkey.Controller, didkey.Alias}, "#"), nil
+
+}
+
+// JWSAlgorithm returns JWS "alg" of the key, defined by its KeySuite.
+// RSA keys sign with PS256 if RSAPSS is set and with RS256 otherwise
+func (key *AbstractKey) JWSAlgorithm() (string, error) {
+
+	suite, err := GetKeySuite(key.KeyType)
+	if err != nil {
+		return "", err
+	}
+
+	algs := suite.JWSAlgorithms()
+	if len(algs) == 0 {
+		return "", fmt.Errorf("%s keys can't sign JWS", key.KeyType)
+	}
+
+	if key.RSAPSS {
+		if !hasJWSAlgorithm(suite, JWSAlgorithmPS256) {
+			return "", fmt.Errorf("RSASSA-PSS requires %s", KeyTypeRSA)
+		}
+		return JWSAlgorithmPS256, nil
+	}
+
+	return algs[0], nil
+
+}
+
+// SignJWS signs payload with DIDKey and returns compact JWS with "kid" set to DID URL of the key
+func (didkey *DIDKey) SignJWS(payload []byte) (string, error) {
+	return didkey.signJWS("", payload)
+}
+
+// SignJWT signs JWT claims (struct or map marshalled into JSON) with DIDKey.
+// "kid" header is set to DID URL of the key
+func (didkey *DIDKey) SignJWT(claims interface{}) (string, error) {
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	return didkey.signJWS(JWTType, payload)
+
+}
+
+// VerifyJWS verifies compact JWS, "kid" is resolved into DIDKey using resolver (or already resolved *DID).
+// The key must have the purpose, e.g. KeyPurposeAuthentication for API authentication.
+// Returns payload and the key which signed JWS
+func VerifyJWS(token string, resolver Resolver, purpose string) ([]byte, *DIDKey, error) {
+
+	header, payload, err := verifyJWS(token, resolver, purpose)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if header.Typ == JWTType {
+		return nil, nil, fmt.Errorf("JWT must be verified with VerifyJWT")
+	}
+
+	return payload.data, payload.key, nil
+
+}
+
+// VerifyJWT verifies JWT like VerifyJWS and unmarshals its payload into claims (if claims is not nil).
+// Registered claims are checked: "exp" and "nbf" against current time, "iss" (if set) must be DID of the signing key,
+// "aud" must contain audience if WithAudience option is used
+func VerifyJWT(token string, resolver Resolver, purpose string, claims interface{}, opts ...JWTOption) (*DIDKey, error) {
+
+	o := &jwtOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	header, payload, err := verifyJWS(token, resolver, purpose)
+	if err != nil {
+		return nil, err
+	}
+
+	if header.Typ != "" && header.Typ != JWTType {
+		return nil, fmt.Errorf("Invalid JWT typ %s", header.Typ)
+	}
+
+	registered := &JWTClaims{}
+	err = json.Unmarshal(payload.data, registered)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	if registered.ExpiresAt != 0 && now.Add(-o.leeway).Unix() >= registered.ExpiresAt {
+		return nil, fmt.Errorf("JWT is expired")
+	}
+
+	if registered.NotBefore != 0 && now.Add(o.leeway).Unix() < registered.NotBefore {
+		return nil, fmt.Errorf("JWT is not valid yet")
+	}
+
+	if registered.Issuer != "" {
+		_, err = payload.did.Resolve(registered.Issuer)
+		if err != nil {
+			return nil, fmt.Errorf("JWT issuer %s is not DID of signing key %s", registered.Issuer, header.Kid)
+		}
+	}
+
+	if o.audience != "" && !registered.Audience.Contains(o.audience) {
+		return nil, fmt.Errorf("JWT audience doesn't contain %s", o.audience)
+	}
+
+	if claims != nil {
+		err = json.Unmarshal(payload.data, claims)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return payload.key, nil
+
+}
+
+// Contains returns true if audience contains aud
+func (audience JWTAudience) Contains(aud string) bool {
+
+	for i := range audience {
+		if audience[i] == aud {
+			return true
+		}
+	}
+
+	return false
+
+}
+
+// MarshalJSON encodes single audience as string and multiple audiences as array
+func (audience JWTAudience) MarshalJSON() ([]byte, error) {
+
+	if len(audience) == 1 {
+		return json.Marshal(audience[0])
+	}
+
+	return json.Marshal([]string(audience))
+
+}
+
+// UnmarshalJSON decodes audience from string or array of strings
+func (audience *JWTAudience) UnmarshalJSON(data []byte) error {
+
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*audience = JWTAudience{s}
+		return nil
+	}
+
+	var a []string
+	if err := json.Unmarshal(data, &a); err != nil {
+		return fmt.Errorf("JWT aud must be a string or an array of strings")
+	}
+
+	*audience = JWTAudience(a)
+
+	return nil
+
+}
+
+// verified JWS payload with the key and DID document it was resolved from
+type jwsPayload struct {
+	data []byte
+	key  *DIDKey
+	did  *DID
+}
+
+// helper function that signs compact JWS
+func (didkey *DIDKey) signJWS(typ string, payload []byte) (string, error) {
+
+	kid, err := didkey.KeyID()
+	if err != nil {
+		return "", err
+	}
+
+	alg, err := didkey.JWSAlgorithm()
+	if err != nil {
+		return "", err
+	}
+
+	header, err := json.Marshal(&JWSHeader{Alg: alg, Kid: kid, Typ: typ})
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	var signature []byte
+
+	switch alg {
+	case JWSAlgorithmEdDSA:
+		signature, err = didkey.SignWithMode([]byte(signingInput), SignatureModePureEd25519)
+	case JWSAlgorithmES256K, JWSAlgorithmES256:
+		signature, err = didkey.SignWithMode([]byte(signingInput), SignatureModePrehashed)
+		if err == nil {
+			signature, err = derToJWSSignature(signature)
+		}
+	default:
+		signature, err = didkey.SignWithMode([]byte(signingInput), SignatureModePrehashed)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+
+}
+
+// helper function that parses compact JWS, resolves its key and verifies signature
+func verifyJWS(token string, resolver Resolver, purpose string) (*JWSHeader, *jwsPayload, error) {
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, nil, fmt.Errorf("Invalid compact JWS, must have 3 parts")
+	}
+
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, nil, fmt.Errorf("Invalid JWS header: %v", err)
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, nil, fmt.Errorf("Invalid JWS payload: %v", err)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, nil, fmt.Errorf("Invalid JWS signature: %v", err)
+	}
+
+	header := &JWSHeader{}
+	err = json.Unmarshal(headerJSON, header)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Invalid JWS header: %v", err)
+	}
+
+	if len(header.Crit) > 0 {
+		return nil, nil, fmt.Errorf("Unsupported JWS critical headers %v", header.Crit)
+	}
+
+	if header.Kid == "" {
+		return nil, nil, fmt.Errorf("JWS kid is empty")
+	}
+
+	if resolver == nil {
+		return nil, nil, fmt.Errorf("Resolver is empty")
+	}
+
+	id, _, err := SplitDIDURL(header.Kid)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	did, err := resolver.Resolve(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if did == nil {
+		return nil, nil, fmt.Errorf("DID %s not found", id)
+	}
+
+	key, err := ResolveDIDKey(did, header.Kid, purpose)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	valid, err := verifyJWSSignature(&key.AbstractKey, header.Alg, []byte(parts[0]+"."+parts[1]), signature)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !valid {
+		return nil, nil, fmt.Errorf("Invalid JWS signature")
+	}
+
+	return header, &jwsPayload{data: payload, key: key, did: did}, nil
+
+}
+
+// helper function that unmarshals JWT claims without verification,
+// used only to pick parameters of verification, e.g. resolution time
+func unmarshalUnverifiedJWT(token string, claims interface{}) error {
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return fmt.Errorf("Invalid compact JWS, must have 3 parts")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return fmt.Errorf("Invalid JWS payload: %v", err)
+	}
+
+	return json.Unmarshal(payload, claims)
+
+}
+
+// helper function that verifies JWS signature, alg must be accepted by KeySuite of the key
+func verifyJWSSignature(key *AbstractKey, alg string, signingInput []byte, signature []byte) (bool, error) {
+
+	suite, err := GetKeySuite(key.KeyType)
+	if err != nil {
+		return false, err
+	}
+
+	if !hasJWSAlgorithm(suite, alg) {
+		return false, fmt.Errorf("JWS alg %s can't be used with %s key", alg, key.KeyType)
+	}
+
+	hashed := sha256.Sum256(signingInput)
+
+	switch alg {
+	case JWSAlgorithmEdDSA:
+		return key.VerifyWithMode(signingInput, signature, SignatureModePureEd25519)
+	case JWSAlgorithmES256K, JWSAlgorithmES256:
+		der, err := jwsSignatureToDER(signature)
+		if err != nil {
+			return false, err
+		}
+		return key.VerifyWithMode(hashed[:], der, SignatureModeDigest)
+	case JWSAlgorithmRS256:
+		p, err := parseRSAPublicKey(key.PublicKey)
+		if err != nil {
+			return false, err
+		}
+		err = rsa.VerifyPKCS1v15(p, crypto.SHA256, hashed[:], signature)
+		if err != nil {
+			return false, err
+		}
+		return true, nil
+	case JWSAlgorithmPS256:
+		return suite.Verify(key.PublicKey, hashed[:], signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: crypto.SHA256})
+	}
+
+	// algorithms of custom key suites
+	return suite.Verify(key.PublicKey, hashed[:], signature, crypto.SHA256)
+
+}
+
+// helper function that converts DER ECDSA signature into JWS R || S signature
+func derToJWSSignature(der []byte) ([]byte, error) {
+
+	sig := &ecdsaSignature{}
+	_, err := asn1.Unmarshal(der, sig)
+	if err != nil {
+		return nil, err
+	}
+
+	signature := make([]byte, 64)
+	sig.R.FillBytes(signature[:32])
+	sig.S.FillBytes(signature[32:])
+
+	return signature, nil
+
+}
+
+// helper function that converts JWS R || S signature into DER ECDSA signature
+func jwsSignatureToDER(signature []byte) ([]byte, error) {
+
+	if len(signature) != 64 {
+		return nil, fmt.Errorf("Invalid JWS ECDSA signature length %d, must be 64", len(signature))
+	}
+
+	sig := ecdsaSignature{R: new(big.Int).SetBytes(signature[:32]), S: new(big.Int).SetBytes(signature[32:])}
+
+	return asn1.Marshal(sig)
+
+}
@@ -0,0 +1,156 @@
+package factomdid
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJWS(t *testing.T) {
+
+	did := NewDID()
+	ecdsa, _ := NewDIDKey("ecdsa", KeyTypeECDSA)
+	p256, _ := NewDIDKey("p256", KeyTypeECDSAP256)
+	eddsa, _ := NewDIDKey("eddsa", KeyTypeEdDSA)
+	rsa, _ := NewDIDKey("rsa", KeyTypeRSA)
+	rsaPSS, _ := NewDIDKey("rsa-pss", KeyTypeRSA, WithRSAPSS())
+	algs := map[*DIDKey]string{ecdsa: JWSAlgorithmES256K, p256: JWSAlgorithmES256, eddsa: JWSAlgorithmEdDSA, rsa: JWSAlgorithmRS256, rsaPSS: JWSAlgorithmPS256}
+
+	for key := range algs {
+		key.AddPurpose(KeyPurposeAuthentication)
+		did.AddDIDKey(key)
+	}
+
+	payload := []byte("payload")
+
+	for key, alg := range algs {
+
+		token, err := key.SignJWS(payload)
+		assert.NoError(t, err)
+
+		header := &JWSHeader{}
+		headerJSON, _ := base64.RawURLEncoding.DecodeString(strings.Split(token, ".")[0])
+		json.Unmarshal(headerJSON, header)
+		assert.Equal(t, alg, header.Alg)
+		assert.Equal(t, did.ID+"#"+key.Alias, header.Kid)
+
+		// verify against public-only DID document
+		data, signer, err := VerifyJWS(token, did.Public(), KeyPurposeAuthentication)
+		assert.NoError(t, err, alg)
+		assert.Equal(t, payload, data)
+		assert.Equal(t, key.Alias, signer.Alias)
+
+		// wrong purpose
+		data, signer, err = VerifyJWS(token, did, KeyPurposePublic)
+		assert.Nil(t, data)
+		assert.Nil(t, signer)
+		assert.Error(t, err)
+
+		// tampered payload
+		parts := strings.Split(token, ".")
+		parts[1] = base64.RawURLEncoding.EncodeToString([]byte("tampered"))
+		_, _, err = VerifyJWS(strings.Join(parts, "."), did, KeyPurposeAuthentication)
+		assert.Error(t, err, alg)
+
+	}
+
+	// alg must match the key type
+	token, _ := eddsa.SignJWS(payload)
+	parts := strings.Split(token, ".")
+	header, _ := json.Marshal(&JWSHeader{Alg: JWSAlgorithmES256K, Kid: did.ID + "#eddsa"})
+	parts[0] = base64.RawURLEncoding.EncodeToString(header)
+	_, _, err := VerifyJWS(strings.Join(parts, "."), did, KeyPurposeAuthentication)
+	assert.Error(t, err)
+
+	// unknown DID
+	_, _, err = VerifyJWS(token, NewDID(), KeyPurposeAuthentication)
+	assert.Error(t, err)
+
+	// DIDKey not added to DID document
+	key, _ := NewDIDKey("key", KeyTypeEdDSA)
+	_, err = key.SignJWS(payload)
+	assert.Error(t, err)
+
+	// key agreement keys can't sign
+	kaKey, _ := eddsa.DeriveKeyAgreementKey("ka-key")
+	did.AddDIDKey(kaKey)
+	_, err = kaKey.SignJWS(payload)
+	assert.Error(t, err)
+
+	// malformed tokens
+	for _, token := range []string{"", "a.b", "a.b.c", token + "."} {
+		_, _, err = VerifyJWS(token, did, KeyPurposeAuthentication)
+		assert.Error(t, err)
+	}
+
+}
+
+type testClaims struct {
+	JWTClaims
+	Name string `json:"name"`
+}
+
+func TestJWT(t *testing.T) {
+
+	did := NewDID()
+	key, _ := NewDIDKey("auth-key", KeyTypeEdDSA)
+	key.AddPurpose(KeyPurposeAuthentication)
+	did.AddDIDKey(key)
+
+	now := time.Now().Unix()
+	claims := &testClaims{Name: "Alice"}
+	claims.Issuer = did.ID
+	claims.Audience = JWTAudience{"https://api.example.com"}
+	claims.IssuedAt = now
+	claims.ExpiresAt = now + 60
+
+	token, err := key.SignJWT(claims)
+	assert.NoError(t, err)
+
+	verified := &testClaims{}
+	signer, err := VerifyJWT(token, did, KeyPurposeAuthentication, verified, WithAudience("https://api.example.com"))
+	assert.NoError(t, err)
+	assert.Equal(t, key.Alias, signer.Alias)
+	assert.Equal(t, claims, verified)
+
+	// JWT is not a plain JWS
+	_, _, err = VerifyJWS(token, did, KeyPurposeAuthentication)
+	assert.Error(t, err)
+
+	// wrong audience
+	_, err = VerifyJWT(token, did, KeyPurposeAuthentication, nil, WithAudience("https://other.example.com"))
+	assert.Error(t, err)
+
+	// expired
+	claims.ExpiresAt = now - 10
+	token, _ = key.SignJWT(claims)
+	_, err = VerifyJWT(token, did, KeyPurposeAuthentication, nil)
+	assert.Error(t, err)
+	_, err = VerifyJWT(token, did, KeyPurposeAuthentication, nil, WithLeeway(time.Minute))
+	assert.NoError(t, err)
+
+	// not valid yet
+	claims.ExpiresAt = 0
+	claims.NotBefore = now + 60
+	token, _ = key.SignJWT(claims)
+	_, err = VerifyJWT(token, did, KeyPurposeAuthentication, nil)
+	assert.Error(t, err)
+
+	// issuer is not DID of the signing key
+	claims.NotBefore = 0
+	claims.Issuer = NewDID().ID
+	token, _ = key.SignJWT(claims)
+	_, err = VerifyJWT(token, did, KeyPurposeAuthentication, nil)
+	assert.Error(t, err)
+
+	// audience array
+	audience := JWTAudience{}
+	assert.NoError(t, json.Unmarshal([]byte(`["a","b"]`), &audience))
+	assert.True(t, audience.Contains("b"))
+	assert.Error(t, json.Unmarshal([]byte(`1`), &audience))
+
+}
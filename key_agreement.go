@@ -0,0 +1,108 @@
+package factomdid
+
+import (
+	"crypto/ed25519"
+	"crypto/sha512"
+	"fmt"
+	"math/big"
+
+	"golang.org/x/crypto/curve25519"
+)
+
+// curve25519 field prime 2^255 - 19
+var curve25519P, _ = new(big.Int).SetString("7fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffed", 16)
+
+// NewKeyAgreementKey generates new X25519 DIDKey with keyAgreement purpose
+func NewKeyAgreementKey(alias string) (*DIDKey, error) {
+
+	key, err := NewDIDKey(alias, KeyTypeX25519)
+	if err != nil {
+		return nil, err
+	}
+
+	return key.AddPurpose(KeyPurposeKeyAgreement)
+
+}
+
+// DeriveKeyAgreementKey derives X25519 key agreement DIDKey from Ed25519 DIDKey.
+// Private key is derived only if Ed25519 DIDKey has one, so public-only keys can be converted as well
+func (didkey *DIDKey) DeriveKeyAgreementKey(alias string) (*DIDKey, error) {
+
+	if didkey.KeyType != KeyTypeEdDSA {
+		return nil, fmt.Errorf("Only %s DIDKey can be converted into %s", KeyTypeEdDSA, KeyTypeX25519)
+	}
+
+	key := &DIDKey{}
+	key.Alias = alias
+	key.KeyType = KeyTypeX25519
+	key.Controller = didkey.Controller
+	key.PriorityRequirement = didkey.PriorityRequirement
+
+	// validate Alias and KeyType
+	err := validate.StructPartial(key.AbstractKey, "Alias", "KeyType")
+	if err != nil {
+		return nil, err
+	}
+
+	key.PublicKey, err = ed25519PublicKeyToX25519(didkey.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if didkey.HasPrivateKey() {
+		key.PrivateKey, err = ed25519PrivateKeyToX25519(didkey.PrivateKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return key.AddPurpose(KeyPurposeKeyAgreement)
+
+}
+
+// helper function that converts Ed25519 public key (Edwards y) into X25519 public key (Montgomery u = (1 + y) / (1 - y))
+func ed25519PublicKeyToX25519(publicKey []byte) ([]byte, error) {
+
+	if len(publicKey) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("Invalid Ed25519 public key")
+	}
+
+	// big-endian y coordinate without sign bit
+	b := reverse(publicKey)
+	b[0] &= 0x7f
+
+	y := new(big.Int).SetBytes(b)
+	if y.Cmp(curve25519P) >= 0 {
+		return nil, fmt.Errorf("Invalid Ed25519 public key")
+	}
+
+	denominator := new(big.Int).Sub(big.NewInt(1), y)
+	denominator.Mod(denominator, curve25519P)
+	if denominator.Sign() == 0 {
+		return nil, fmt.Errorf("Invalid Ed25519 public key")
+	}
+
+	u := new(big.Int).Add(big.NewInt(1), y)
+	u.Mul(u, denominator.ModInverse(denominator, curve25519P))
+	u.Mod(u, curve25519P)
+
+	// back to little-endian
+	return reverse(u.FillBytes(make([]byte, curve25519.PointSize))), nil
+
+}
+
+// helper function that converts Ed25519 private key into X25519 private key (clamped SHA-512 of the seed)
+func ed25519PrivateKeyToX25519(privateKey []byte) ([]byte, error) {
+
+	if len(privateKey) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("Invalid Ed25519 private key")
+	}
+
+	h := sha512.Sum512(ed25519.PrivateKey(privateKey).Seed())
+	h[0] &= 248
+	h[31] &= 127
+	h[31] |= 64
+
+	return h[:curve25519.ScalarSize], nil
+
+}
@@ -0,0 +1,125 @@
+package factomdid
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/curve25519"
+)
+
+func TestNewKeyAgreementKey(t *testing.T) {
+
+	k, err := NewKeyAgreementKey("ka")
+	assert.NoError(t, err)
+	assert.Equal(t, KeyTypeX25519, k.KeyType)
+	assert.Equal(t, 32, len(k.PrivateKey))
+	assert.Equal(t, 32, len(k.PublicKey))
+	assert.True(t, k.HasPurpose(KeyPurposeKeyAgreement))
+
+	// key agreement keys can't sign
+	signature, err := k.Sign([]byte("Test"))
+	assert.Nil(t, signature)
+	assert.Error(t, err)
+
+	// invalid alias
+	k, err = NewKeyAgreementKey("")
+	assert.Nil(t, k)
+	assert.Error(t, err)
+
+	// key agreement keys can't be ManagementKeys
+	m, err := NewManagementKey("m", KeyTypeX25519, 0)
+	assert.Nil(t, m)
+	assert.Error(t, err)
+
+}
+
+func TestDeriveKeyAgreementKey(t *testing.T) {
+
+	edKey, _ := NewDIDKey("ed", KeyTypeEdDSA)
+	edKey.AddPurpose(KeyPurposeAuthentication)
+
+	k, err := edKey.DeriveKeyAgreementKey("ka")
+	assert.NoError(t, err)
+	assert.Equal(t, KeyTypeX25519, k.KeyType)
+	assert.True(t, k.HasPurpose(KeyPurposeKeyAgreement))
+
+	// derived public key matches derived private key
+	pub, err := curve25519.X25519(k.PrivateKey, curve25519.Basepoint)
+	assert.NoError(t, err)
+	assert.Equal(t, pub, k.PublicKey)
+
+	// public-only Ed25519 key gives the same public key
+	pk, err := edKey.Public().DeriveKeyAgreementKey("ka")
+	assert.NoError(t, err)
+	assert.Empty(t, pk.PrivateKey)
+	assert.Equal(t, k.PublicKey, pk.PublicKey)
+
+	// shared secrets match
+	other, _ := NewKeyAgreementKey("other")
+	s1, _ := curve25519.X25519(k.PrivateKey, other.PublicKey)
+	s2, _ := curve25519.X25519(other.PrivateKey, pk.PublicKey)
+	assert.True(t, bytes.Equal(s1, s2))
+
+	// only Ed25519 keys can be converted
+	ecKey, _ := NewDIDKey("ec", KeyTypeECDSA)
+	k, err = ecKey.DeriveKeyAgreementKey("ka")
+	assert.Nil(t, k)
+	assert.Error(t, err)
+
+}
+
+func TestKeyAgreementKeyPurpose(t *testing.T) {
+
+	// key agreement keys require entry schema 1.0.0+factomdid-ext
+	did, err := NewDIDFromReader(rand.Reader, WithEntrySchema(EntrySchemaV100Ext))
+	assert.NoError(t, err)
+
+	// X25519 key with publicKey purpose
+	k1, _ := NewDIDKey("k1", KeyTypeX25519)
+	k1.AddPurpose(KeyPurposePublic)
+	_, err = did.AddDIDKey(k1)
+	assert.Error(t, err)
+
+	// X25519 key with two purposes
+	k2, _ := NewKeyAgreementKey("k2")
+	k2.AddPurpose(KeyPurposeAuthentication)
+	_, err = did.AddDIDKey(k2)
+	assert.Error(t, err)
+
+	// Ed25519 key with keyAgreement purpose
+	k3, _ := NewDIDKey("k3", KeyTypeEdDSA)
+	k3.AddPurpose(KeyPurposeKeyAgreement)
+	_, err = did.AddDIDKey(k3)
+	assert.Error(t, err)
+
+	// add and revoke valid key agreement key
+	didKey, _ := NewDIDKey("did-key", KeyTypeEdDSA)
+	didKey.AddPurpose(KeyPurposeAuthentication)
+	mgmtKey, _ := NewManagementKey("mgmt-key", KeyTypeEdDSA, 0)
+	did.AddDIDKey(didKey)
+	did.AddManagementKey(mgmtKey)
+
+	ka, _ := NewKeyAgreementKey("ka")
+	_, err = did.AddDIDKey(ka)
+	assert.NoError(t, err)
+
+	fe, err := did.Create()
+	assert.NoError(t, err)
+	assert.Contains(t, string(fe.Content), KeyPurposeKeyAgreement)
+	assert.Contains(t, string(fe.Content), KeyTypeX25519)
+
+	// entry schema 1.0.0 doesn't allow X25519 keys and keyAgreement purpose
+	err = ValidateEntryContent(EntryTypeCreate, EntrySchemaV100, fe.Content)
+	assert.ElementsMatch(t, []string{"/didKey/1/type", "/didKey/1/purpose/0"}, schemaErrorPaths(t, err))
+
+	updatedDID := did.Copy()
+	_, err = updatedDID.RevokeDIDKey("ka")
+	assert.NoError(t, err)
+
+	fe, err = did.Update(updatedDID, "mgmt-key", WithEntrySchema(EntrySchemaV100Ext))
+	assert.NoError(t, err)
+	assert.Contains(t, string(fe.Content), did.ID+"#ka")
+
+}
@@ -0,0 +1,96 @@
+package factomdid
+
+import (
+	"fmt"
+)
+
+// KeyOptions configures key generation of NewDIDKey and NewManagementKey
+type KeyOptions struct {
+	// RSABits is RSA key size, 2048 by default
+	RSABits int
+	// RSAPublicExponent is RSA public exponent, 65537 by default
+	RSAPublicExponent int
+	// RSAPSS enables RSASSA-PSS signatures for generated RSA key
+	RSAPSS bool
+}
+
+// KeyOption sets KeyOptions
+type KeyOption func(*KeyOptions)
+
+const (
+	// DefaultRSABits is default RSA key size
+	DefaultRSABits = 2048
+	// MinRSABits is minimum RSA key size
+	MinRSABits = 2048
+	// MaxRSABits is maximum RSA key size
+	MaxRSABits = 8192
+	// DefaultRSAPublicExponent is default (and the only supported for key generation) RSA public exponent,
+	// public keys with smaller or even exponent are rejected
+	DefaultRSAPublicExponent = 65537
+)
+
+// WithRSABits sets RSA key size, e.g. 3072 or 4096
+func WithRSABits(bits int) KeyOption {
+	return func(o *KeyOptions) {
+		o.RSABits = bits
+	}
+}
+
+// WithRSAPublicExponent sets RSA public exponent
+func WithRSAPublicExponent(e int) KeyOption {
+	return func(o *KeyOptions) {
+		o.RSAPublicExponent = e
+	}
+}
+
+// WithRSAPSS makes generated RSA key sign with RSASSA-PSS instead of PKCS #1 v1.5
+func WithRSAPSS() KeyOption {
+	return func(o *KeyOptions) {
+		o.RSAPSS = true
+	}
+}
+
+// helper function that applies KeyOption list to default KeyOptions
+func newKeyOptions(opts []KeyOption) *KeyOptions {
+
+	o := &KeyOptions{RSABits: DefaultRSABits, RSAPublicExponent: DefaultRSAPublicExponent}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+
+}
+
+// helper function that validates RSA options of key generation.
+// Key size limits apply to generated keys only, existing keys of any size can be imported and verified
+func (o *KeyOptions) validateRSA() error {
+
+	if o.RSABits < MinRSABits || o.RSABits > MaxRSABits || o.RSABits%8 != 0 {
+		return fmt.Errorf("RSA key size must be a multiple of 8 between %d and %d bits, got %d", MinRSABits, MaxRSABits, o.RSABits)
+	}
+
+	err := validateRSAPublicExponent(o.RSAPublicExponent)
+	if err != nil {
+		return err
+	}
+
+	// crypto/rsa always generates keys with 65537 public exponent
+	if o.RSAPublicExponent != DefaultRSAPublicExponent {
+		return fmt.Errorf("Only %d RSA public exponent is supported for key generation, got %d", DefaultRSAPublicExponent, o.RSAPublicExponent)
+	}
+
+	return nil
+
+}
+
+// helper function that validates RSA public exponent of generated and imported keys
+func validateRSAPublicExponent(e int) error {
+
+	if e < DefaultRSAPublicExponent || e%2 == 0 {
+		return fmt.Errorf("RSA public exponent must be odd and at least %d, got %d", DefaultRSAPublicExponent, e)
+	}
+
+	return nil
+
+}
@@ -0,0 +1,176 @@
+package factomdid
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"math/big"
+	"testing"
+
+	"github.com/FactomProject/btcutil/base58"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyOptions(t *testing.T) {
+
+	// default RSA key size
+	k1, err := NewDIDKey("test", KeyTypeRSA)
+	assert.NoError(t, err)
+	p, _ := x509.ParsePKCS1PublicKey(k1.PublicKey)
+	assert.Equal(t, DefaultRSABits, p.N.BitLen())
+	assert.Equal(t, 65537, p.E)
+
+	// custom RSA key size
+	k2, err := NewManagementKey("test", KeyTypeRSA, 0, WithRSABits(3072))
+	assert.NoError(t, err)
+	p, _ = x509.ParsePKCS1PublicKey(k2.PublicKey)
+	assert.Equal(t, 3072, p.N.BitLen())
+
+	// invalid RSA key size
+	k3, err := NewDIDKey("test", KeyTypeRSA, WithRSABits(1024))
+	assert.Nil(t, k3)
+	assert.Error(t, err)
+
+	// existing RSA key smaller than MinRSABits is still usable
+	small, err := rsa.GenerateKey(rand.Reader, 1024)
+	assert.NoError(t, err)
+	k4 := &AbstractKey{KeyType: KeyTypeRSA, PublicKey: x509.MarshalPKCS1PublicKey(&small.PublicKey), PrivateKey: x509.MarshalPKCS1PrivateKey(small)}
+	signature, err := k4.Sign([]byte("Test"))
+	assert.NoError(t, err)
+	v, err := k4.Verify([]byte("Test"), signature)
+	assert.NoError(t, err)
+	assert.True(t, v)
+	_, err = k4.ToJWK()
+	assert.NoError(t, err)
+
+	// invalid RSA public exponent
+	for _, e := range []int{3, 65535, 65538} {
+		k, err := NewDIDKey("test", KeyTypeRSA, WithRSAPublicExponent(e))
+		assert.Nil(t, k)
+		assert.Error(t, err)
+	}
+	// valid, but not supported by key generation
+	k, err := NewDIDKey("test", KeyTypeRSA, WithRSAPublicExponent(65539))
+	assert.Nil(t, k)
+	assert.Error(t, err)
+
+	// RSA options for non-RSA key
+	k5, err := NewDIDKey("test", KeyTypeEdDSA, WithRSABits(3072))
+	assert.Nil(t, k5)
+	assert.Error(t, err)
+	k6, err := NewDIDKey("test", KeyTypeECDSA, WithRSAPSS())
+	assert.Nil(t, k6)
+	assert.Error(t, err)
+
+}
+
+func TestRSAPublicExponent(t *testing.T) {
+
+	suite, _ := GetKeySuite(KeyTypeRSA)
+	privateKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		E     int
+		Error bool
+	}{
+		{65537, false},
+		{65539, false},
+		{3, true},
+		{65535, true},
+		{65538, true},
+	}
+
+	for _, c := range testCases {
+
+		publicKey := x509.MarshalPKCS1PublicKey(&rsa.PublicKey{N: privateKey.N, E: c.E})
+
+		// JWK import
+		jwk := &JWK{Kty: JWKKeyTypeRSA, N: base64.RawURLEncoding.EncodeToString(privateKey.N.Bytes()), E: base64.RawURLEncoding.EncodeToString(big.NewInt(int64(c.E)).Bytes())}
+		imported, err := suite.FromJWK(jwk)
+		if c.Error {
+			assert.Nil(t, imported)
+			assert.Error(t, err)
+		} else {
+			assert.NoError(t, err)
+			assert.Equal(t, publicKey, imported)
+		}
+
+		// on-chain import
+		decoded, err := suite.DecodePublicKey(OnChainPubKeyPemName, string(pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: publicKey})))
+		if c.Error {
+			assert.Nil(t, decoded)
+			assert.Error(t, err)
+		} else {
+			assert.NoError(t, err)
+			assert.Equal(t, publicKey, decoded)
+		}
+
+		// multibase import
+		prefix := binary.AppendUvarint(nil, MulticodecRSAPub)
+		_, err = (&AbstractKey{}).FromMultibase(MultibaseBase58BTC + base58.Encode(append(prefix, publicKey...)))
+		assert.Equal(t, c.Error, err != nil)
+
+	}
+
+}
+
+func TestRSAPSS(t *testing.T) {
+
+	pssKey, err := NewManagementKey("pss", KeyTypeRSA, 0, WithRSAPSS())
+	assert.NoError(t, err)
+	assert.True(t, pssKey.RSAPSS)
+
+	signature, err := pssKey.Sign([]byte("Test"))
+	assert.NoError(t, err)
+
+	// signature is RSASSA-PSS
+	p, _ := x509.ParsePKCS1PublicKey(pssKey.PublicKey)
+	hashed := sha256.Sum256([]byte("Test"))
+	assert.NoError(t, rsa.VerifyPSS(p, crypto.SHA256, hashed[:], signature, nil))
+	assert.Error(t, rsa.VerifyPKCS1v15(p, crypto.SHA256, hashed[:], signature))
+
+	v, err := pssKey.Verify([]byte("Test"), signature)
+	assert.NoError(t, err)
+	assert.True(t, v)
+
+	v, err = pssKey.Verify([]byte("Test2"), signature)
+	assert.Error(t, err)
+	assert.False(t, v)
+
+	// legacy PKCS #1 v1.5 signature of the same key is accepted
+	legacyKey := *pssKey
+	legacyKey.RSAPSS = false
+	legacySignature, err := legacyKey.Sign([]byte("Test"))
+	assert.NoError(t, err)
+	assert.NoError(t, rsa.VerifyPKCS1v15(p, crypto.SHA256, hashed[:], legacySignature))
+
+	v, err = pssKey.Verify([]byte("Test"), legacySignature)
+	assert.NoError(t, err)
+	assert.True(t, v)
+
+	// RSASSA-PSS only verification rejects PKCS #1 v1.5 signature
+	suite, _ := GetKeySuite(KeyTypeRSA)
+	v, err = suite.Verify(pssKey.PublicKey, hashed[:], legacySignature, &rsa.PSSOptions{Hash: crypto.SHA256})
+	assert.Error(t, err)
+	assert.False(t, v)
+
+	// RSASSA-PSS is used for entries signed by the key
+	did := NewDID()
+	didKey, _ := NewDIDKey("did-key", KeyTypeEdDSA)
+	didKey.AddPurpose(KeyPurposePublic)
+	did.AddDIDKey(didKey)
+	did.AddManagementKey(pssKey)
+
+	fe, err := did.Deactivate("pss")
+	assert.NoError(t, err)
+	m := []byte(EntryTypeDeactivation + LatestEntrySchema + did.ID + "#pss")
+	hashed = sha256.Sum256(m)
+	assert.NoError(t, rsa.VerifyPSS(p, crypto.SHA256, hashed[:], fe.ExtIDs[3], nil))
+
+}
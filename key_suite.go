@@ -0,0 +1,261 @@
+package factomdid
+
+import (
+	"crypto"
+	"fmt"
+	"sort"
+	"sync"
+
+	"gopkg.in/go-playground/validator.v9"
+)
+
+// KeySuite implements cryptography of a single key type (AbstractKey.KeyType).
+// Built-in suites cover ECDSA secp256k1, ECDSA P-256, Ed25519, RSA and X25519 keys,
+// custom key types may be added with RegisterKeySuite
+type KeySuite interface {
+	// KeyType returns AbstractKey.KeyType served by the suite
+	KeyType() string
+	// GenerateKey generates random keypair
+	GenerateKey() (publicKey []byte, privateKey []byte, err error)
+	// Sign signs data with private key, data and opts are defined by SignatureMode,
+	// e.g. SignatureModePrehashed passes SHA-256 hash of the message as data and crypto.SHA256 as opts
+	Sign(privateKey []byte, data []byte, opts crypto.SignerOpts) ([]byte, error)
+	// Verify verifies signature of data with public key
+	Verify(publicKey []byte, data []byte, signature []byte, opts crypto.SignerOpts) (bool, error)
+	// EncodePublicKey encodes public key for on-chain entry, returns property name (OnChainPubKeyName or OnChainPubKeyPemName) and value
+	EncodePublicKey(publicKey []byte) (name string, value string, err error)
+	// DecodePublicKey decodes on-chain public key property
+	DecodePublicKey(name string, value string) ([]byte, error)
+	// ToJWK exports public key as JWK
+	ToJWK(publicKey []byte) (*JWK, error)
+	// FromJWK imports public key from JWK, returns error if JWK is not of the suite key type
+	FromJWK(jwk *JWK) ([]byte, error)
+	// JWKType returns JWK "kty" and "crv" of the suite keys, crv is empty if kty has no curves (e.g. RSA)
+	JWKType() (kty string, crv string)
+	// JWSAlgorithms returns JWS "alg" values accepted for the suite keys, the first one is used for signing.
+	// Algorithms not defined by the library sign SHA-256 digest of JWS signing input with SignatureModePrehashed.
+	// Suites which keys can't sign return nil
+	JWSAlgorithms() []string
+}
+
+// MultibaseSuite is a KeySuite which has multicodec code, so its public keys can be encoded as publicKeyMultibase
+type MultibaseSuite interface {
+	KeySuite
+	// Multicodec returns multicodec code of the public key, e.g. 0xed for Ed25519
+	Multicodec() uint64
+}
+
+// ConfigurableKeySuite is a KeySuite which supports KeyOptions for key generation
+type ConfigurableKeySuite interface {
+	KeySuite
+	// GenerateKeyWithOptions generates random keypair using KeyOptions
+	GenerateKeyWithOptions(opts *KeyOptions) (publicKey []byte, privateKey []byte, err error)
+}
+
+// StrictKeySuite is a KeySuite which is able to reject malleable signatures.
+// Used by AbstractKey.VerifyStrict, e.g. when signatures identify entries on chain replay
+type StrictKeySuite interface {
+	KeySuite
+	// CheckCanonicalSignature returns error if signature is not in its only canonical encoding
+	CheckCanonicalSignature(publicKey []byte, signature []byte) error
+}
+
+// KeyAgreementSuite is a KeySuite of key agreement keys.
+// Keys of such suites can't sign and are used with keyAgreement purpose only
+type KeyAgreementSuite interface {
+	KeySuite
+	// SharedSecret computes shared secret of private key and peer's public key
+	SharedSecret(privateKey []byte, peerPublicKey []byte) ([]byte, error)
+}
+
+const (
+	// OnChainPubKeyPemName is name of PEM encoded public key on-chain
+	OnChainPubKeyPemName = "publicKeyPem"
+)
+
+// built-in key suites
+var keySuites = map[string]KeySuite{
+	KeyTypeECDSA:     &ecdsaSecp256k1Suite{},
+	KeyTypeECDSAP256: &ecdsaP256Suite{},
+	KeyTypeEdDSA:     &ed25519Suite{},
+	KeyTypeRSA:       &rsaSuite{},
+	KeyTypeX25519:    &x25519Suite{},
+}
+var keySuitesMtx sync.RWMutex
+
+// key types of built-in key suites, RegisterKeySuite can't add new ones
+var builtinKeyTypes = make(map[string]bool)
+
+func init() {
+	for keyType := range keySuites {
+		builtinKeyTypes[keyType] = true
+	}
+}
+
+// RegisterKeySuite registers custom KeySuite, so its KeyType may be used in DIDKey and ManagementKey
+func RegisterKeySuite(suite KeySuite) error {
+
+	if suite == nil || suite.KeyType() == "" {
+		return fmt.Errorf("KeySuite must have non-empty KeyType")
+	}
+
+	keySuitesMtx.Lock()
+	defer keySuitesMtx.Unlock()
+
+	if _, ok := keySuites[suite.KeyType()]; ok {
+		return fmt.Errorf("KeySuite %s is already registered", suite.KeyType())
+	}
+
+	keySuites[suite.KeyType()] = suite
+
+	return nil
+
+}
+
+// helper function that removes custom KeySuite of keyType, used by tests to undo RegisterKeySuite
+func unregisterKeySuite(keyType string) {
+
+	keySuitesMtx.Lock()
+	defer keySuitesMtx.Unlock()
+
+	if !builtinKeyTypes[keyType] {
+		delete(keySuites, keyType)
+	}
+
+}
+
+// GetKeySuite returns registered KeySuite of keyType
+func GetKeySuite(keyType string) (KeySuite, error) {
+
+	keySuitesMtx.RLock()
+	defer keySuitesMtx.RUnlock()
+
+	suite, ok := keySuites[keyType]
+	if !ok {
+		return nil, fmt.Errorf("Invalid key.KeyType")
+	}
+
+	return suite, nil
+
+}
+
+// KeyTypes returns sorted list of registered key types
+func KeyTypes() []string {
+
+	keySuitesMtx.RLock()
+	defer keySuitesMtx.RUnlock()
+
+	var keyTypes []string
+	for keyType := range keySuites {
+		keyTypes = append(keyTypes, keyType)
+	}
+	sort.Strings(keyTypes)
+
+	return keyTypes
+
+}
+
+// helper function that checks if keyType is one of built-in key types
+func isBuiltinKeyType(keyType string) bool {
+	return builtinKeyTypes[keyType]
+}
+
+// validation function for "keytype" tag, checks that KeyType is registered
+func validateKeyType(fl validator.FieldLevel) bool {
+	_, err := GetKeySuite(fl.Field().String())
+	return err == nil
+}
+
+// helper function that finds key type of JWK kty and crv, built-in key types take precedence over custom ones
+func jwkKeyType(jwk *JWK) string {
+
+	var custom string
+
+	for _, keyType := range KeyTypes() {
+
+		suite, err := GetKeySuite(keyType)
+		if err != nil {
+			continue
+		}
+
+		kty, crv := suite.JWKType()
+		if kty != jwk.Kty || crv != jwk.Crv {
+			continue
+		}
+
+		if isBuiltinKeyType(keyType) {
+			return keyType
+		}
+
+		if custom == "" {
+			custom = keyType
+		}
+
+	}
+
+	return custom
+
+}
+
+// helper function that checks if JWS alg is accepted for keys of suite
+func hasJWSAlgorithm(suite KeySuite, alg string) bool {
+
+	for _, a := range suite.JWSAlgorithms() {
+		if a == alg {
+			return true
+		}
+	}
+
+	return false
+
+}
+
+// helper function that checks if keyType is served by KeyAgreementSuite
+func isKeyAgreementKeyType(keyType string) bool {
+
+	suite, err := GetKeySuite(keyType)
+	if err != nil {
+		return false
+	}
+
+	_, ok := suite.(KeyAgreementSuite)
+
+	return ok
+
+}
+
+// helper function that sets on-chain public key property of DIDKeySchema or ManagementKeySchema
+func setOnChainPublicKey(suite KeySuite, publicKey []byte, base58 *string, pem *string) error {
+
+	name, value, err := suite.EncodePublicKey(publicKey)
+	if err != nil {
+		return err
+	}
+
+	switch name {
+	case OnChainPubKeyName:
+		*base58 = value
+	case OnChainPubKeyPemName:
+		*pem = value
+	default:
+		return fmt.Errorf("Unsupported on-chain public key property %s", name)
+	}
+
+	return nil
+
+}
+
+// helper function that decodes on-chain public key property of DIDKeySchema or ManagementKeySchema
+func getOnChainPublicKey(suite KeySuite, base58 string, pem string) ([]byte, error) {
+
+	if base58 != "" && pem != "" {
+		return nil, fmt.Errorf("Public key must have either %s or %s property", OnChainPubKeyName, OnChainPubKeyPemName)
+	}
+
+	if pem != "" {
+		return suite.DecodePublicKey(OnChainPubKeyPemName, pem)
+	}
+
+	return suite.DecodePublicKey(OnChainPubKeyName, base58)
+
+}
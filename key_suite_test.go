@@ -0,0 +1,182 @@
+package factomdid
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// custom key suite for tests, Ed25519 under another key type
+type testKeySuite struct {
+	ed25519Suite
+}
+
+func (s *testKeySuite) KeyType() string {
+	return "TestVerificationKey"
+}
+
+func (s *testKeySuite) JWKType() (string, string) {
+	return JWKKeyTypeOKP, "TestCurve"
+}
+
+func (s *testKeySuite) ToJWK(publicKey []byte) (*JWK, error) {
+	return &JWK{Kty: JWKKeyTypeOKP, Crv: "TestCurve", X: base64.RawURLEncoding.EncodeToString(publicKey)}, nil
+}
+
+func (s *testKeySuite) FromJWK(jwk *JWK) ([]byte, error) {
+	return decodeJWKOctetKey(jwk, "TestCurve", ed25519.PublicKeySize)
+}
+
+func (s *testKeySuite) JWSAlgorithms() []string {
+	return []string{"TestEdDSA"}
+}
+
+// entry schema for tests, entry schema 1.0.0 extended with TestVerificationKey key type
+type testKeyEntrySchema struct {
+	embeddedEntrySchema
+}
+
+func (s *testKeyEntrySchema) Version() string {
+	return "1.0.0-test-key"
+}
+
+func (s *testKeyEntrySchema) EncodeManagement(v *DIDManagementEntrySchema) ([]byte, error) {
+
+	content, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return content, s.ValidateContent(EntryTypeCreate, content)
+
+}
+
+func (s *testKeyEntrySchema) ValidateContent(entryType string, content []byte) error {
+	return s.embeddedEntrySchema.ValidateContent(entryType, bytes.ReplaceAll(content, []byte(`"TestVerificationKey"`), []byte(`"`+KeyTypeEdDSA+`"`)))
+}
+
+func TestRegisterKeySuite(t *testing.T) {
+
+	// unknown key type
+	_, err := NewDIDKey("test", "TestVerificationKey")
+	assert.Error(t, err)
+
+	err = RegisterKeySuite(&testKeySuite{})
+	assert.NoError(t, err)
+	t.Cleanup(func() { unregisterKeySuite("TestVerificationKey") })
+	assert.Contains(t, KeyTypes(), "TestVerificationKey")
+
+	// duplicate registration
+	err = RegisterKeySuite(&testKeySuite{})
+	assert.Error(t, err)
+	err = RegisterKeySuite(&ed25519Suite{})
+	assert.Error(t, err)
+
+	// custom key type works end to end
+	didKey, err := NewDIDKey("test", "TestVerificationKey")
+	assert.NoError(t, err)
+	didKey.AddPurpose(KeyPurposePublic)
+
+	signature, err := didKey.Sign([]byte("Test"))
+	assert.NoError(t, err)
+	v, err := didKey.Verify([]byte("Test"), signature)
+	assert.NoError(t, err)
+	assert.True(t, v)
+
+	mgmtKey, err := NewManagementKey("mgmt", "TestVerificationKey", 0)
+	assert.NoError(t, err)
+
+	// JWK export and import use kty and crv of the suite
+	jwk, err := didKey.ToJWK()
+	assert.NoError(t, err)
+	assert.Equal(t, "TestCurve", jwk.Crv)
+	imported, err := (&AbstractKey{}).FromJWK(jwk)
+	assert.NoError(t, err)
+	assert.Equal(t, "TestVerificationKey", imported.KeyType)
+	assert.Equal(t, didKey.PublicKey, imported.PublicKey)
+
+	// built-in key types take precedence over custom ones of the same kty and crv
+	edKey, _ := NewDIDKey("ed", KeyTypeEdDSA)
+	jwk, _ = edKey.ToJWK()
+	imported, err = (&AbstractKey{}).FromJWK(jwk)
+	assert.NoError(t, err)
+	assert.Equal(t, KeyTypeEdDSA, imported.KeyType)
+
+	// entry schema 1.0.0 rejects key types not defined by DID spec
+	did := NewDID()
+	did.AddDIDKey(didKey)
+	did.AddManagementKey(mgmtKey)
+
+	_, err = did.Create()
+	assert.Error(t, err)
+
+	// custom key type comes with its own entry schema
+	entrySchema := &testKeyEntrySchema{embeddedEntrySchema{version: EntrySchemaV100}}
+	err = RegisterEntrySchema(entrySchema)
+	assert.NoError(t, err)
+	t.Cleanup(func() { unregisterEntrySchema(entrySchema.Version()) })
+
+	did, err = NewDIDFromReader(rand.Reader, WithEntrySchema(entrySchema.Version()))
+	assert.NoError(t, err)
+	did.AddDIDKey(didKey)
+	did.AddManagementKey(mgmtKey)
+
+	fe, err := did.Create(WithEntrySchema(entrySchema.Version()))
+	assert.NoError(t, err)
+	assert.Contains(t, string(fe.Content), "TestVerificationKey")
+
+	// JWS is signed and verified with alg of the suite
+	alg, err := didKey.JWSAlgorithm()
+	assert.NoError(t, err)
+	assert.Equal(t, "TestEdDSA", alg)
+
+	token, err := didKey.SignJWS([]byte("Test"))
+	assert.NoError(t, err)
+	payload, key, err := VerifyJWS(token, newTestResolver(did), KeyPurposePublic)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("Test"), payload)
+	assert.Equal(t, didKey.PublicKey, key.PublicKey)
+
+	// alg of another key type is rejected
+	valid, err := verifyJWSSignature(&didKey.AbstractKey, JWSAlgorithmEdDSA, []byte("Test"), signature)
+	assert.False(t, valid)
+	assert.Error(t, err)
+
+}
+
+func TestGetKeySuite(t *testing.T) {
+
+	for _, keyType := range []string{KeyTypeECDSA, KeyTypeECDSAP256, KeyTypeEdDSA, KeyTypeRSA, KeyTypeX25519} {
+
+		suite, err := GetKeySuite(keyType)
+		assert.NoError(t, err)
+		assert.Equal(t, keyType, suite.KeyType())
+
+		// on-chain encoding round trip
+		publicKey, privateKey, err := suite.GenerateKey()
+		assert.NoError(t, err)
+		assert.NotEmpty(t, privateKey)
+
+		name, value, err := suite.EncodePublicKey(publicKey)
+		assert.NoError(t, err)
+		decoded, err := suite.DecodePublicKey(name, value)
+		assert.NoError(t, err)
+		assert.Equal(t, publicKey, decoded)
+
+	}
+
+	suite, err := GetKeySuite("WrongKeyType")
+	assert.Nil(t, suite)
+	assert.Error(t, err)
+
+	// key agreement suites
+	assert.True(t, isKeyAgreementKeyType(KeyTypeX25519))
+	assert.False(t, isKeyAgreementKeyType(KeyTypeEdDSA))
+	assert.False(t, isKeyAgreementKeyType("WrongKeyType"))
+
+}
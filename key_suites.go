@@ -0,0 +1,748 @@
+package factomdid
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+
+	"github.com/FactomProject/btcutil/base58"
+	"github.com/frankbraun/dcrd/dcrec/secp256k1"
+	"golang.org/x/crypto/curve25519"
+)
+
+// ECDSA secp256k1 key suite
+type ecdsaSecp256k1Suite struct{}
+
+func (s *ecdsaSecp256k1Suite) KeyType() string {
+	return KeyTypeECDSA
+}
+
+func (s *ecdsaSecp256k1Suite) GenerateKey() ([]byte, []byte, error) {
+
+	// Generate random ecdsa key
+	privateKey, err := secp256k1.GeneratePrivateKey(secp256k1.S256())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Get corresponding public key
+	x, y := privateKey.Public()
+
+	return secp256k1.NewPublicKey(secp256k1.S256(), x, y).Serialize(), privateKey.Serialize(), nil
+
+}
+
+func (s *ecdsaSecp256k1Suite) Sign(privateKey []byte, data []byte, opts crypto.SignerOpts) ([]byte, error) {
+
+	err := checkSHA256Digest(data, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	privKey, _ := secp256k1.PrivKeyFromBytes(secp256k1.S256(), privateKey)
+
+	signature, err := privKey.Sign(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return signature.Serialize(), nil
+
+}
+
+func (s *ecdsaSecp256k1Suite) Verify(publicKey []byte, data []byte, signature []byte, opts crypto.SignerOpts) (bool, error) {
+
+	err := checkSHA256Digest(data, opts)
+	if err != nil {
+		return false, err
+	}
+
+	pubKey, err := secp256k1.ParsePubKey(publicKey, secp256k1.S256())
+	if err != nil {
+		return false, err
+	}
+
+	sig, err := secp256k1.ParseSignature(signature, secp256k1.S256())
+	if err != nil {
+		return false, err
+	}
+
+	return sig.Verify(data, pubKey), nil
+
+}
+
+// CheckCanonicalSignature accepts strict DER signatures with low S only
+func (s *ecdsaSecp256k1Suite) CheckCanonicalSignature(publicKey []byte, signature []byte) error {
+
+	sig, err := secp256k1.ParseDERSignature(signature, secp256k1.S256())
+	if err != nil {
+		return err
+	}
+
+	// Serialize produces strict DER with low S
+	if !bytes.Equal(sig.Serialize(), signature) {
+		return fmt.Errorf("Signature is not canonical: high S or non-strict DER")
+	}
+
+	return nil
+
+}
+
+func (s *ecdsaSecp256k1Suite) EncodePublicKey(publicKey []byte) (string, string, error) {
+	return OnChainPubKeyName, base58.Encode(publicKey), nil
+}
+
+func (s *ecdsaSecp256k1Suite) DecodePublicKey(name string, value string) ([]byte, error) {
+	return decodeBase58PublicKey(name, value)
+}
+
+func (s *ecdsaSecp256k1Suite) ToJWK(publicKey []byte) (*JWK, error) {
+
+	pubKey, err := secp256k1.ParsePubKey(publicKey, secp256k1.S256())
+	if err != nil {
+		return nil, err
+	}
+
+	return &JWK{Kty: JWKKeyTypeEC, Crv: JWKCurveSecp256k1, X: encodeJWKCoordinate(pubKey.GetX()), Y: encodeJWKCoordinate(pubKey.GetY())}, nil
+
+}
+
+func (s *ecdsaSecp256k1Suite) FromJWK(jwk *JWK) ([]byte, error) {
+
+	x, y, err := decodeJWKCoordinates(jwk, JWKCurveSecp256k1)
+	if err != nil {
+		return nil, err
+	}
+
+	if !secp256k1.S256().IsOnCurve(x, y) {
+		return nil, fmt.Errorf("JWK point is not on %s curve", JWKCurveSecp256k1)
+	}
+
+	return secp256k1.NewPublicKey(secp256k1.S256(), x, y).Serialize(), nil
+
+}
+
+func (s *ecdsaSecp256k1Suite) Multicodec() uint64 {
+	return MulticodecSecp256k1Pub
+}
+
+func (s *ecdsaSecp256k1Suite) JWKType() (string, string) {
+	return JWKKeyTypeEC, JWKCurveSecp256k1
+}
+
+func (s *ecdsaSecp256k1Suite) JWSAlgorithms() []string {
+	return []string{JWSAlgorithmES256K}
+}
+
+// ECDSA P-256 key suite
+type ecdsaP256Suite struct{}
+
+func (s *ecdsaP256Suite) KeyType() string {
+	return KeyTypeECDSAP256
+}
+
+func (s *ecdsaP256Suite) GenerateKey() ([]byte, []byte, error) {
+
+	// Generate random P-256 key
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Get corresponding compressed public key
+	publicKey := elliptic.MarshalCompressed(elliptic.P256(), privateKey.X, privateKey.Y)
+
+	return publicKey, privateKey.D.FillBytes(make([]byte, 32)), nil
+
+}
+
+func (s *ecdsaP256Suite) Sign(privateKey []byte, data []byte, opts crypto.SignerOpts) ([]byte, error) {
+
+	err := checkSHA256Digest(data, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	privKey, err := parseP256PrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	r, ss, err := ecdsa.Sign(rand.Reader, privKey, data)
+	if err != nil {
+		return nil, err
+	}
+
+	// low S malleability breaker
+	n := privKey.Curve.Params().N
+	if ss.Cmp(new(big.Int).Rsh(n, 1)) > 0 {
+		ss.Sub(n, ss)
+	}
+
+	return asn1.Marshal(ecdsaSignature{R: r, S: ss})
+
+}
+
+func (s *ecdsaP256Suite) Verify(publicKey []byte, data []byte, signature []byte, opts crypto.SignerOpts) (bool, error) {
+
+	err := checkSHA256Digest(data, opts)
+	if err != nil {
+		return false, err
+	}
+
+	pubKey, err := parseP256PublicKey(publicKey)
+	if err != nil {
+		return false, err
+	}
+
+	return ecdsa.VerifyASN1(pubKey, data, signature), nil
+
+}
+
+// CheckCanonicalSignature accepts strict DER signatures with low S only
+func (s *ecdsaP256Suite) CheckCanonicalSignature(publicKey []byte, signature []byte) error {
+
+	sig := &ecdsaSignature{}
+	rest, err := asn1.Unmarshal(signature, sig)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return fmt.Errorf("Signature is not canonical: trailing data")
+	}
+
+	// encoding/asn1 produces strict DER
+	der, err := asn1.Marshal(*sig)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(der, signature) {
+		return fmt.Errorf("Signature is not canonical: non-strict DER")
+	}
+
+	if sig.S.Sign() <= 0 || sig.S.Cmp(new(big.Int).Rsh(elliptic.P256().Params().N, 1)) > 0 {
+		return fmt.Errorf("Signature is not canonical: high S")
+	}
+
+	return nil
+
+}
+
+func (s *ecdsaP256Suite) EncodePublicKey(publicKey []byte) (string, string, error) {
+	return OnChainPubKeyName, base58.Encode(publicKey), nil
+}
+
+func (s *ecdsaP256Suite) DecodePublicKey(name string, value string) ([]byte, error) {
+	return decodeBase58PublicKey(name, value)
+}
+
+func (s *ecdsaP256Suite) ToJWK(publicKey []byte) (*JWK, error) {
+
+	pubKey, err := parseP256PublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &JWK{Kty: JWKKeyTypeEC, Crv: JWKCurveP256, X: encodeJWKCoordinate(pubKey.X), Y: encodeJWKCoordinate(pubKey.Y)}, nil
+
+}
+
+func (s *ecdsaP256Suite) FromJWK(jwk *JWK) ([]byte, error) {
+
+	x, y, err := decodeJWKCoordinates(jwk, JWKCurveP256)
+	if err != nil {
+		return nil, err
+	}
+
+	if !elliptic.P256().IsOnCurve(x, y) {
+		return nil, fmt.Errorf("JWK point is not on %s curve", JWKCurveP256)
+	}
+
+	return elliptic.MarshalCompressed(elliptic.P256(), x, y), nil
+
+}
+
+func (s *ecdsaP256Suite) Multicodec() uint64 {
+	return MulticodecP256Pub
+}
+
+func (s *ecdsaP256Suite) JWKType() (string, string) {
+	return JWKKeyTypeEC, JWKCurveP256
+}
+
+func (s *ecdsaP256Suite) JWSAlgorithms() []string {
+	return []string{JWSAlgorithmES256}
+}
+
+// Ed25519 key suite
+type ed25519Suite struct{}
+
+func (s *ed25519Suite) KeyType() string {
+	return KeyTypeEdDSA
+}
+
+func (s *ed25519Suite) GenerateKey() ([]byte, []byte, error) {
+	return ed25519.GenerateKey(rand.Reader)
+}
+
+// Sign signs data with Ed25519ph if opts is *ed25519.Options with SHA-512 hash, otherwise data is signed with pure Ed25519
+func (s *ed25519Suite) Sign(privateKey []byte, data []byte, opts crypto.SignerOpts) ([]byte, error) {
+
+	if len(privateKey) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("Invalid Ed25519 private key")
+	}
+
+	if edOpts, ok := opts.(*ed25519.Options); ok {
+		return ed25519.PrivateKey(privateKey).Sign(nil, data, edOpts)
+	}
+
+	return ed25519.Sign(privateKey, data), nil
+
+}
+
+func (s *ed25519Suite) Verify(publicKey []byte, data []byte, signature []byte, opts crypto.SignerOpts) (bool, error) {
+
+	if len(publicKey) != ed25519.PublicKeySize {
+		return false, fmt.Errorf("Invalid Ed25519 public key")
+	}
+
+	if edOpts, ok := opts.(*ed25519.Options); ok {
+		return ed25519.VerifyWithOptions(publicKey, data, signature, edOpts) == nil, nil
+	}
+
+	return ed25519.Verify(publicKey, data, signature), nil
+
+}
+
+// CheckCanonicalSignature rejects non-canonical S (S >= L) and non-canonical encodings of R and public key
+func (s *ed25519Suite) CheckCanonicalSignature(publicKey []byte, signature []byte) error {
+
+	if len(signature) != ed25519.SignatureSize {
+		return fmt.Errorf("Invalid Ed25519 signature length")
+	}
+
+	if !isCanonicalEd25519Point(publicKey) {
+		return fmt.Errorf("Public key is not canonical")
+	}
+
+	if !isCanonicalEd25519Point(signature[:32]) {
+		return fmt.Errorf("Signature is not canonical: non-canonical R")
+	}
+
+	if new(big.Int).SetBytes(reverse(signature[32:])).Cmp(ed25519L) >= 0 {
+		return fmt.Errorf("Signature is not canonical: S >= L")
+	}
+
+	return nil
+
+}
+
+func (s *ed25519Suite) EncodePublicKey(publicKey []byte) (string, string, error) {
+	return OnChainPubKeyName, base58.Encode(publicKey), nil
+}
+
+func (s *ed25519Suite) DecodePublicKey(name string, value string) ([]byte, error) {
+	return decodeBase58PublicKey(name, value)
+}
+
+func (s *ed25519Suite) ToJWK(publicKey []byte) (*JWK, error) {
+	return &JWK{Kty: JWKKeyTypeOKP, Crv: JWKCurveEd25519, X: base64.RawURLEncoding.EncodeToString(publicKey)}, nil
+}
+
+func (s *ed25519Suite) FromJWK(jwk *JWK) ([]byte, error) {
+	return decodeJWKOctetKey(jwk, JWKCurveEd25519, ed25519.PublicKeySize)
+}
+
+func (s *ed25519Suite) Multicodec() uint64 {
+	return MulticodecEd25519Pub
+}
+
+func (s *ed25519Suite) JWKType() (string, string) {
+	return JWKKeyTypeOKP, JWKCurveEd25519
+}
+
+func (s *ed25519Suite) JWSAlgorithms() []string {
+	return []string{JWSAlgorithmEdDSA}
+}
+
+// RSA key suite
+type rsaSuite struct{}
+
+func (s *rsaSuite) KeyType() string {
+	return KeyTypeRSA
+}
+
+func (s *rsaSuite) GenerateKey() ([]byte, []byte, error) {
+	return s.GenerateKeyWithOptions(newKeyOptions(nil))
+}
+
+func (s *rsaSuite) GenerateKeyWithOptions(opts *KeyOptions) ([]byte, []byte, error) {
+
+	err := opts.validateRSA()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Generate random rsa key, crypto/rsa always uses 65537 public exponent
+	privateKey, err := rsa.GenerateKey(rand.Reader, opts.RSABits)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Marshal keys to []byte
+	return x509.MarshalPKCS1PublicKey(&privateKey.PublicKey), x509.MarshalPKCS1PrivateKey(privateKey), nil
+
+}
+
+// Sign signs with RSASSA-PSS if opts is *rsa.PSSOptions, otherwise with PKCS #1 v1.5
+func (s *rsaSuite) Sign(privateKey []byte, data []byte, opts crypto.SignerOpts) ([]byte, error) {
+
+	p, err := x509.ParsePKCS1PrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkSHA256Digest(data, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if pssOpts, ok := opts.(*rsa.PSSOptions); ok {
+		return rsa.SignPSS(rand.Reader, p, crypto.SHA256, data, pssOpts)
+	}
+
+	return rsa.SignPKCS1v15(rand.Reader, p, crypto.SHA256, data)
+
+}
+
+// Verify verifies RSASSA-PSS signature only if opts is *rsa.PSSOptions,
+// otherwise both PKCS #1 v1.5 (legacy) and RSASSA-PSS signatures are accepted
+func (s *rsaSuite) Verify(publicKey []byte, data []byte, signature []byte, opts crypto.SignerOpts) (bool, error) {
+
+	err := checkSHA256Digest(data, opts)
+	if err != nil {
+		return false, err
+	}
+
+	p, err := parseRSAPublicKey(publicKey)
+	if err != nil {
+		return false, err
+	}
+
+	if pssOpts, ok := opts.(*rsa.PSSOptions); ok {
+		err = rsa.VerifyPSS(p, crypto.SHA256, data, signature, pssOpts)
+		if err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	err = rsa.VerifyPKCS1v15(p, crypto.SHA256, data, signature)
+	if err != nil {
+		// fallback to RSASSA-PSS with any salt length
+		err = rsa.VerifyPSS(p, crypto.SHA256, data, signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthAuto})
+		if err != nil {
+			return false, err
+		}
+	}
+
+	return true, nil
+
+}
+
+// CheckCanonicalSignature accepts signatures of modulus size only
+func (s *rsaSuite) CheckCanonicalSignature(publicKey []byte, signature []byte) error {
+
+	p, err := parseRSAPublicKey(publicKey)
+	if err != nil {
+		return err
+	}
+
+	if len(signature) != p.Size() {
+		return fmt.Errorf("Signature is not canonical: length must be %d bytes", p.Size())
+	}
+
+	if new(big.Int).SetBytes(signature).Cmp(p.N) >= 0 {
+		return fmt.Errorf("Signature is not canonical: signature >= modulus")
+	}
+
+	return nil
+
+}
+
+func (s *rsaSuite) EncodePublicKey(publicKey []byte) (string, string, error) {
+
+	_, err := parseRSAPublicKey(publicKey)
+	if err != nil {
+		return "", "", err
+	}
+
+	return OnChainPubKeyPemName, string(pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: publicKey})), nil
+
+}
+
+func (s *rsaSuite) DecodePublicKey(name string, value string) ([]byte, error) {
+
+	if name != OnChainPubKeyPemName {
+		return nil, fmt.Errorf("%s public key must be encoded as %s", KeyTypeRSA, OnChainPubKeyPemName)
+	}
+
+	block, _ := pem.Decode([]byte(value))
+	if block == nil || block.Type != "RSA PUBLIC KEY" {
+		return nil, fmt.Errorf("Invalid %s", OnChainPubKeyPemName)
+	}
+
+	// validate public exponent
+	_, err := parseRSAPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return block.Bytes, nil
+
+}
+
+func (s *rsaSuite) ToJWK(publicKey []byte) (*JWK, error) {
+
+	pubKey, err := parseRSAPublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &JWK{Kty: JWKKeyTypeRSA, N: base64.RawURLEncoding.EncodeToString(pubKey.N.Bytes()), E: base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pubKey.E)).Bytes())}, nil
+
+}
+
+func (s *rsaSuite) FromJWK(jwk *JWK) ([]byte, error) {
+
+	if jwk.Kty != JWKKeyTypeRSA {
+		return nil, fmt.Errorf("JWK kty must be %s", JWKKeyTypeRSA)
+	}
+
+	n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(n) == 0 || len(e) == 0 || len(e) > 4 {
+		return nil, fmt.Errorf("Invalid RSA JWK")
+	}
+
+	publicKey := x509.MarshalPKCS1PublicKey(&rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())})
+
+	// validate public exponent
+	_, err = parseRSAPublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return publicKey, nil
+
+}
+
+func (s *rsaSuite) Multicodec() uint64 {
+	return MulticodecRSAPub
+}
+
+func (s *rsaSuite) JWKType() (string, string) {
+	return JWKKeyTypeRSA, ""
+}
+
+// JWSAlgorithms returns RS256 and PS256, keys with AbstractKey.RSAPSS sign with PS256
+func (s *rsaSuite) JWSAlgorithms() []string {
+	return []string{JWSAlgorithmRS256, JWSAlgorithmPS256}
+}
+
+// X25519 key agreement suite
+type x25519Suite struct{}
+
+func (s *x25519Suite) KeyType() string {
+	return KeyTypeX25519
+}
+
+func (s *x25519Suite) GenerateKey() ([]byte, []byte, error) {
+
+	// Generate random x25519 key
+	privateKey := make([]byte, curve25519.ScalarSize)
+	_, err := rand.Read(privateKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Get corresponding public key
+	publicKey, err := curve25519.X25519(privateKey, curve25519.Basepoint)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return publicKey, privateKey, nil
+
+}
+
+func (s *x25519Suite) Sign(privateKey []byte, data []byte, opts crypto.SignerOpts) ([]byte, error) {
+	return nil, fmt.Errorf("%s can't be used for signing", KeyTypeX25519)
+}
+
+func (s *x25519Suite) Verify(publicKey []byte, data []byte, signature []byte, opts crypto.SignerOpts) (bool, error) {
+	return false, fmt.Errorf("%s can't be used for signing", KeyTypeX25519)
+}
+
+func (s *x25519Suite) EncodePublicKey(publicKey []byte) (string, string, error) {
+	return OnChainPubKeyName, base58.Encode(publicKey), nil
+}
+
+func (s *x25519Suite) DecodePublicKey(name string, value string) ([]byte, error) {
+	return decodeBase58PublicKey(name, value)
+}
+
+func (s *x25519Suite) ToJWK(publicKey []byte) (*JWK, error) {
+	return &JWK{Kty: JWKKeyTypeOKP, Crv: JWKCurveX25519, X: base64.RawURLEncoding.EncodeToString(publicKey)}, nil
+}
+
+func (s *x25519Suite) FromJWK(jwk *JWK) ([]byte, error) {
+	return decodeJWKOctetKey(jwk, JWKCurveX25519, curve25519.PointSize)
+}
+
+func (s *x25519Suite) Multicodec() uint64 {
+	return MulticodecX25519Pub
+}
+
+func (s *x25519Suite) JWKType() (string, string) {
+	return JWKKeyTypeOKP, JWKCurveX25519
+}
+
+func (s *x25519Suite) JWSAlgorithms() []string {
+	return nil
+}
+
+func (s *x25519Suite) SharedSecret(privateKey []byte, peerPublicKey []byte) ([]byte, error) {
+	return curve25519.X25519(privateKey, peerPublicKey)
+}
+
+// ECDSA signature ASN.1 structure
+type ecdsaSignature struct {
+	R, S *big.Int
+}
+
+// Ed25519 group order 2^252 + 27742317777372353535851937790883648493
+var ed25519L, _ = new(big.Int).SetString("1000000000000000000000000000000014def9dea2f79cd65812631a5cf5d3ed", 16)
+
+// helper function that checks that encoded Ed25519 point has y < p
+func isCanonicalEd25519Point(b []byte) bool {
+
+	if len(b) != 32 {
+		return false
+	}
+
+	y := reverse(b)
+	y[0] &= 0x7f
+
+	return new(big.Int).SetBytes(y).Cmp(curve25519P) < 0
+
+}
+
+// helper function that returns reversed copy of little-endian bytes
+func reverse(b []byte) []byte {
+
+	r := make([]byte, len(b))
+	for i := range b {
+		r[len(b)-1-i] = b[i]
+	}
+
+	return r
+
+}
+
+// helper function that decodes Base58 on-chain public key
+func decodeBase58PublicKey(name string, value string) ([]byte, error) {
+
+	if name != OnChainPubKeyName {
+		return nil, fmt.Errorf("Public key must be encoded as %s", OnChainPubKeyName)
+	}
+
+	publicKey := base58.Decode(value)
+	if len(publicKey) == 0 {
+		return nil, fmt.Errorf("Invalid %s", OnChainPubKeyName)
+	}
+
+	return publicKey, nil
+
+}
+
+// helper function that parses PKCS #1 RSA public key and validates its public exponent
+func parseRSAPublicKey(publicKey []byte) (*rsa.PublicKey, error) {
+
+	p, err := x509.ParsePKCS1PublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validateRSAPublicExponent(p.E)
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+
+}
+
+// helper function that checks that ECDSA and RSA suites sign SHA-256 digest
+func checkSHA256Digest(data []byte, opts crypto.SignerOpts) error {
+
+	if opts == nil || opts.HashFunc() != crypto.SHA256 || len(data) != crypto.SHA256.Size() {
+		return fmt.Errorf("Only SHA-256 digests can be signed with this key type")
+	}
+
+	return nil
+
+}
+
+// helper function that parses 32 bytes P-256 private scalar
+func parseP256PrivateKey(b []byte) (*ecdsa.PrivateKey, error) {
+
+	curve := elliptic.P256()
+
+	d := new(big.Int).SetBytes(b)
+	if len(b) != 32 || d.Sign() == 0 || d.Cmp(curve.Params().N) >= 0 {
+		return nil, fmt.Errorf("Invalid P-256 private key")
+	}
+
+	privKey := &ecdsa.PrivateKey{D: d}
+	privKey.Curve = curve
+	privKey.X, privKey.Y = curve.ScalarBaseMult(b)
+
+	return privKey, nil
+
+}
+
+// helper function that parses compressed or uncompressed P-256 public key
+func parseP256PublicKey(b []byte) (*ecdsa.PublicKey, error) {
+
+	curve := elliptic.P256()
+
+	x, y := elliptic.UnmarshalCompressed(curve, b)
+	if x == nil {
+		x, y = elliptic.Unmarshal(curve, b)
+	}
+	if x == nil {
+		return nil, fmt.Errorf("Invalid P-256 public key")
+	}
+
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+
+}
@@ -0,0 +1,162 @@
+package factomdid
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/FactomProject/btcutil/base58"
+)
+
+// Linked Data proofs use JCS (RFC 8785) canonicalization instead of URDNA2015, so they are published under
+// JCS suite types instead of Ed25519Signature2020 and EcdsaSecp256k1Signature2019. Signed data is
+// SHA-256(canonical proof options) || SHA-256(canonical document without proof), where proof options are
+// the proof without proofValue. proofValue is base58btc multibase of the signature: Ed25519 signature
+// of the data for JcsEd25519Signature2020 and R || S ECDSA signature of SHA-256 of the data for
+// JcsEcdsaSecp256k1Signature2019
+
+// helper function that returns key type of Linked Data proof type
+func linkedDataProofKeyType(proofType string) (string, error) {
+
+	switch proofType {
+	case ProofTypeJcsEd25519Signature2020:
+		return KeyTypeEdDSA, nil
+	case ProofTypeJcsEcdsaSecp256k1Signature2019:
+		return KeyTypeECDSA, nil
+	}
+
+	return "", fmt.Errorf("Unsupported Linked Data proof type %s", proofType)
+
+}
+
+// helper function that signs Linked Data proof and sets proof.ProofValue
+func (didkey *DIDKey) signLinkedDataProof(proof *Proof, marshal func(*Proof) ([]byte, error)) error {
+
+	keyType, err := linkedDataProofKeyType(proof.Type)
+	if err != nil {
+		return err
+	}
+
+	if didkey.KeyType != keyType {
+		return fmt.Errorf("%s proof requires %s DIDKey", proof.Type, keyType)
+	}
+
+	data, err := linkedDataProofSigningInput(proof, marshal)
+	if err != nil {
+		return err
+	}
+
+	var signature []byte
+
+	switch proof.Type {
+	case ProofTypeJcsEd25519Signature2020:
+		signature, err = didkey.SignWithMode(data, SignatureModePureEd25519)
+	case ProofTypeJcsEcdsaSecp256k1Signature2019:
+		signature, err = didkey.SignWithMode(data, SignatureModePrehashed)
+		if err == nil {
+			signature, err = derToJWSSignature(signature)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	proof.ProofValue = MultibaseBase58BTC + base58.Encode(signature)
+
+	return nil
+
+}
+
+// helper function that verifies Linked Data proof, the verification method must have purpose
+func verifyLinkedDataProof(proof *Proof, purpose string, resolver Resolver, marshal func(*Proof) ([]byte, error)) (*DIDKey, *DID, error) {
+
+	keyType, err := linkedDataProofKeyType(proof.Type)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(proof.ProofValue) < 2 || proof.ProofValue[:1] != MultibaseBase58BTC {
+		return nil, nil, fmt.Errorf("Invalid proofValue, must be base58btc (z) multibase")
+	}
+
+	signature := base58.Decode(proof.ProofValue[1:])
+	if len(signature) == 0 {
+		return nil, nil, fmt.Errorf("Invalid proofValue")
+	}
+
+	if resolver == nil {
+		return nil, nil, fmt.Errorf("Resolver is empty")
+	}
+
+	id, _, err := SplitDIDURL(proof.VerificationMethod)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	did, err := resolver.Resolve(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if did == nil {
+		return nil, nil, fmt.Errorf("DID %s not found", id)
+	}
+
+	key, err := ResolveDIDKey(did, proof.VerificationMethod, purpose)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if key.KeyType != keyType {
+		return nil, nil, fmt.Errorf("%s proof can't be verified with %s key", proof.Type, key.KeyType)
+	}
+
+	unsigned := *proof
+	unsigned.ProofValue = ""
+	data, err := linkedDataProofSigningInput(&unsigned, marshal)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var valid bool
+
+	switch proof.Type {
+	case ProofTypeJcsEd25519Signature2020:
+		valid, err = key.VerifyWithMode(data, signature, SignatureModePureEd25519)
+	case ProofTypeJcsEcdsaSecp256k1Signature2019:
+		var der []byte
+		der, err = jwsSignatureToDER(signature)
+		if err == nil {
+			valid, err = key.VerifyWithMode(data, der, SignatureModePrehashed)
+		}
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !valid {
+		return nil, nil, fmt.Errorf("Invalid proof signature")
+	}
+
+	return key, did, nil
+
+}
+
+// helper function that computes SHA-256(canonical proof options) || SHA-256(canonical document without proof)
+func linkedDataProofSigningInput(proof *Proof, marshal func(*Proof) ([]byte, error)) ([]byte, error) {
+
+	options, err := MarshalCanonicalJSON(proof)
+	if err != nil {
+		return nil, err
+	}
+
+	document, err := marshal(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	optionsHash := sha256.Sum256(options)
+	documentHash := sha256.Sum256(document)
+
+	return append(optionsHash[:], documentHash[:]...), nil
+
+}
@@ -0,0 +1,97 @@
+package factomdid
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLinkedDataProof(t *testing.T) {
+
+	did := NewDID()
+	eddsa, _ := NewDIDKey("eddsa", KeyTypeEdDSA)
+	ecdsa, _ := NewDIDKey("ecdsa", KeyTypeECDSA)
+	proofTypes := map[*DIDKey]string{eddsa: ProofTypeJcsEd25519Signature2020, ecdsa: ProofTypeJcsEcdsaSecp256k1Signature2019}
+
+	for key := range proofTypes {
+		key.AddPurpose(KeyPurposePublic)
+		key.AddPurpose(KeyPurposeAuthentication)
+		did.AddDIDKey(key)
+	}
+
+	vc, _ := NewCredential(did.ID, "KYCCredential", map[string]interface{}{"id": did.ID, "kyc": true})
+
+	for key, proofType := range proofTypes {
+
+		signed, err := key.IssueCredential(vc, WithProofType(proofType))
+		assert.NoError(t, err)
+		assert.Equal(t, proofType, signed.Proof.Type)
+		assert.Equal(t, did.ID+"#"+key.Alias, signed.Proof.VerificationMethod)
+		assert.True(t, strings.HasPrefix(signed.Proof.ProofValue, MultibaseBase58BTC))
+		assert.Empty(t, signed.Proof.JWS)
+
+		// verify credential transferred as JSON
+		data, _ := json.Marshal(signed)
+		received := &VerifiableCredential{}
+		json.Unmarshal(data, received)
+
+		issuerKey, err := VerifyCredential(received, did.Public())
+		assert.NoError(t, err, proofType)
+		assert.Equal(t, key.Alias, issuerKey.Alias)
+
+		// tampered document
+		received.CredentialSubject["kyc"] = false
+		_, err = VerifyCredential(received, did)
+		assert.Error(t, err)
+
+		// tampered proof options
+		tampered := *signed
+		proof := *signed.Proof
+		proof.Created = "2020-01-01T00:00:00Z"
+		tampered.Proof = &proof
+		_, err = VerifyCredential(&tampered, did)
+		assert.Error(t, err)
+
+		// presentation with Linked Data proof, challenge is signed
+		vp, _ := NewPresentation(did.ID, signed)
+		signedVP, err := key.SignPresentation(vp, "challenge", "example.com", WithProofType(proofType))
+		assert.NoError(t, err)
+		_, err = VerifyPresentation(signedVP, did, "challenge", "example.com")
+		assert.NoError(t, err)
+		signedVP.Proof.Challenge = "another-challenge"
+		_, err = VerifyPresentation(signedVP, did, "another-challenge", "example.com")
+		assert.Error(t, err)
+
+	}
+
+	// proof type must match key type
+	_, err := eddsa.IssueCredential(vc, WithProofType(ProofTypeJcsEcdsaSecp256k1Signature2019))
+	assert.Error(t, err)
+	_, err = ecdsa.IssueCredential(vc, WithProofType(ProofTypeJcsEd25519Signature2020))
+	assert.Error(t, err)
+
+	// proof type must match verification method key type
+	signed, _ := eddsa.IssueCredential(vc, WithProofType(ProofTypeJcsEd25519Signature2020))
+	signed.Proof.Type = ProofTypeJcsEcdsaSecp256k1Signature2019
+	_, err = VerifyCredential(signed, did)
+	assert.Error(t, err)
+
+	// proofPurpose is checked against DIDKey purposes
+	authKey, _ := NewDIDKey("auth-key", KeyTypeEdDSA)
+	authKey.AddPurpose(KeyPurposeAuthentication)
+	did.AddDIDKey(authKey)
+	_, err = authKey.IssueCredential(vc, WithProofType(ProofTypeJcsEd25519Signature2020))
+	assert.Error(t, err)
+
+	signed, _ = eddsa.IssueCredential(vc, WithProofType(ProofTypeJcsEd25519Signature2020))
+	signed.Proof.VerificationMethod = did.ID + "#auth-key"
+	_, err = VerifyCredential(signed, did)
+	assert.Error(t, err)
+
+	// unsupported proof type
+	_, err = eddsa.IssueCredential(vc, WithProofType("RsaSignature2018"))
+	assert.Error(t, err)
+
+}
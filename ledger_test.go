@@ -0,0 +1,353 @@
+package factomdid_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/FactomProject/factom"
+	"github.com/stretchr/testify/assert"
+
+	factomdid "github.com/DeFacto-Team/go-factom-did"
+	"github.com/DeFacto-Team/go-factom-did/factomdidtest"
+)
+
+// otherChainPublisher publishes entries to another chain
+type otherChainPublisher struct {
+	*factomdidtest.Ledger
+}
+
+func (p *otherChainPublisher) PublishEntry(entry *factom.Entry) (*factomdid.PublishResult, error) {
+
+	e := *entry
+	e.ChainID = "301a57c2e753d061928cf6b6a692ea052885d75d2af5640e9b5cbc8897bbf7d5"
+
+	return &factomdid.PublishResult{ChainID: e.ChainID, EntryHash: hex.EncodeToString(e.Hash())}, nil
+
+}
+
+func TestLedgerPublish(t *testing.T) {
+
+	ledger := factomdidtest.NewLedger()
+	did, _ := factomdid.AddTestKeys(t, factomdid.NewDID(), "default-did-key", factomdid.KeyTypeEdDSA, factomdid.KeyPurposePublic)
+
+	// DID chain doesn't exist yet
+	_, err := did.DeactivateAndPublish("default-mgmt-key", ledger)
+	assert.Error(t, err)
+
+	result, err := did.CreateAndPublish(ledger)
+	assert.NoError(t, err)
+	assert.Equal(t, did.GetChainID(), result.ChainID)
+
+	// chain can't be created twice
+	_, err = did.CreateAndPublish(ledger)
+	assert.Error(t, err)
+
+	updatedDID := did.Copy()
+	service, _ := factomdid.NewService("demo", "Demo", "https://demo.com")
+	updatedDID.AddService(service)
+
+	_, err = did.UpdateAndPublish(updatedDID, "default-mgmt-key", ledger)
+	assert.NoError(t, err)
+	_, err = updatedDID.UpgradeVersionAndPublish("0.3.0", "default-mgmt-key", ledger)
+	assert.NoError(t, err)
+	result, err = updatedDID.DeactivateAndPublish("default-mgmt-key", ledger)
+	assert.NoError(t, err)
+
+	entries, err := ledger.FetchChainEntries(did.GetChainID())
+	assert.NoError(t, err)
+	assert.Equal(t, 4, len(entries))
+	assert.Equal(t, []byte(factomdid.EntryTypeCreate), entries[0].Entry.ExtIDs[0])
+	assert.Equal(t, []byte(factomdid.EntryTypeUpdate), entries[1].Entry.ExtIDs[0])
+	assert.Equal(t, []byte(factomdid.EntryTypeVersionUpgrade), entries[2].Entry.ExtIDs[0])
+	assert.Equal(t, []byte(factomdid.EntryTypeDeactivation), entries[3].Entry.ExtIDs[0])
+	assert.Equal(t, result.EntryHash, hex.EncodeToString(entries[3].Entry.Hash()))
+
+	// entry published to another chain
+	_, err = did.DeactivateAndPublish("default-mgmt-key", &otherChainPublisher{ledger})
+	assert.Error(t, err)
+
+	// ExtIDs that don't match DID ChainID
+	did.ExtIDs[2] = []byte("other-nonce")
+	_, err = did.CreateAndPublish(ledger)
+	assert.Error(t, err)
+
+}
+
+func TestLedgerChainResolver(t *testing.T) {
+
+	ledger := factomdidtest.NewLedger()
+	resolver := factomdid.NewChainResolver(ledger, factomdid.NetworkMainnet)
+
+	// P-256 and key agreement keys require entry schema 1.0.0+factomdid-ext
+	did, err := factomdid.NewDIDFromReader(rand.Reader, factomdid.WithEntrySchema(factomdid.EntrySchemaV100Ext))
+	assert.NoError(t, err)
+	didKey, _ := factomdid.NewDIDKey("did-key", factomdid.KeyTypeECDSAP256)
+	didKey.AddPurpose(factomdid.KeyPurposePublic)
+	didKey.AddPurpose(factomdid.KeyPurposeAuthentication)
+	agreementKey, _ := factomdid.NewKeyAgreementKey("agreement-key")
+	mgmtKey, _ := factomdid.NewManagementKey("mgmt-key", factomdid.KeyTypeRSA, 0)
+	mgmtKey.SetPriorityRequirement(0)
+	service, _ := factomdid.NewService("service", "Demo", "https://demo.com")
+	service.SetPriorityRequirement(1)
+
+	did.AddDIDKey(didKey)
+	did.AddDIDKey(agreementKey)
+	did.AddManagementKey(mgmtKey)
+	did.AddService(service)
+
+	_, err = did.CreateAndPublish(ledger)
+	assert.NoError(t, err)
+	created := ledger.Time()
+	ledger.NextBlock()
+
+	// on-chain DID document is the same as public DID document
+	resolved, err := resolver.Resolve(did.ID)
+	assert.NoError(t, err)
+	public := did.Public()
+	public.Network = factomdid.NetworkMainnet
+	assert.Equal(t, public, resolved)
+
+	_, err = did.UpgradeVersionAndPublish("0.3.0", "mgmt-key", ledger)
+	assert.NoError(t, err)
+
+	// DID document before version upgrade
+	resolved, err = resolver.ResolveAt(did.ID, created)
+	assert.NoError(t, err)
+	assert.Equal(t, public, resolved)
+
+	// resolver without fetcher
+	_, err = factomdid.NewChainResolver(nil, factomdid.NetworkUnspecified).Resolve(did.ID)
+	assert.Error(t, err)
+
+}
+
+func TestLedgerSchemaValidation(t *testing.T) {
+
+	ledger := factomdidtest.NewLedger()
+	resolver := factomdid.NewChainResolver(ledger, factomdid.NetworkUnspecified)
+
+	did, _ := factomdid.AddTestKeys(t, factomdid.NewDID(), "default-did-key", factomdid.KeyTypeEdDSA, factomdid.KeyPurposePublic)
+	_, err := did.CreateAndPublish(ledger)
+	assert.NoError(t, err)
+
+	// correctly signed update, which content doesn't match the schema, is skipped
+	content := []byte(`{"Add":{"service":[{"id":"` + did.ID + `#service","type":"Demo","serviceEndpoint":"https://demo.com"}]}}`)
+	keyID := did.ID + "#default-mgmt-key"
+	signature, err := did.ManagementKeys[0].SignWithMode([]byte(factomdid.EntryTypeUpdate+factomdid.EntrySchemaV100+keyID+string(content)), factomdid.SignatureModePrehashed)
+	assert.NoError(t, err)
+	fe := &factom.Entry{ChainID: did.GetChainID(), Content: content}
+	fe.ExtIDs = [][]byte{[]byte(factomdid.EntryTypeUpdate), []byte(factomdid.EntrySchemaV100), []byte(keyID), signature}
+	_, err = ledger.PublishEntry(fe)
+	assert.NoError(t, err)
+
+	resolved, err := resolver.Resolve(did.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(resolved.Services))
+
+	// the same update with lowercase keys is applied
+	fe.Content = []byte(strings.Replace(string(content), "Add", "add", 1))
+	fe.ExtIDs[3], err = did.ManagementKeys[0].SignWithMode([]byte(factomdid.EntryTypeUpdate+factomdid.EntrySchemaV100+keyID+string(fe.Content)), factomdid.SignatureModePrehashed)
+	assert.NoError(t, err)
+	_, err = ledger.PublishEntry(fe)
+	assert.NoError(t, err)
+
+	resolved, err = resolver.Resolve(did.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(resolved.Services))
+
+}
+
+func TestLedgerEntrySchemaVersions(t *testing.T) {
+
+	decodedEntries := factomdid.RegisterTestEntrySchema(t)
+	ledger := factomdidtest.NewLedger()
+	resolver := factomdid.NewChainResolver(ledger, factomdid.NetworkUnspecified)
+
+	// DID created with entry schema 1.0.0
+	did, _ := factomdid.AddTestKeys(t, factomdid.NewDID(), "default-did-key", factomdid.KeyTypeEdDSA, factomdid.KeyPurposePublic)
+	assert.Equal(t, factomdid.EntrySchemaV100, string(did.ExtIDs[1]))
+	_, err := did.Create(factomdid.WithEntrySchema(factomdid.TestEntrySchemaVersion))
+	assert.Error(t, err)
+	_, err = did.CreateAndPublish(ledger, factomdid.WithEntrySchema(factomdid.EntrySchemaV100))
+	assert.NoError(t, err)
+
+	// update written with newer entry schema
+	updated := did.Copy()
+	key, _ := factomdid.NewDIDKey("new-key", factomdid.KeyTypeEdDSA)
+	key.AddPurpose(factomdid.KeyPurposePublic)
+	updated.AddDIDKey(key)
+	fe, err := did.Update(updated, "default-mgmt-key", factomdid.WithEntrySchema(factomdid.TestEntrySchemaVersion))
+	assert.NoError(t, err)
+	assert.Equal(t, factomdid.TestEntrySchemaVersion, string(fe.ExtIDs[1]))
+	_, err = ledger.PublishEntry(fe)
+	assert.NoError(t, err)
+
+	decoded := decodedEntries()
+	resolved, err := resolver.Resolve(did.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(resolved.DIDKeys))
+	assert.Equal(t, decoded+1, decodedEntries())
+
+	// entries of unknown entry schema are not generated and are ignored by resolver
+	_, err = updated.Deactivate("default-mgmt-key", factomdid.WithEntrySchema("9.9.9"))
+	assert.Error(t, err)
+	_, err = updated.UpgradeVersion("0.3.0", "default-mgmt-key", factomdid.WithEntrySchema("9.9.9"))
+	assert.Error(t, err)
+	fe, err = updated.Deactivate("default-mgmt-key")
+	assert.NoError(t, err)
+	fe.ExtIDs[1] = []byte("9.9.9")
+	_, err = ledger.PublishEntry(fe)
+	assert.NoError(t, err)
+	_, err = resolver.Resolve(did.ID)
+	assert.NoError(t, err)
+
+	// DID created with newer entry schema, which is a part of its chain ID
+	nonce := bytes.Repeat([]byte{1}, factomdid.NonceSize)
+	did, err = factomdid.NewDIDWithNonce(nonce, factomdid.WithEntrySchema(factomdid.TestEntrySchemaVersion))
+	assert.NoError(t, err)
+	assert.Equal(t, factomdid.TestEntrySchemaVersion, string(did.ExtIDs[1]))
+	assert.Equal(t, factom.ChainIDFromFields(did.ExtIDs), did.GetChainID())
+	old, _ := factomdid.NewDIDWithNonce(nonce)
+	assert.NotEqual(t, old.GetChainID(), did.GetChainID())
+
+	didKey, _ := factomdid.NewDIDKey("default-did-key", factomdid.KeyTypeEdDSA)
+	didKey.AddPurpose(factomdid.KeyPurposePublic)
+	mgmtKey, _ := factomdid.NewManagementKey("default-mgmt-key", factomdid.KeyTypeEdDSA, 0)
+	did.AddDIDKey(didKey)
+	did.AddManagementKey(mgmtKey)
+	_, err = did.CreateAndPublish(ledger)
+	assert.NoError(t, err)
+
+	resolved, err = resolver.Resolve(did.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, factomdid.TestEntrySchemaVersion, string(resolved.ExtIDs[1]))
+
+	_, err = factomdid.NewDIDWithNonce(nonce, factomdid.WithEntrySchema("9.9.9"))
+	assert.Error(t, err)
+
+}
+
+func TestLedgerEntrySchemaV100Ext(t *testing.T) {
+
+	ledger := factomdidtest.NewLedger()
+	resolver := factomdid.NewChainResolver(ledger, factomdid.NetworkUnspecified)
+
+	did, _ := factomdid.AddTestKeys(t, factomdid.NewDID(), "default-did-key", factomdid.KeyTypeEdDSA, factomdid.KeyPurposePublic)
+	_, err := did.CreateAndPublish(ledger)
+	assert.NoError(t, err)
+
+	// P-256 key can't be added with entry schema 1.0.0
+	updated := did.Copy()
+	key, _ := factomdid.NewDIDKey("p256-key", factomdid.KeyTypeECDSAP256)
+	key.AddPurpose(factomdid.KeyPurposePublic)
+	updated.AddDIDKey(key)
+	_, err = did.Update(updated, "default-mgmt-key")
+	assert.Equal(t, []string{"/add/didKey/0/type"}, factomdid.SchemaErrorPaths(t, err))
+
+	fe, err := did.Update(updated, "default-mgmt-key", factomdid.WithEntrySchema(factomdid.EntrySchemaV100Ext))
+	assert.NoError(t, err)
+
+	// the same content signed as entry schema 1.0.0 entry is ignored by resolver
+	keyID := did.ID + "#default-mgmt-key"
+	signature, err := did.ManagementKeys[0].SignWithMode([]byte(factomdid.EntryTypeUpdate+factomdid.EntrySchemaV100+keyID+string(fe.Content)), factomdid.SignatureModePrehashed)
+	assert.NoError(t, err)
+	v100 := &factom.Entry{ChainID: fe.ChainID, Content: fe.Content}
+	v100.ExtIDs = [][]byte{[]byte(factomdid.EntryTypeUpdate), []byte(factomdid.EntrySchemaV100), []byte(keyID), signature}
+	_, err = ledger.PublishEntry(v100)
+	assert.NoError(t, err)
+
+	resolved, err := resolver.Resolve(did.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(resolved.DIDKeys))
+
+	_, err = ledger.PublishEntry(fe)
+	assert.NoError(t, err)
+
+	resolved, err = resolver.Resolve(did.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(resolved.DIDKeys))
+	assert.Equal(t, factomdid.KeyTypeECDSAP256, resolved.DIDKeys[1].KeyType)
+
+}
+
+func TestLedgerServiceCustomFields(t *testing.T) {
+
+	ledger := factomdidtest.NewLedger()
+	resolver := factomdid.NewChainResolver(ledger, factomdid.NetworkUnspecified)
+
+	did, _ := factomdid.AddTestKeys(t, factomdid.NewDID(), "default-did-key", factomdid.KeyTypeEdDSA, factomdid.KeyPurposePublic)
+	service, err := factomdid.NewDIDCommService("didcomm", "https://agent.example.com", []string{"did:example:mediator#key-1"}, []string{factomdid.DIDCommProfileV2})
+	assert.NoError(t, err)
+	did.AddService(service)
+	_, err = did.CreateAndPublish(ledger)
+	assert.NoError(t, err)
+
+	// service with custom fields added by DIDUpdate
+	updated := did.Copy()
+	hub, _ := factomdid.NewService("hub", "IdentityHub", "https://hub.example.com")
+	hub.SetCustomFields(map[string]interface{}{"settings": map[string]interface{}{"region": "eu"}})
+	updated.AddService(hub)
+	fe, err := did.Update(updated, "default-mgmt-key")
+	assert.NoError(t, err)
+	assert.NoError(t, factomdid.ValidateEntry(fe))
+	_, err = ledger.PublishEntry(fe)
+	assert.NoError(t, err)
+
+	resolved, err := resolver.Resolve(did.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(resolved.Services))
+	assert.JSONEq(t, `{"accept":["didcomm/v2"],"routingKeys":["did:example:mediator#key-1"]}`, string(resolved.Services[0].CustomField))
+	assert.JSONEq(t, `{"settings":{"region":"eu"}}`, string(resolved.Services[1].CustomField))
+
+}
+
+func TestLedgerServiceEndpoints(t *testing.T) {
+
+	ledger := factomdidtest.NewLedger()
+	resolver := factomdid.NewChainResolver(ledger, factomdid.NetworkUnspecified)
+
+	did, _ := factomdid.AddTestKeys(t, factomdid.NewDID(), "default-did-key", factomdid.KeyTypeEdDSA, factomdid.KeyPurposePublic)
+	_, err := did.CreateAndPublish(ledger)
+	assert.NoError(t, err)
+
+	// service endpoint map and array added by DIDUpdate
+	updated := did.Copy()
+	hub, err := factomdid.NewService("hub", "IdentityHub", map[string]interface{}{"origins": []string{"https://hub.example.com"}, "region": "eu"})
+	assert.NoError(t, err)
+	didcomm, err := factomdid.NewService("didcomm", factomdid.ServiceTypeDIDCommMessaging, []interface{}{
+		"https://a.example.com",
+		map[string]interface{}{"uri": "https://b.example.com", "accept": []string{factomdid.DIDCommProfileV2}},
+	})
+	assert.NoError(t, err)
+	updated.AddService(hub)
+	updated.AddService(didcomm)
+
+	// entry schema 1.0.0 requires URI endpoint
+	_, err = did.Update(updated, "default-mgmt-key")
+	assert.Equal(t, []string{"/add/service/0/serviceEndpoint", "/add/service/1/serviceEndpoint"}, factomdid.SchemaErrorPaths(t, err))
+
+	_, err = did.UpdateAndPublish(updated, "default-mgmt-key", ledger, factomdid.WithEntrySchema(factomdid.EntrySchemaV100Ext))
+	assert.NoError(t, err)
+
+	resolved, err := resolver.Resolve(did.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(resolved.Services))
+	assert.Equal(t, hub, resolved.Services[0])
+	assert.Equal(t, didcomm, resolved.Services[1])
+
+	endpoints, err := resolved.DIDCommEndpoints()
+	assert.NoError(t, err)
+	assert.Equal(t, []*factomdid.W3CServiceEndpoint{
+		{URI: "https://a.example.com"},
+		{URI: "https://b.example.com", Accept: []string{factomdid.DIDCommProfileV2}},
+	}, endpoints)
+
+	doc, err := resolved.ToW3C()
+	assert.NoError(t, err)
+	assert.Equal(t, hub.Endpoint, doc.Service[0].ServiceEndpoint)
+	assert.Equal(t, didcomm.Endpoint, doc.Service[1].ServiceEndpoint)
+
+}
@@ -1,10 +1,8 @@
 package factomdid
 
 import (
-	"encoding/pem"
+	"fmt"
 	"strings"
-
-	"github.com/FactomProject/btcutil/base58"
 )
 
 // ManagementKey is a key used to sign updates for an existing DID
@@ -13,8 +11,9 @@ type ManagementKey struct {
 	Priority int `json:"priority" form:"priority" query:"priority" validate:"min=0"`
 }
 
-// NewManagementKey generates new ManagementKey with alias and keyType
-func NewManagementKey(alias string, keyType string, priority int) (*ManagementKey, error) {
+// NewManagementKey generates new ManagementKey with alias and keyType.
+// Key generation can be configured with KeyOption, e.g. WithRSABits(4096)
+func NewManagementKey(alias string, keyType string, priority int, opts ...KeyOption) (*ManagementKey, error) {
 
 	key := &ManagementKey{}
 	key.Alias = alias
@@ -31,18 +30,52 @@ func NewManagementKey(alias string, keyType string, priority int) (*ManagementKe
 	if err != nil {
 		return nil, err
 	}
+	// key agreement keys can't sign updates
+	err = key.checkKeyType()
+	if err != nil {
+		return nil, err
+	}
 
-	key.generateRandomKeys()
+	err = key.generateRandomKeys(opts...)
+	if err != nil {
+		return nil, err
+	}
 
 	return key, nil
 
 }
 
+// helper function that checks that ManagementKey is able to sign
+func (mgmtkey *ManagementKey) checkKeyType() error {
+
+	if isKeyAgreementKeyType(mgmtkey.KeyType) {
+		return fmt.Errorf("%s can't be used as ManagementKey", mgmtkey.KeyType)
+	}
+
+	return nil
+
+}
+
+// Public returns a copy of ManagementKey without PrivateKey
+func (mgmtkey *ManagementKey) Public() *ManagementKey {
+
+	public := *mgmtkey
+	public.AbstractKey = mgmtkey.AbstractKey.public()
+
+	return &public
+
+}
+
 // helper function to convert ManagementKey into ManagementKeySchema
 func (mgmtkey *ManagementKey) toSchema(DID string) (*ManagementKeySchema, error) {
 
-	// validate ManagementKey
-	err := validate.Struct(mgmtkey)
+	// validate ManagementKey, PrivateKey is not written on-chain
+	err := validate.StructExcept(mgmtkey, "AbstractKey.PrivateKey")
+	if err != nil {
+		return nil, err
+	}
+
+	err = mgmtkey.checkKeyType()
 	if err != nil {
 		return nil, err
 	}
@@ -54,10 +87,14 @@ func (mgmtkey *ManagementKey) toSchema(DID string) (*ManagementKeySchema, error)
 	s.PriorityRequirement = mgmtkey.PriorityRequirement
 	s.Type = mgmtkey.KeyType
 
-	if mgmtkey.KeyType == KeyTypeRSA {
-		s.PublicKeyPem = string(pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: mgmtkey.PublicKey}))
-	} else {
-		s.PublicKeyBase58 = base58.Encode(mgmtkey.PublicKey)
+	suite, err := GetKeySuite(mgmtkey.KeyType)
+	if err != nil {
+		return nil, err
+	}
+
+	err = setOnChainPublicKey(suite, mgmtkey.PublicKey, &s.PublicKeyBase58, &s.PublicKeyPem)
+	if err != nil {
+		return nil, err
 	}
 
 	return s, nil
@@ -79,3 +116,42 @@ func (mgmtkey *ManagementKey) toRevokeIDSchema(DID string) (*RevokeIDSchema, err
 	return s, nil
 
 }
+
+// helper function to convert on-chain ManagementKeySchema of DID into public ManagementKey
+func managementKeyFromSchema(s *ManagementKeySchema, DID string) (*ManagementKey, error) {
+
+	alias, err := schemaIDAlias(s.ID, DID)
+	if err != nil {
+		return nil, err
+	}
+
+	key := &ManagementKey{}
+	key.Alias = alias
+	key.KeyType = s.Type
+	key.Controller = s.Controller
+	key.Priority = s.Priority
+	key.PriorityRequirement = s.PriorityRequirement
+
+	suite, err := GetKeySuite(s.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	key.PublicKey, err = getOnChainPublicKey(suite, s.PublicKeyBase58, s.PublicKeyPem)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validate.StructExcept(key, "AbstractKey.PrivateKey")
+	if err != nil {
+		return nil, err
+	}
+
+	err = key.checkKeyType()
+	if err != nil {
+		return nil, err
+	}
+
+	return key, nil
+
+}
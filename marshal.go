@@ -0,0 +1,101 @@
+package factomdid
+
+import (
+	"encoding/json"
+)
+
+// MarshalOption configures DID.Marshal
+type MarshalOption func(*marshalOptions)
+
+type marshalOptions struct {
+	privateKeys bool
+	prefix      string
+	indent      string
+}
+
+// WithPrivateKeys includes private keys into marshalled DID document.
+// Use it only to store DID document in a secure storage
+func WithPrivateKeys() MarshalOption {
+	return func(o *marshalOptions) {
+		o.privateKeys = true
+	}
+}
+
+// WithIndent produces indented JSON, like json.MarshalIndent
+func WithIndent(prefix string, indent string) MarshalOption {
+	return func(o *marshalOptions) {
+		o.prefix = prefix
+		o.indent = indent
+	}
+}
+
+// privateDIDKey, privateManagementKey and privateDID are DIDKey, ManagementKey and DID without MarshalJSON,
+// they keep private keys in JSON encoding
+type privateDIDKey DIDKey
+type privateManagementKey ManagementKey
+type plainDID DID
+
+type privateDID struct {
+	*plainDID
+	ManagementKeys []*privateManagementKey `json:"managementKeys"`
+	DIDKeys        []*privateDIDKey        `json:"didKeys"`
+}
+
+// MarshalJSON encodes DIDKey without PrivateKey, use DID.Marshal with WithPrivateKeys() to export private keys
+func (didkey DIDKey) MarshalJSON() ([]byte, error) {
+
+	key := privateDIDKey(didkey)
+	key.PrivateKey = nil
+
+	return json.Marshal(&key)
+
+}
+
+// MarshalJSON encodes ManagementKey without PrivateKey, use DID.Marshal with WithPrivateKeys() to export private keys
+func (mgmtkey ManagementKey) MarshalJSON() ([]byte, error) {
+
+	key := privateManagementKey(mgmtkey)
+	key.PrivateKey = nil
+
+	return json.Marshal(&key)
+
+}
+
+// Marshal returns JSON encoding of DID document.
+// Private keys are stripped like in json.Marshal, use WithPrivateKeys() to keep them
+func (did *DID) Marshal(opts ...MarshalOption) ([]byte, error) {
+
+	o := &marshalOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	var d interface{} = did
+	if o.privateKeys {
+		d = did.withPrivateKeys()
+	}
+
+	if o.prefix != "" || o.indent != "" {
+		return json.MarshalIndent(d, o.prefix, o.indent)
+	}
+
+	return json.Marshal(d)
+
+}
+
+// helper function that converts DID into privateDID, which JSON encoding keeps private keys
+func (did *DID) withPrivateKeys() *privateDID {
+
+	d := &privateDID{plainDID: (*plainDID)(did)}
+
+	for _, key := range did.ManagementKeys {
+		d.ManagementKeys = append(d.ManagementKeys, (*privateManagementKey)(key))
+	}
+
+	for _, key := range did.DIDKeys {
+		d.DIDKeys = append(d.DIDKeys, (*privateDIDKey)(key))
+	}
+
+	return d
+
+}
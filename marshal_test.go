@@ -0,0 +1,61 @@
+package factomdid
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarshal(t *testing.T) {
+
+	did := NewDID()
+
+	didKey, _ := NewDIDKey("did-key", KeyTypeEdDSA)
+	didKey.AddPurpose(KeyPurposePublic)
+	mgmtKey, _ := NewManagementKey("mgmt-key", KeyTypeECDSA, 0)
+
+	did.AddDIDKey(didKey)
+	did.AddManagementKey(mgmtKey)
+
+	// private keys are stripped by json.Marshal
+	for _, v := range []interface{}{did, didKey, mgmtKey, *didKey, *mgmtKey} {
+		b, err := json.Marshal(v)
+		assert.NoError(t, err)
+		assert.NotContains(t, string(b), "privateKey")
+		assert.Contains(t, string(b), "publicKey")
+	}
+	assert.NotEmpty(t, didKey.PrivateKey)
+
+	// private keys are stripped by default
+	b, err := did.Marshal()
+	assert.NoError(t, err)
+	assert.NotContains(t, string(b), "privateKey")
+
+	decoded := &DID{}
+	err = json.Unmarshal(b, decoded)
+	assert.NoError(t, err)
+	assert.Equal(t, did.ID, decoded.ID)
+	assert.NoError(t, decoded.Validate())
+
+	// private keys are included on demand
+	b, err = did.Marshal(WithPrivateKeys())
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), "privateKey")
+
+	decoded = &DID{}
+	err = json.Unmarshal(b, decoded)
+	assert.NoError(t, err)
+	assert.Equal(t, did.DIDKeys[0].PrivateKey, decoded.DIDKeys[0].PrivateKey)
+	assert.Equal(t, did.ManagementKeys[0].PrivateKey, decoded.ManagementKeys[0].PrivateKey)
+	assert.Equal(t, did.DIDKeys[0].Purpose, decoded.DIDKeys[0].Purpose)
+	assert.NoError(t, decoded.Validate())
+
+	// indented output
+	b, err = did.Marshal(WithIndent("", "  "))
+	assert.NoError(t, err)
+	assert.True(t, strings.Contains(string(b), "\n  "))
+	assert.NotContains(t, string(b), "privateKey")
+
+}
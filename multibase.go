@@ -0,0 +1,161 @@
+package factomdid
+
+import (
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/FactomProject/btcutil/base58"
+	"github.com/frankbraun/dcrd/dcrec/secp256k1"
+	"golang.org/x/crypto/curve25519"
+)
+
+const (
+
+	// Multicodec codes of public keys
+
+	// MulticodecSecp256k1Pub is multicodec of compressed secp256k1 public key
+	MulticodecSecp256k1Pub = 0xe7
+	// MulticodecX25519Pub is multicodec of X25519 public key
+	MulticodecX25519Pub = 0xec
+	// MulticodecEd25519Pub is multicodec of Ed25519 public key
+	MulticodecEd25519Pub = 0xed
+	// MulticodecP256Pub is multicodec of compressed P-256 public key
+	MulticodecP256Pub = 0x1200
+	// MulticodecRSAPub is multicodec of PKCS #1 RSA public key
+	MulticodecRSAPub = 0x1205
+
+	// compressedECPublicKeySize is size of compressed secp256k1 and P-256 public keys
+	compressedECPublicKeySize = 33
+
+	// MultibaseBase58BTC is multibase prefix of base58btc encoding
+	MultibaseBase58BTC = "z"
+)
+
+// ToMultibase encodes public key as publicKeyMultibase: base58btc multibase of multicodec prefixed public key
+func (key *AbstractKey) ToMultibase() (string, error) {
+
+	err := validate.StructPartial(key, "PublicKey", "KeyType")
+	if err != nil {
+		return "", err
+	}
+
+	suite, err := GetKeySuite(key.KeyType)
+	if err != nil {
+		return "", err
+	}
+
+	multibaseSuite, ok := suite.(MultibaseSuite)
+	if !ok {
+		return "", fmt.Errorf("%s doesn't support multicodec", key.KeyType)
+	}
+
+	publicKey, err := compressMultibasePublicKey(multibaseSuite.Multicodec(), key.PublicKey)
+	if err != nil {
+		return "", err
+	}
+
+	prefix := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(prefix, multibaseSuite.Multicodec())
+
+	return MultibaseBase58BTC + base58.Encode(append(prefix[:n], publicKey...)), nil
+
+}
+
+// FromMultibase imports public key from publicKeyMultibase into AbstractKey.
+// KeyType is inferred from multicodec prefix, PrivateKey is reset
+func (key *AbstractKey) FromMultibase(value string) (*AbstractKey, error) {
+
+	if len(value) < 2 || value[:1] != MultibaseBase58BTC {
+		return nil, fmt.Errorf("Only base58btc (z) multibase is supported")
+	}
+
+	b := base58.Decode(value[1:])
+
+	code, n := binary.Uvarint(b)
+	if n <= 0 || n == len(b) {
+		return nil, fmt.Errorf("Invalid multicodec prefix")
+	}
+
+	// built-in key types take precedence over custom key types with the same multicodec
+	keyType := ""
+	for _, t := range KeyTypes() {
+
+		suite, _ := GetKeySuite(t)
+
+		multibaseSuite, ok := suite.(MultibaseSuite)
+		if !ok || multibaseSuite.Multicodec() != code {
+			continue
+		}
+
+		if keyType == "" || isBuiltinKeyType(t) {
+			keyType = t
+		}
+
+	}
+
+	if keyType == "" {
+		return nil, fmt.Errorf("Unsupported multicodec 0x%x", code)
+	}
+
+	err := validateMultibasePublicKey(code, b[n:])
+	if err != nil {
+		return nil, err
+	}
+
+	key.KeyType = keyType
+	key.PublicKey = b[n:]
+	key.PrivateKey = nil
+
+	return key, nil
+
+}
+
+// helper function that checks public key of built-in multicodec, public keys of custom multicodecs aren't checked
+func validateMultibasePublicKey(code uint64, publicKey []byte) error {
+
+	size := 0
+	switch code {
+	case MulticodecEd25519Pub:
+		size = ed25519.PublicKeySize
+	case MulticodecX25519Pub:
+		size = curve25519.PointSize
+	case MulticodecSecp256k1Pub, MulticodecP256Pub:
+		size = compressedECPublicKeySize
+	case MulticodecRSAPub:
+		if _, err := parseRSAPublicKey(publicKey); err != nil {
+			return fmt.Errorf("Invalid multicodec 0x%x public key: %v", code, err)
+		}
+	}
+
+	if size > 0 && len(publicKey) != size {
+		return fmt.Errorf("Multicodec 0x%x public key must be %d bytes, got %d bytes", code, size, len(publicKey))
+	}
+
+	return nil
+
+}
+
+// helper function that compresses secp256k1 and P-256 public keys, since their multicodecs define compressed keys only.
+// Public keys of other multicodecs are returned as is
+func compressMultibasePublicKey(code uint64, publicKey []byte) ([]byte, error) {
+
+	switch code {
+	case MulticodecSecp256k1Pub:
+		pubKey, err := secp256k1.ParsePubKey(publicKey, secp256k1.S256())
+		if err != nil {
+			return nil, err
+		}
+		return pubKey.SerializeCompressed(), nil
+	case MulticodecP256Pub:
+		pubKey, err := parseP256PublicKey(publicKey)
+		if err != nil {
+			return nil, err
+		}
+		return elliptic.MarshalCompressed(pubKey.Curve, pubKey.X, pubKey.Y), nil
+	}
+
+	return publicKey, nil
+
+}
@@ -0,0 +1,107 @@
+package factomdid
+
+import (
+	"crypto/elliptic"
+	"strings"
+	"testing"
+
+	"github.com/frankbraun/dcrd/dcrec/secp256k1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMultibase(t *testing.T) {
+
+	// well-known base58btc prefixes of multicodec public keys
+	prefixes := map[string]string{
+		KeyTypeEdDSA:     "z6Mk",
+		KeyTypeX25519:    "z6LS",
+		KeyTypeECDSA:     "zQ3s",
+		KeyTypeECDSAP256: "zDn",
+	}
+
+	for keyType, prefix := range prefixes {
+
+		key := &AbstractKey{KeyType: keyType}
+		key.generateRandomKeys()
+
+		value, err := key.ToMultibase()
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(value, prefix), keyType)
+
+		imported, err := (&AbstractKey{}).FromMultibase(value)
+		assert.NoError(t, err)
+		assert.Equal(t, keyType, imported.KeyType)
+		assert.Equal(t, key.PublicKey, imported.PublicKey)
+
+	}
+
+	// uncompressed secp256k1 and P-256 keys are compressed
+	k1 := &AbstractKey{KeyType: KeyTypeECDSA}
+	k1.generateRandomKeys()
+	pubKey, _ := secp256k1.ParsePubKey(k1.PublicKey, secp256k1.S256())
+	p256 := &AbstractKey{KeyType: KeyTypeECDSAP256}
+	p256.generateRandomKeys()
+	x, y := elliptic.UnmarshalCompressed(elliptic.P256(), p256.PublicKey)
+
+	for _, key := range []*AbstractKey{
+		{KeyType: KeyTypeECDSA, PublicKey: pubKey.SerializeUncompressed()},
+		{KeyType: KeyTypeECDSAP256, PublicKey: elliptic.Marshal(elliptic.P256(), x, y)},
+	} {
+
+		assert.Len(t, key.PublicKey, 65)
+		value, err := key.ToMultibase()
+		assert.NoError(t, err)
+		imported, err := (&AbstractKey{}).FromMultibase(value)
+		assert.NoError(t, err)
+		assert.Equal(t, key.KeyType, imported.KeyType)
+		assert.Len(t, imported.PublicKey, 33)
+
+		// compressed key is the same public key
+		jwk1, _ := key.ToJWK()
+		jwk2, _ := imported.ToJWK()
+		assert.Equal(t, jwk1, jwk2)
+
+	}
+
+	// invalid EC public key
+	_, err := (&AbstractKey{KeyType: KeyTypeECDSA, PublicKey: make([]byte, 65)}).ToMultibase()
+	assert.Error(t, err)
+
+	// RSA round trip
+	rsa := &AbstractKey{KeyType: KeyTypeRSA}
+	rsa.generateRandomKeys()
+	value, err := rsa.ToMultibase()
+	assert.NoError(t, err)
+	imported, err := (&AbstractKey{}).FromMultibase(value)
+	assert.NoError(t, err)
+	assert.Equal(t, KeyTypeRSA, imported.KeyType)
+
+	// unsupported multibase
+	imported, err = (&AbstractKey{}).FromMultibase("f0123")
+	assert.Nil(t, imported)
+	assert.Error(t, err)
+
+	// public key of wrong length
+	ed := &AbstractKey{KeyType: KeyTypeEdDSA}
+	ed.generateRandomKeys()
+	ed.PublicKey = ed.PublicKey[:31]
+	value, err = ed.ToMultibase()
+	assert.NoError(t, err)
+	imported, err = (&AbstractKey{}).FromMultibase(value)
+	assert.Nil(t, imported)
+	assert.Error(t, err)
+
+	// invalid RSA public key
+	rsa.PublicKey = rsa.PublicKey[1:]
+	value, err = rsa.ToMultibase()
+	assert.NoError(t, err)
+	imported, err = (&AbstractKey{}).FromMultibase(value)
+	assert.Nil(t, imported)
+	assert.Error(t, err)
+
+	// unsupported multicodec
+	imported, err = (&AbstractKey{}).FromMultibase("z111111")
+	assert.Nil(t, imported)
+	assert.Error(t, err)
+
+}
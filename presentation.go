@@ -0,0 +1,271 @@
+package factomdid
+
+import (
+	"fmt"
+	"time"
+)
+
+// VerifiablePresentation describes W3C Verifiable Presentation signed by holder's Factom DID
+type VerifiablePresentation struct {
+	Context              []string                `json:"@context" form:"@context" query:"@context"`
+	ID                   string                  `json:"id,omitempty" form:"id" query:"id"`
+	Type                 []string                `json:"type" form:"type" query:"type"`
+	Holder               string                  `json:"holder" form:"holder" query:"holder"`
+	VerifiableCredential []*VerifiableCredential `json:"verifiableCredential,omitempty" form:"verifiableCredential" query:"verifiableCredential"`
+	Proof                *Proof                  `json:"proof,omitempty" form:"proof" query:"proof"`
+}
+
+// presentationJWTClaims are JWT-VP claims, challenge is carried in nonce and domain in aud
+type presentationJWTClaims struct {
+	JWTClaims
+	Nonce string                  `json:"nonce,omitempty"`
+	VP    *VerifiablePresentation `json:"vp"`
+}
+
+const (
+	// VPType is type of every verifiable presentation
+	VPType = "VerifiablePresentation"
+)
+
+// NewPresentation generates new unsigned verifiable presentation of holder DID with embedded credentials
+func NewPresentation(holder string, credentials ...*VerifiableCredential) (*VerifiablePresentation, error) {
+
+	_, _, err := parseDID(holder)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range credentials {
+		if credentials[i] == nil || credentials[i].Proof == nil {
+			return nil, fmt.Errorf("Presentation must contain credentials with embedded proof")
+		}
+	}
+
+	vp := &VerifiablePresentation{}
+	vp.Context = []string{VCContext}
+	vp.Type = []string{VPType}
+	vp.Holder = holder
+	vp.VerifiableCredential = credentials
+
+	return vp, nil
+
+}
+
+// SetID sets ID of verifiable presentation
+func (vp *VerifiablePresentation) SetID(id string) *VerifiablePresentation {
+
+	vp.ID = id
+
+	return vp
+
+}
+
+// SignPresentation signs verifiable presentation with holder's DIDKey and returns a copy with embedded proof
+// (JcsJsonWebSignature2020 by default, see WithProofType).
+// DIDKey must have authentication purpose. challenge (required) and domain (optional) are set by verifier to prevent replay
+func (didkey *DIDKey) SignPresentation(vp *VerifiablePresentation, challenge string, domain string, opts ...ProofOption) (*VerifiablePresentation, error) {
+
+	err := didkey.checkHolder(vp, challenge)
+	if err != nil {
+		return nil, err
+	}
+
+	signed := *vp
+	signed.Proof, err = didkey.createProof(Proof{ProofPurpose: ProofPurposeAuthentication, Challenge: challenge, Domain: domain}, signed.marshalWithProof, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &signed, nil
+
+}
+
+// SignPresentationJWT signs verifiable presentation with holder's DIDKey and returns JWT-VP,
+// challenge is set as nonce and domain as aud claim. DIDKey must have authentication purpose
+func (didkey *DIDKey) SignPresentationJWT(vp *VerifiablePresentation, challenge string, domain string) (string, error) {
+
+	err := didkey.checkHolder(vp, challenge)
+	if err != nil {
+		return "", err
+	}
+
+	if !didkey.HasPurpose(KeyPurposeAuthentication) {
+		return "", fmt.Errorf("DIDKey %s must have %s purpose to sign presentations", didkey.Alias, KeyPurposeAuthentication)
+	}
+
+	claims := &presentationJWTClaims{}
+	claims.Issuer = vp.Holder
+	claims.ID = vp.ID
+	claims.IssuedAt = time.Now().Unix()
+	claims.Nonce = challenge
+	if domain != "" {
+		claims.Audience = JWTAudience{domain}
+	}
+
+	unsigned := *vp
+	unsigned.Proof = nil
+	claims.VP = &unsigned
+
+	return didkey.SignJWT(claims)
+
+}
+
+// VerifyPresentation verifies embedded proof of verifiable presentation and all its credentials.
+// Presenting key must have authentication purpose on resolved holder DID, proof challenge and domain must match.
+// Credentials must be issued to the holder (credentialSubject.id), unless WithoutHolderBinding is set.
+// CredentialOption is applied to credentials verification. Returns the holder key which signed the presentation
+func VerifyPresentation(vp *VerifiablePresentation, resolver Resolver, challenge string, domain string, opts ...CredentialOption) (*DIDKey, error) {
+
+	if vp == nil {
+		return nil, fmt.Errorf("Presentation is empty")
+	}
+
+	if challenge == "" {
+		return nil, fmt.Errorf("Presentation challenge is empty")
+	}
+
+	if vp.Proof == nil {
+		return nil, fmt.Errorf("Proof is empty")
+	}
+
+	if vp.Proof.Challenge != challenge {
+		return nil, fmt.Errorf("Invalid presentation challenge")
+	}
+
+	if vp.Proof.Domain != domain {
+		return nil, fmt.Errorf("Invalid presentation domain %s, must be %s", vp.Proof.Domain, domain)
+	}
+
+	key, did, err := verifyProof(vp.Proof, ProofPurposeAuthentication, resolver, vp.marshalWithProof)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = did.Resolve(vp.Holder)
+	if err != nil {
+		return nil, fmt.Errorf("Presentation holder %s is not DID of signing key %s", vp.Holder, vp.Proof.VerificationMethod)
+	}
+
+	err = verifyPresentedCredentials(vp, resolver, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return key, nil
+
+}
+
+// VerifyPresentationJWT verifies JWT-VP and all its credentials like VerifyPresentation.
+// Returns verifiable presentation (without proof) and the holder key which signed it
+func VerifyPresentationJWT(token string, resolver Resolver, challenge string, domain string, opts ...CredentialOption) (*VerifiablePresentation, *DIDKey, error) {
+
+	if challenge == "" {
+		return nil, nil, fmt.Errorf("Presentation challenge is empty")
+	}
+
+	var jwtOpts []JWTOption
+	if domain != "" {
+		jwtOpts = append(jwtOpts, WithAudience(domain))
+	}
+
+	claims := &presentationJWTClaims{}
+	key, err := VerifyJWT(token, resolver, KeyPurposeAuthentication, claims, jwtOpts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if claims.Nonce != challenge {
+		return nil, nil, fmt.Errorf("Invalid presentation challenge")
+	}
+
+	if domain == "" && len(claims.Audience) > 0 {
+		return nil, nil, fmt.Errorf("Invalid presentation domain %v", []string(claims.Audience))
+	}
+
+	vp := claims.VP
+	if vp == nil {
+		return nil, nil, fmt.Errorf("JWT-VP has no vp claim")
+	}
+
+	if claims.Issuer == "" {
+		return nil, nil, fmt.Errorf("JWT-VP must have iss claim")
+	}
+
+	if vp.Holder != "" && vp.Holder != claims.Issuer {
+		return nil, nil, fmt.Errorf("JWT-VP iss doesn't match presentation holder")
+	}
+
+	vp.Holder = claims.Issuer
+	vp.Proof = nil
+
+	err = verifyPresentedCredentials(vp, resolver, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return vp, key, nil
+
+}
+
+// helper function that checks that verifiable presentation is presented by DID of DIDKey
+func (didkey *DIDKey) checkHolder(vp *VerifiablePresentation, challenge string) error {
+
+	if vp == nil {
+		return fmt.Errorf("Presentation is empty")
+	}
+
+	if challenge == "" {
+		return fmt.Errorf("Presentation challenge is empty")
+	}
+
+	if didkey.Controller == "" {
+		return fmt.Errorf("DIDKey %s has no controller, add it to DID document first", didkey.Alias)
+	}
+
+	_, err := (&DID{ID: didkey.Controller}).Resolve(vp.Holder)
+	if err != nil {
+		return fmt.Errorf("Presentation holder %s is not DID of DIDKey %s", vp.Holder, didkey.Alias)
+	}
+
+	return nil
+
+}
+
+// helper function that verifies all credentials of verifiable presentation and their holder binding
+func verifyPresentedCredentials(vp *VerifiablePresentation, resolver Resolver, opts []CredentialOption) error {
+
+	o := &credentialOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	for i, vc := range vp.VerifiableCredential {
+
+		_, err := VerifyCredential(vc, resolver, opts...)
+		if err != nil {
+			return fmt.Errorf("Invalid presented credential %d: %v", i, err)
+		}
+
+		if o.withoutHolderBinding {
+			continue
+		}
+
+		subject, _ := vc.CredentialSubject["id"].(string)
+		if subject != vp.Holder {
+			return fmt.Errorf("Presented credential %d is issued to %s, not to holder %s", i, subject, vp.Holder)
+		}
+
+	}
+
+	return nil
+
+}
+
+// helper function that marshals verifiable presentation with the proof, canonical (RFC 8785) JSON is signed payload of embedded proof
+func (vp VerifiablePresentation) marshalWithProof(proof *Proof) ([]byte, error) {
+
+	vp.Proof = proof
+
+	return MarshalCanonicalJSON(&vp)
+
+}
@@ -0,0 +1,157 @@
+package factomdid
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// helper function that returns resolver of several DID documents
+func newTestResolver(dids ...*DID) Resolver {
+	return ResolverFunc(func(id string) (*DID, error) {
+		for _, did := range dids {
+			if resolved, err := did.Resolve(id); err == nil {
+				return resolved, nil
+			}
+		}
+		return nil, fmt.Errorf("DID %s not found", id)
+	})
+}
+
+func TestSignPresentation(t *testing.T) {
+
+	issuer, issuerKey := addTestKeys(t, NewDID(), "issuer-key", KeyTypeEdDSA, KeyPurposePublic)
+	holder, holderKey := addTestKeys(t, NewDID(), "holder-key", KeyTypeECDSA, KeyPurposeAuthentication)
+	resolver := newTestResolver(issuer, holder)
+
+	vc, _ := NewCredential(issuer.ID, "KYCCredential", map[string]interface{}{"id": holder.ID, "kyc": true})
+	signedVC, _ := issuerKey.IssueCredential(vc)
+
+	vp, err := NewPresentation(holder.ID, signedVC)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{VPType}, vp.Type)
+
+	signed, err := holderKey.SignPresentation(vp, "challenge", "example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, ProofPurposeAuthentication, signed.Proof.ProofPurpose)
+	assert.Equal(t, "challenge", signed.Proof.Challenge)
+	assert.Equal(t, "example.com", signed.Proof.Domain)
+
+	// verify presentation transferred as JSON
+	data, _ := json.Marshal(signed)
+	received := &VerifiablePresentation{}
+	json.Unmarshal(data, received)
+
+	key, err := VerifyPresentation(received, resolver, "challenge", "example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, holderKey.Alias, key.Alias)
+
+	// replay with another challenge or domain
+	_, err = VerifyPresentation(received, resolver, "another-challenge", "example.com")
+	assert.Error(t, err)
+	_, err = VerifyPresentation(received, resolver, "challenge", "another.example.com")
+	assert.Error(t, err)
+	_, err = VerifyPresentation(received, resolver, "", "example.com")
+	assert.Error(t, err)
+
+	// challenge can't be changed without the signature
+	received.Proof.Challenge = "another-challenge"
+	_, err = VerifyPresentation(received, resolver, "another-challenge", "example.com")
+	assert.Error(t, err)
+
+	// tampered credential
+	tampered, _ := holderKey.SignPresentation(vp, "challenge", "")
+	tamperedVC := *signedVC
+	tamperedVC.CredentialSubject = map[string]interface{}{"id": holder.ID, "kyc": false}
+	tampered.VerifiableCredential = []*VerifiableCredential{&tamperedVC}
+	_, err = VerifyPresentation(tampered, resolver, "challenge", "")
+	assert.Error(t, err)
+
+	// presenting key must have authentication purpose
+	publicKey, _ := NewDIDKey("public-key", KeyTypeEdDSA)
+	publicKey.AddPurpose(KeyPurposePublic)
+	holder.AddDIDKey(publicKey)
+	_, err = publicKey.SignPresentation(vp, "challenge", "")
+	assert.Error(t, err)
+	_, err = publicKey.SignPresentationJWT(vp, "challenge", "")
+	assert.Error(t, err)
+
+	// holder key loses authentication purpose
+	signed, _ = holderKey.SignPresentation(vp, "challenge", "")
+	public := holder.Public()
+	for _, k := range public.DIDKeys {
+		if k.Alias == holderKey.Alias {
+			k.Purpose = []DIDKeyPurpose{{Purpose: KeyPurposePublic}}
+		}
+	}
+	_, err = VerifyPresentation(signed, newTestResolver(issuer, public), "challenge", "")
+	assert.Error(t, err)
+
+	// holder is not DID of the key
+	other, _ := NewPresentation(issuer.ID, signedVC)
+	_, err = holderKey.SignPresentation(other, "challenge", "")
+	assert.Error(t, err)
+
+	// signing requires challenge
+	_, err = holderKey.SignPresentation(vp, "", "")
+	assert.Error(t, err)
+
+	// credentials must be signed
+	_, err = NewPresentation(holder.ID, vc)
+	assert.Error(t, err)
+
+	// credential issued to another subject
+	otherVC, _ := NewCredential(issuer.ID, "KYCCredential", map[string]interface{}{"id": issuer.ID, "kyc": true})
+	signedOtherVC, _ := issuerKey.IssueCredential(otherVC)
+	otherVP, _ := NewPresentation(holder.ID, signedVC, signedOtherVC)
+	signed, _ = holderKey.SignPresentation(otherVP, "challenge", "")
+	_, err = VerifyPresentation(signed, resolver, "challenge", "")
+	assert.Error(t, err)
+	_, err = VerifyPresentation(signed, resolver, "challenge", "", WithoutHolderBinding())
+	assert.NoError(t, err)
+
+}
+
+func TestSignPresentationJWT(t *testing.T) {
+
+	issuer, issuerKey := addTestKeys(t, NewDID(), "issuer-key", KeyTypeEdDSA, KeyPurposePublic)
+	holder, holderKey := addTestKeys(t, NewDID(), "holder-key", KeyTypeECDSA, KeyPurposeAuthentication)
+	resolver := newTestResolver(issuer, holder)
+
+	vc, _ := NewCredential(issuer.ID, "KYCCredential", map[string]interface{}{"id": holder.ID, "kyc": true})
+	signedVC, _ := issuerKey.IssueCredential(vc)
+	vp, _ := NewPresentation(holder.ID, signedVC)
+
+	token, err := holderKey.SignPresentationJWT(vp, "challenge", "example.com")
+	assert.NoError(t, err)
+
+	verified, key, err := VerifyPresentationJWT(token, resolver, "challenge", "example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, holderKey.Alias, key.Alias)
+	assert.Equal(t, holder.ID, verified.Holder)
+	assert.Equal(t, 1, len(verified.VerifiableCredential))
+
+	_, _, err = VerifyPresentationJWT(token, resolver, "another-challenge", "example.com")
+	assert.Error(t, err)
+	_, _, err = VerifyPresentationJWT(token, resolver, "challenge", "another.example.com")
+	assert.Error(t, err)
+	_, _, err = VerifyPresentationJWT(token, resolver, "challenge", "")
+	assert.Error(t, err)
+
+	// issuer DID can't be resolved
+	_, _, err = VerifyPresentationJWT(token, holder, "challenge", "example.com")
+	assert.Error(t, err)
+
+	// credential without subject DID
+	anonymousVC, _ := NewCredential(issuer.ID, "KYCCredential", map[string]interface{}{"kyc": true})
+	signedAnonymousVC, _ := issuerKey.IssueCredential(anonymousVC)
+	vp, _ = NewPresentation(holder.ID, signedAnonymousVC)
+	token, _ = holderKey.SignPresentationJWT(vp, "challenge", "example.com")
+	_, _, err = VerifyPresentationJWT(token, resolver, "challenge", "example.com")
+	assert.Error(t, err)
+	_, _, err = VerifyPresentationJWT(token, resolver, "challenge", "example.com", WithoutHolderBinding())
+	assert.NoError(t, err)
+
+}
@@ -0,0 +1,191 @@
+package factomdid
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Proof is embedded proof of verifiable credential or presentation
+type Proof struct {
+	Type               string `json:"type" form:"type" query:"type"`
+	Created            string `json:"created" form:"created" query:"created"`
+	VerificationMethod string `json:"verificationMethod" form:"verificationMethod" query:"verificationMethod"`
+	ProofPurpose       string `json:"proofPurpose" form:"proofPurpose" query:"proofPurpose"`
+	Challenge          string `json:"challenge,omitempty" form:"challenge" query:"challenge"`
+	Domain             string `json:"domain,omitempty" form:"domain" query:"domain"`
+	JWS                string `json:"jws,omitempty" form:"jws" query:"jws"`
+	ProofValue         string `json:"proofValue,omitempty" form:"proofValue" query:"proofValue"`
+}
+
+// ProofOption configures embedded proof created by IssueCredential and SignPresentation
+type ProofOption func(*proofOptions)
+
+type proofOptions struct {
+	proofType string
+}
+
+const (
+
+	// Proof types
+
+	// ProofTypeJcsJSONWebSignature2020 is proof with detached base64url JWS of JCS (RFC 8785) canonical JSON document
+	// with the proof without the signature. It isn't JsonWebSignature2020, which uses URDNA2015 canonicalization
+	// and unencoded (b64: false) JWS payload
+	ProofTypeJcsJSONWebSignature2020 = "JcsJsonWebSignature2020"
+	// ProofTypeJcsEd25519Signature2020 is Linked Data proof of Ed25519 DIDKey over JCS (RFC 8785) canonical JSON
+	ProofTypeJcsEd25519Signature2020 = "JcsEd25519Signature2020"
+	// ProofTypeJcsEcdsaSecp256k1Signature2019 is Linked Data proof of ECDSA secp256k1 DIDKey over JCS (RFC 8785) canonical JSON
+	ProofTypeJcsEcdsaSecp256k1Signature2019 = "JcsEcdsaSecp256k1Signature2019"
+
+	// Proof purposes
+
+	// ProofPurposeAssertionMethod is assertionMethod proof purpose, requires DIDKey with publicKey purpose
+	ProofPurposeAssertionMethod = "assertionMethod"
+	// ProofPurposeAuthentication is authentication proof purpose, requires DIDKey with authentication purpose
+	ProofPurposeAuthentication = "authentication"
+)
+
+// helper function that maps proof purpose into DIDKey purpose
+func proofPurposeToKeyPurpose(proofPurpose string) (string, error) {
+
+	switch proofPurpose {
+	case ProofPurposeAssertionMethod:
+		return KeyPurposePublic, nil
+	case ProofPurposeAuthentication:
+		return KeyPurposeAuthentication, nil
+	}
+
+	return "", fmt.Errorf("Unsupported proof purpose %s", proofPurpose)
+
+}
+
+// WithProofType sets type of embedded proof, ProofTypeJcsJSONWebSignature2020 by default
+func WithProofType(proofType string) ProofOption {
+	return func(o *proofOptions) {
+		o.proofType = proofType
+	}
+}
+
+// helper function that creates proof with proof purpose, challenge and domain of options.
+// marshal returns canonical JSON document with the proof embedded (or without proof if it is nil)
+func (didkey *DIDKey) createProof(options Proof, marshal func(*Proof) ([]byte, error), opts []ProofOption) (*Proof, error) {
+
+	o := &proofOptions{proofType: ProofTypeJcsJSONWebSignature2020}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	purpose, err := proofPurposeToKeyPurpose(options.ProofPurpose)
+	if err != nil {
+		return nil, err
+	}
+
+	if !didkey.HasPurpose(purpose) {
+		return nil, fmt.Errorf("DIDKey %s must have %s purpose to create %s proof", didkey.Alias, purpose, options.ProofPurpose)
+	}
+
+	kid, err := didkey.KeyID()
+	if err != nil {
+		return nil, err
+	}
+
+	proof := &options
+	proof.Type = o.proofType
+	proof.Created = time.Now().UTC().Format(time.RFC3339)
+	proof.VerificationMethod = kid
+	proof.JWS = ""
+	proof.ProofValue = ""
+
+	switch proof.Type {
+	case ProofTypeJcsJSONWebSignature2020:
+		err = didkey.signJWSProof(proof, marshal)
+	case ProofTypeJcsEd25519Signature2020, ProofTypeJcsEcdsaSecp256k1Signature2019:
+		err = didkey.signLinkedDataProof(proof, marshal)
+	default:
+		err = fmt.Errorf("Unsupported proof type %s", proof.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return proof, nil
+
+}
+
+// helper function that verifies proof, returns the key and DID document it was resolved from
+func verifyProof(proof *Proof, proofPurpose string, resolver Resolver, marshal func(*Proof) ([]byte, error)) (*DIDKey, *DID, error) {
+
+	if proof == nil {
+		return nil, nil, fmt.Errorf("Proof is empty")
+	}
+
+	if proof.ProofPurpose != proofPurpose {
+		return nil, nil, fmt.Errorf("Invalid proof purpose %s, must be %s", proof.ProofPurpose, proofPurpose)
+	}
+
+	purpose, err := proofPurposeToKeyPurpose(proofPurpose)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch proof.Type {
+	case ProofTypeJcsJSONWebSignature2020:
+		return verifyJWSProof(proof, purpose, resolver, marshal)
+	case ProofTypeJcsEd25519Signature2020, ProofTypeJcsEcdsaSecp256k1Signature2019:
+		return verifyLinkedDataProof(proof, purpose, resolver, marshal)
+	}
+
+	return nil, nil, fmt.Errorf("Unsupported proof type %s", proof.Type)
+
+}
+
+// helper function that signs JcsJsonWebSignature2020 proof: detached JWS of the document with the proof without signature
+func (didkey *DIDKey) signJWSProof(proof *Proof, marshal func(*Proof) ([]byte, error)) error {
+
+	payload, err := marshal(proof)
+	if err != nil {
+		return err
+	}
+
+	token, err := didkey.signJWS("", payload)
+	if err != nil {
+		return err
+	}
+
+	// detached payload
+	parts := strings.Split(token, ".")
+	proof.JWS = parts[0] + ".." + parts[2]
+
+	return nil
+
+}
+
+// helper function that verifies JcsJsonWebSignature2020 proof, the key must have purpose
+func verifyJWSProof(proof *Proof, purpose string, resolver Resolver, marshal func(*Proof) ([]byte, error)) (*DIDKey, *DID, error) {
+
+	parts := strings.Split(proof.JWS, ".")
+	if len(parts) != 3 || parts[1] != "" {
+		return nil, nil, fmt.Errorf("Invalid proof JWS, must be detached compact JWS")
+	}
+
+	unsigned := *proof
+	unsigned.JWS = ""
+	payload, err := marshal(&unsigned)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	header, verified, err := verifyJWS(parts[0]+"."+base64.RawURLEncoding.EncodeToString(payload)+"."+parts[2], resolver, purpose)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if header.Kid != proof.VerificationMethod {
+		return nil, nil, fmt.Errorf("Proof JWS kid %s doesn't match verification method %s", header.Kid, proof.VerificationMethod)
+	}
+
+	return verified.key, verified.did, nil
+
+}
@@ -0,0 +1,297 @@
+package factomdid
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/FactomProject/factom"
+)
+
+// Publisher submits DID entries to Factom: commits, reveals and waits for acknowledgment
+type Publisher interface {
+	// PublishChain creates new chain with the entry as its first entry
+	PublishChain(entry *factom.Entry) (*PublishResult, error)
+	// PublishEntry adds the entry to existing chain entry.ChainID
+	PublishEntry(entry *factom.Entry) (*PublishResult, error)
+}
+
+// PublishResult describes published entry, TxID is ID of the commit transaction (if any)
+type PublishResult struct {
+	ChainID   string `json:"chainId" form:"chainId" query:"chainId"`
+	EntryHash string `json:"entryHash" form:"entryHash" query:"entryHash"`
+	TxID      string `json:"txId,omitempty" form:"txId" query:"txId"`
+}
+
+// PublisherOption configures FactomPublisher
+type PublisherOption func(*publisherOptions)
+
+type publisherOptions struct {
+	ackTimeout  time.Duration
+	ackInterval time.Duration
+}
+
+// FactomPublisher publishes entries to factomd (see factom.SetFactomdServer).
+// Commits are signed either locally with Entry Credit address or by walletd (see factom.SetWalletServer)
+type FactomPublisher struct {
+	ec    *factom.ECAddress
+	ecPub string
+	opts  *publisherOptions
+}
+
+const (
+	// DefaultAckTimeout is default time FactomPublisher waits for acknowledgment of revealed entry
+	DefaultAckTimeout = time.Minute
+	// DefaultAckInterval is default interval between acknowledgment requests
+	DefaultAckInterval = time.Second
+
+	// Factom entry statuses
+
+	// EntryStatusTransactionACK is status of entry acknowledged by factomd
+	EntryStatusTransactionACK = "TransactionACK"
+	// EntryStatusDBlockConfirmed is status of entry included in directory block
+	EntryStatusDBlockConfirmed = "DBlockConfirmed"
+)
+
+// WithAckTimeout sets time FactomPublisher waits for acknowledgment of revealed entry, 0 disables waiting
+func WithAckTimeout(timeout time.Duration) PublisherOption {
+	return func(o *publisherOptions) {
+		o.ackTimeout = timeout
+	}
+}
+
+// WithAckInterval sets interval between acknowledgment requests
+func WithAckInterval(interval time.Duration) PublisherOption {
+	return func(o *publisherOptions) {
+		o.ackInterval = interval
+	}
+}
+
+// NewFactomPublisher creates FactomPublisher, that signs commits with Entry Credit address
+func NewFactomPublisher(ec *factom.ECAddress, opts ...PublisherOption) *FactomPublisher {
+
+	return &FactomPublisher{ec: ec, opts: newPublisherOptions(opts)}
+
+}
+
+// NewWalletPublisher creates FactomPublisher, that signs commits by walletd with Entry Credit public address stored in wallet
+func NewWalletPublisher(ecPub string, opts ...PublisherOption) *FactomPublisher {
+
+	return &FactomPublisher{ecPub: ecPub, opts: newPublisherOptions(opts)}
+
+}
+
+// PublishChain commits and reveals new chain, then waits for acknowledgment of its first entry
+func (p *FactomPublisher) PublishChain(entry *factom.Entry) (*PublishResult, error) {
+
+	if entry == nil {
+		return nil, fmt.Errorf("Entry is empty")
+	}
+
+	e := *entry
+	chain := factom.NewChain(&e)
+
+	var commit, reveal *factom.JSON2Request
+	var err error
+
+	if p.ec != nil {
+		var cr *CommitReveal
+		cr, err = newChainCommitReveal(chain, p.ec)
+		if err == nil {
+			commit, reveal = cr.Commit, cr.Reveal
+		}
+	} else {
+		commit, reveal, err = factom.WalletComposeChainCommitReveal(chain, p.ecPub, false)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return p.publish(commit, reveal, chain.ChainID, hex.EncodeToString(e.Hash()))
+
+}
+
+// PublishEntry commits and reveals entry, then waits for its acknowledgment
+func (p *FactomPublisher) PublishEntry(entry *factom.Entry) (*PublishResult, error) {
+
+	if entry == nil {
+		return nil, fmt.Errorf("Entry is empty")
+	}
+
+	var commit, reveal *factom.JSON2Request
+	var err error
+
+	if p.ec != nil {
+		var cr *CommitReveal
+		cr, err = newEntryCommitReveal(entry, p.ec)
+		if err == nil {
+			commit, reveal = cr.Commit, cr.Reveal
+		}
+	} else {
+		commit, reveal, err = factom.WalletComposeEntryCommitReveal(entry, p.ecPub, false)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return p.publish(commit, reveal, entry.ChainID, hex.EncodeToString(entry.Hash()))
+
+}
+
+// helper function that sends commit and reveal to factomd and waits for acknowledgment
+func (p *FactomPublisher) publish(commit *factom.JSON2Request, reveal *factom.JSON2Request, chainID string, entryHash string) (*PublishResult, error) {
+
+	commitResult := &struct {
+		TxID string `json:"txid"`
+	}{}
+
+	err := sendFactomdRequest(commit, commitResult)
+	if err != nil {
+		return nil, fmt.Errorf("Commit failed: %v", err)
+	}
+
+	revealResult := &struct {
+		EntryHash string `json:"entryhash"`
+	}{}
+
+	err = sendFactomdRequest(reveal, revealResult)
+	if err != nil {
+		return nil, fmt.Errorf("Reveal failed: %v", err)
+	}
+
+	if revealResult.EntryHash != entryHash {
+		return nil, fmt.Errorf("Revealed entry hash %s doesn't match entry hash %s", revealResult.EntryHash, entryHash)
+	}
+
+	if p.opts.ackTimeout > 0 {
+		err = p.waitForAck(chainID, entryHash)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &PublishResult{ChainID: chainID, EntryHash: entryHash, TxID: commitResult.TxID}, nil
+
+}
+
+// helper function that polls factomd until entry is acknowledged or timeout is reached
+func (p *FactomPublisher) waitForAck(chainID string, entryHash string) error {
+
+	deadline := time.Now().Add(p.opts.ackTimeout)
+
+	for {
+
+		status, err := factom.EntryRevealACK(entryHash, "", chainID)
+		if err == nil && (status.EntryData.Status == EntryStatusTransactionACK || status.EntryData.Status == EntryStatusDBlockConfirmed) {
+			return nil
+		}
+
+		if time.Now().Add(p.opts.ackInterval).After(deadline) {
+			if err != nil {
+				return fmt.Errorf("Entry %s is not acknowledged: %v", entryHash, err)
+			}
+			return fmt.Errorf("Entry %s is not acknowledged, status %s", entryHash, status.EntryData.Status)
+		}
+
+		time.Sleep(p.opts.ackInterval)
+
+	}
+
+}
+
+// CreateAndPublish generates DIDManagement entry and publishes it as the first entry of new DID chain.
+// ChainID of published chain is checked to match DID ChainID
+func (did *DID) CreateAndPublish(publisher Publisher, opts ...EntryOption) (*PublishResult, error) {
+
+	fe, err := did.Create(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if chainID := factom.ChainIDFromFields(fe.ExtIDs); chainID != did.GetChainID() {
+		return nil, fmt.Errorf("DID ExtIDs don't match DID ChainID %s", did.GetChainID())
+	}
+
+	return did.checkPublished(publisher.PublishChain(fe))
+
+}
+
+// UpdateAndPublish generates and publishes DIDUpdate entry
+func (did *DID) UpdateAndPublish(updatedDID *DID, signingKeyAlias string, publisher Publisher, opts ...EntryOption) (*PublishResult, error) {
+
+	fe, err := did.Update(updatedDID, signingKeyAlias, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return did.checkPublished(publisher.PublishEntry(fe))
+
+}
+
+// DeactivateAndPublish generates and publishes DIDDeactivation entry
+func (did *DID) DeactivateAndPublish(signingKeyAlias string, publisher Publisher, opts ...EntryOption) (*PublishResult, error) {
+
+	fe, err := did.Deactivate(signingKeyAlias, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return did.checkPublished(publisher.PublishEntry(fe))
+
+}
+
+// UpgradeVersionAndPublish generates and publishes DIDMethodVersionUpgrade entry
+func (did *DID) UpgradeVersionAndPublish(version string, signingKeyAlias string, publisher Publisher, opts ...EntryOption) (*PublishResult, error) {
+
+	fe, err := did.UpgradeVersion(version, signingKeyAlias, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return did.checkPublished(publisher.PublishEntry(fe))
+
+}
+
+// helper function that checks that entry is published to DID chain
+func (did *DID) checkPublished(result *PublishResult, err error) (*PublishResult, error) {
+
+	if err != nil {
+		return nil, err
+	}
+
+	if result.ChainID != did.GetChainID() {
+		return nil, fmt.Errorf("Published ChainID %s doesn't match DID ChainID %s", result.ChainID, did.GetChainID())
+	}
+
+	return result, nil
+
+}
+
+// helper function that applies PublisherOption list to default options
+func newPublisherOptions(opts []PublisherOption) *publisherOptions {
+
+	o := &publisherOptions{ackTimeout: DefaultAckTimeout, ackInterval: DefaultAckInterval}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+
+}
+
+// helper function that sends request to factomd and unmarshals its result
+func sendFactomdRequest(req *factom.JSON2Request, result interface{}) error {
+
+	resp, err := factom.SendFactomdRequest(req)
+	if err != nil {
+		return err
+	}
+
+	if resp.Error != nil {
+		return resp.Error
+	}
+
+	return json.Unmarshal(resp.JSONResult(), result)
+
+}
@@ -0,0 +1,91 @@
+package factomdid
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/FactomProject/factom"
+	"github.com/stretchr/testify/assert"
+)
+
+// helper function that starts fake factomd acknowledging all revealed entries with status
+func newTestFactomd(t *testing.T, status string) *httptest.Server {
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		req := &factom.JSON2Request{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(req))
+
+		var result interface{}
+
+		switch req.Method {
+		case "commit-chain", "commit-entry":
+			result = map[string]string{"message": "Entry Commit Success", "txid": "txid"}
+		case "reveal-chain", "reveal-entry":
+			result = map[string]string{"message": "Entry Reveal Success", "entryhash": hex.EncodeToString(entryHash(decodeRequestParam(t, req, "entry")))}
+		case "ack":
+			result = map[string]interface{}{"entrydata": map[string]string{"status": status}}
+		}
+
+		resp := factom.NewJSON2Response()
+		resp.ID = req.ID
+		resp.Result, _ = json.Marshal(result)
+		json.NewEncoder(w).Encode(resp)
+
+	}))
+
+}
+
+func TestFactomPublisher(t *testing.T) {
+
+	server := newTestFactomd(t, EntryStatusTransactionACK)
+	defer server.Close()
+
+	factomdServer := factom.FactomdServer()
+	factom.SetFactomdServer(server.URL)
+	defer factom.SetFactomdServer(factomdServer)
+
+	ec, _ := factom.MakeECAddress(bytes.Repeat([]byte{1}, 32))
+	publisher := NewFactomPublisher(ec, WithAckInterval(time.Millisecond))
+	did, _ := addTestKeys(t, NewDID(), "default-did-key", KeyTypeEdDSA, KeyPurposePublic)
+
+	result, err := did.CreateAndPublish(publisher)
+	assert.NoError(t, err)
+	assert.Equal(t, did.GetChainID(), result.ChainID)
+	assert.Equal(t, "txid", result.TxID)
+
+	fe, _ := did.Deactivate("default-mgmt-key")
+	result, err = did.DeactivateAndPublish("default-mgmt-key", publisher)
+	assert.NoError(t, err)
+	assert.Equal(t, hex.EncodeToString(fe.Hash()), result.EntryHash)
+
+}
+
+func TestFactomPublisherAckTimeout(t *testing.T) {
+
+	server := newTestFactomd(t, "NotConfirmed")
+	defer server.Close()
+
+	factomdServer := factom.FactomdServer()
+	factom.SetFactomdServer(server.URL)
+	defer factom.SetFactomdServer(factomdServer)
+
+	ec, _ := factom.MakeECAddress(bytes.Repeat([]byte{1}, 32))
+	did, _ := addTestKeys(t, NewDID(), "default-did-key", KeyTypeEdDSA, KeyPurposePublic)
+
+	publisher := NewFactomPublisher(ec, WithAckTimeout(10*time.Millisecond), WithAckInterval(time.Millisecond))
+	_, err := did.CreateAndPublish(publisher)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "NotConfirmed")
+
+	// no waiting for acknowledgment
+	publisher = NewFactomPublisher(ec, WithAckTimeout(0))
+	_, err = did.CreateAndPublish(publisher)
+	assert.NoError(t, err)
+
+}
@@ -0,0 +1,153 @@
+package factomdid
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Resolver resolves DID (e.g. "did:factom:testnet:<chainID>") into DID document
+type Resolver interface {
+	Resolve(did string) (*DID, error)
+}
+
+// HistoricalResolver is a Resolver which is able to resolve DID document as it was at the given time,
+// e.g. to verify credential signed by the key revoked after issuance
+type HistoricalResolver interface {
+	Resolver
+	ResolveAt(did string, t time.Time) (*DID, error)
+}
+
+// ResolverFunc is an adapter to use ordinary function as Resolver
+type ResolverFunc func(did string) (*DID, error)
+
+// Resolve calls f(did)
+func (f ResolverFunc) Resolve(did string) (*DID, error) {
+	return f(did)
+}
+
+// Resolve implements Resolver for already resolved DID document.
+// It returns the DID document itself if id refers to its chain, so *DID can be used wherever Resolver is expected
+func (did *DID) Resolve(id string) (*DID, error) {
+
+	network, chainID, err := parseDID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if chainID != did.GetChainID() {
+		return nil, fmt.Errorf("Can't resolve %s, DID document is %s", id, did.ID)
+	}
+
+	if network != NetworkUnspecified && did.Network != NetworkUnspecified && network != did.Network {
+		return nil, fmt.Errorf("Can't resolve %s, DID document is on %s", id, did.Network)
+	}
+
+	return did, nil
+
+}
+
+// resolver that resolves DID document at fixed time using HistoricalResolver
+type atTimeResolver struct {
+	resolver HistoricalResolver
+	t        time.Time
+}
+
+func (r *atTimeResolver) Resolve(did string) (*DID, error) {
+	return r.resolver.ResolveAt(did, r.t)
+}
+
+// helper function that returns resolver of DID documents at time t, resolver must be HistoricalResolver
+func resolverAt(resolver Resolver, t time.Time) (Resolver, error) {
+
+	historical, ok := resolver.(HistoricalResolver)
+	if !ok {
+		return nil, fmt.Errorf("Resolver doesn't support resolution at time")
+	}
+
+	return &atTimeResolver{resolver: historical, t: t}, nil
+
+}
+
+// SplitDIDURL splits DID URL "did:factom:<chainID>#alias" into DID and fragment (key or service alias)
+func SplitDIDURL(didURL string) (string, string, error) {
+
+	s := strings.SplitN(didURL, "#", 2)
+	if len(s) != 2 || s[1] == "" {
+		return "", "", fmt.Errorf("DID URL %s has no fragment", didURL)
+	}
+
+	_, _, err := parseDID(s[0])
+	if err != nil {
+		return "", "", err
+	}
+
+	return s[0], s[1], nil
+
+}
+
+// ResolveDIDKey resolves DID URL "did:factom:<chainID>#alias" into DIDKey of resolved DID document.
+// Returns error if the key doesn't exist or has no purpose
+func ResolveDIDKey(resolver Resolver, didURL string, purpose string) (*DIDKey, error) {
+
+	if resolver == nil {
+		return nil, fmt.Errorf("Resolver is empty")
+	}
+
+	id, alias, err := SplitDIDURL(didURL)
+	if err != nil {
+		return nil, err
+	}
+
+	did, err := resolver.Resolve(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if did == nil {
+		return nil, fmt.Errorf("DID %s not found", id)
+	}
+
+	for _, v := range did.DIDKeys {
+		if v.Alias == alias {
+			if !v.HasPurpose(purpose) {
+				return nil, fmt.Errorf("DIDKey %s doesn't have %s purpose", didURL, purpose)
+			}
+			return v, nil
+		}
+	}
+
+	return nil, fmt.Errorf("DIDKey %s not found", didURL)
+
+}
+
+// helper function that parses DID "did:factom[:network]:<chainID>" into network and chain ID
+func parseDID(id string) (string, string, error) {
+
+	if !strings.HasPrefix(id, DIDMethodName+":") {
+		return "", "", fmt.Errorf("Invalid DID %s, must start with %s", id, DIDMethodName)
+	}
+
+	var network string
+	s := strings.Split(strings.TrimPrefix(id, DIDMethodName+":"), ":")
+
+	switch len(s) {
+	case 1:
+	case 2:
+		network = s[0]
+		if network != NetworkMainnet && network != NetworkTestnet {
+			return "", "", fmt.Errorf("Invalid DID %s, unknown network %s", id, network)
+		}
+	default:
+		return "", "", fmt.Errorf("Invalid DID %s", id)
+	}
+
+	chainID := s[len(s)-1]
+	if b, err := hex.DecodeString(chainID); err != nil || len(b) != 32 {
+		return "", "", fmt.Errorf("Invalid DID %s, chain ID must be 32 bytes hex string", id)
+	}
+
+	return network, chainID, nil
+
+}
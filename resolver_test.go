@@ -0,0 +1,78 @@
+package factomdid
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolve(t *testing.T) {
+
+	did := NewDID()
+	chainID := did.GetChainID()
+
+	resolved, err := did.Resolve(did.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, did, resolved)
+
+	// network may be specified in DID
+	resolved, err = did.Resolve("did:factom:testnet:" + chainID)
+	assert.NoError(t, err)
+	assert.Equal(t, did, resolved)
+
+	// network must match if both are specified
+	did.SetMainnet()
+	resolved, err = did.Resolve("did:factom:testnet:" + chainID)
+	assert.Nil(t, resolved)
+	assert.Error(t, err)
+
+	// another DID
+	resolved, err = did.Resolve(NewDID().ID)
+	assert.Nil(t, resolved)
+	assert.Error(t, err)
+
+	// invalid DIDs
+	for _, id := range []string{"", "did:example:" + chainID, "did:factom:devnet:" + chainID, "did:factom:1234", "did:factom:a:b:" + chainID} {
+		resolved, err = did.Resolve(id)
+		assert.Nil(t, resolved)
+		assert.Error(t, err, id)
+	}
+
+}
+
+func TestResolveDIDKey(t *testing.T) {
+
+	did := NewDID()
+	authKey, _ := NewDIDKey("auth-key", KeyTypeEdDSA)
+	authKey.AddPurpose(KeyPurposeAuthentication)
+	did.AddDIDKey(authKey)
+
+	key, err := ResolveDIDKey(did, did.ID+"#auth-key", KeyPurposeAuthentication)
+	assert.NoError(t, err)
+	assert.Equal(t, authKey, key)
+
+	// wrong purpose
+	key, err = ResolveDIDKey(did, did.ID+"#auth-key", KeyPurposePublic)
+	assert.Nil(t, key)
+	assert.Error(t, err)
+
+	// unknown key
+	key, err = ResolveDIDKey(did, did.ID+"#unknown", KeyPurposeAuthentication)
+	assert.Nil(t, key)
+	assert.Error(t, err)
+
+	// no fragment
+	key, err = ResolveDIDKey(did, did.ID, KeyPurposeAuthentication)
+	assert.Nil(t, key)
+	assert.Error(t, err)
+
+	// ResolverFunc
+	resolver := ResolverFunc(func(id string) (*DID, error) {
+		return nil, fmt.Errorf("DID %s not found", id)
+	})
+	key, err = ResolveDIDKey(resolver, did.ID+"#auth-key", KeyPurposeAuthentication)
+	assert.Nil(t, key)
+	assert.Error(t, err)
+
+}
@@ -1,56 +1,327 @@
 package factomdid
 
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/FactomProject/factom"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// schemas/<entry schema version>/*.json are JSON schemas of DID entries content
+//
+//go:embed schemas
+var schemaFiles embed.FS
+
+// entryContentSchemas maps entry type to JSON schema file of its content, DIDDeactivation content must be empty
+var entryContentSchemas = map[string]string{
+	EntryTypeCreate:         "did_management_entry.json",
+	EntryTypeUpdate:         "did_update_entry.json",
+	EntryTypeVersionUpgrade: "did_method_version_upgrade_entry.json",
+}
+
+var (
+	compiledSchemas    map[string]*jsonschema.Schema
+	compiledSchemasErr error
+	compileSchemasOnce sync.Once
+)
+
+// DIDManagementEntrySchema is content of DIDManagement entry, fields are in the order of Factom DID spec
 type DIDManagementEntrySchema struct {
-	DIDKey           []*DIDKeySchema        `json:"didKey" form:"didKey" query:"didKey"`
 	DIDMethodVersion string                 `json:"didMethodVersion" form:"didMethodVersion" query:"didMethodVersion"`
 	ManagementKey    []*ManagementKeySchema `json:"managementKey" form:"managementKey" query:"managementKey"`
+	DIDKey           []*DIDKeySchema        `json:"didKey" form:"didKey" query:"didKey"`
 	Service          []*ServiceSchema       `json:"service,omitempty" form:"service" query:"service"`
 }
 
+// DIDUpdateEntrySchema is content of DIDUpdate entry, empty add and revoke sections are omitted
 type DIDUpdateEntrySchema struct {
-	Add struct {
-		DIDKey        []*DIDKeySchema        `json:"didKey,omitempty" form:"didKey" query:"didKey"`
-		ManagementKey []*ManagementKeySchema `json:"managementKey,omitempty" form:"managementKey" query:"managementKey"`
-		Service       []*ServiceSchema       `json:"service,omitempty" form:"service" query:"service"`
-	}
-	Revoke struct {
-		DIDKey        []*RevokeIDSchema `json:"didKey,omitempty" form:"didKey" query:"didKey"`
-		ManagementKey []*RevokeIDSchema `json:"managementKey,omitempty" form:"managementKey" query:"managementKey"`
-		Service       []*RevokeIDSchema `json:"service,omitempty" form:"service" query:"service"`
-	}
+	Add    DIDUpdateAddSchema    `json:"add" form:"add" query:"add"`
+	Revoke DIDUpdateRevokeSchema `json:"revoke" form:"revoke" query:"revoke"`
+}
+
+// DIDUpdateAddSchema is add section of DIDUpdate entry
+type DIDUpdateAddSchema struct {
+	ManagementKey []*ManagementKeySchema `json:"managementKey,omitempty" form:"managementKey" query:"managementKey"`
+	DIDKey        []*DIDKeySchema        `json:"didKey,omitempty" form:"didKey" query:"didKey"`
+	Service       []*ServiceSchema       `json:"service,omitempty" form:"service" query:"service"`
+}
+
+// DIDUpdateRevokeSchema is revoke section of DIDUpdate entry
+type DIDUpdateRevokeSchema struct {
+	ManagementKey []*RevokeIDSchema `json:"managementKey,omitempty" form:"managementKey" query:"managementKey"`
+	DIDKey        []*RevokeIDSchema `json:"didKey,omitempty" form:"didKey" query:"didKey"`
+	Service       []*RevokeIDSchema `json:"service,omitempty" form:"service" query:"service"`
 }
 
 type DIDDeactivationEntrySchema struct{}
 
+type DIDMethodVersionUpgradeEntrySchema struct {
+	DIDMethodVersion string `json:"didMethodVersion" form:"didMethodVersion" query:"didMethodVersion"`
+}
+
 type DIDKeySchema struct {
-	Controller          string   `json:"controller" form:"controller" query:"controller"`
 	ID                  string   `json:"id" form:"id" query:"id"`
+	Type                string   `json:"type" form:"type" query:"type"`
+	Controller          string   `json:"controller" form:"controller" query:"controller"`
 	PublicKeyBase58     string   `json:"publicKeyBase58,omitempty" form:"publicKeyBase58" query:"publicKeyBase58"`
 	PublicKeyPem        string   `json:"publicKeyPem,omitempty" form:"publicKeyPem" query:"publicKeyPem"`
 	Purpose             []string `json:"purpose" form:"purpose" query:"purpose"`
-	Type                string   `json:"type" form:"type" query:"type"`
 	PriorityRequirement *int     `json:"priorityRequirement,omitempty" form:"priorityRequirement" query:"priorityRequirement"`
 	BIP44               string   `json:"bip44,omitempty" form:"bip44" query:"bip44"`
 }
 
 type ManagementKeySchema struct {
-	Controller          string `json:"controller" form:"controller" query:"controller"`
 	ID                  string `json:"id" form:"id" query:"id"`
+	Type                string `json:"type" form:"type" query:"type"`
+	Controller          string `json:"controller" form:"controller" query:"controller"`
 	PublicKeyBase58     string `json:"publicKeyBase58,omitempty" form:"publicKeyBase58" query:"publicKeyBase58"`
 	PublicKeyPem        string `json:"publicKeyPem,omitempty" form:"publicKeyPem" query:"publicKeyPem"`
-	Type                string `json:"type" form:"type" query:"type"`
 	Priority            int    `json:"priority" form:"priority" query:"priority"`
 	PriorityRequirement *int   `json:"priorityRequirement,omitempty" form:"priorityRequirement" query:"priorityRequirement"`
 	BIP44               string `json:"bip44,omitempty" form:"bip44" query:"bip44"`
 }
 
 type ServiceSchema struct {
-	ID                  string `json:"id" form:"id" query:"id"`
-	Type                string `json:"type" form:"type" query:"type"`
-	ServiceEndpoint     string `json:"serviceEndpoint" form:"serviceEndpoint" query:"serviceEndpoint"`
-	PriorityRequirement *int   `json:"priorityRequirement,omitempty" form:"priorityRequirement" query:"priorityRequirement"`
+	ID   string `json:"id" form:"id" query:"id"`
+	Type string `json:"type" form:"type" query:"type"`
+	// ServiceEndpoint is URI string, map[string]interface{} or []interface{} of URIs and maps
+	ServiceEndpoint     interface{} `json:"serviceEndpoint" form:"serviceEndpoint" query:"serviceEndpoint"`
+	PriorityRequirement *int        `json:"priorityRequirement,omitempty" form:"priorityRequirement" query:"priorityRequirement"`
+	// CustomFields are additional service properties, written after standard ones in sorted order
+	CustomFields map[string]json.RawMessage `json:"-" form:"-" query:"-"`
 }
 
 type RevokeIDSchema struct {
 	ID string `json:"id" form:"id" query:"id"`
 }
+
+// MarshalJSON encodes DIDUpdate entry content, add and revoke sections are written only if not empty
+func (s DIDUpdateEntrySchema) MarshalJSON() ([]byte, error) {
+
+	content := struct {
+		Add    *DIDUpdateAddSchema    `json:"add,omitempty"`
+		Revoke *DIDUpdateRevokeSchema `json:"revoke,omitempty"`
+	}{}
+
+	if len(s.Add.ManagementKey)+len(s.Add.DIDKey)+len(s.Add.Service) > 0 {
+		content.Add = &s.Add
+	}
+
+	if len(s.Revoke.ManagementKey)+len(s.Revoke.DIDKey)+len(s.Revoke.Service) > 0 {
+		content.Revoke = &s.Revoke
+	}
+
+	return json.Marshal(content)
+
+}
+
+// MarshalJSON encodes service with its custom fields
+func (s ServiceSchema) MarshalJSON() ([]byte, error) {
+
+	type service ServiceSchema
+
+	return marshalWithProperties(service(s), s.CustomFields)
+
+}
+
+// UnmarshalJSON decodes service, properties other than standard ones become custom fields
+func (s *ServiceSchema) UnmarshalJSON(data []byte) error {
+
+	type service ServiceSchema
+
+	v := service{}
+	err := json.Unmarshal(data, &v)
+	if err != nil {
+		return err
+	}
+
+	var properties map[string]json.RawMessage
+	err = json.Unmarshal(data, &properties)
+	if err != nil {
+		return err
+	}
+
+	v.CustomFields = nil
+	for k, value := range properties {
+		if isServiceProperty(k) {
+			continue
+		}
+		if v.CustomFields == nil {
+			v.CustomFields = make(map[string]json.RawMessage)
+		}
+		v.CustomFields[k] = value
+	}
+
+	*s = ServiceSchema(v)
+
+	return nil
+
+}
+
+// SchemaError describes violation of entry schema
+type SchemaError struct {
+	// Path is JSON pointer to invalid value in entry content, empty for the whole content
+	Path    string `json:"path" form:"path" query:"path"`
+	Message string `json:"message" form:"message" query:"message"`
+}
+
+// SchemaValidationError is returned when entry content doesn't match entry schema
+type SchemaValidationError struct {
+	EntryType   string         `json:"entryType" form:"entryType" query:"entryType"`
+	EntrySchema string         `json:"entrySchema" form:"entrySchema" query:"entrySchema"`
+	Errors      []*SchemaError `json:"errors" form:"errors" query:"errors"`
+}
+
+// Error lists JSON pointers and messages of all schema violations
+func (e *SchemaValidationError) Error() string {
+
+	errs := make([]string, len(e.Errors))
+	for i, v := range e.Errors {
+		errs[i] = fmt.Sprintf("%s: %s", v.Path, v.Message)
+		if v.Path == "" {
+			errs[i] = fmt.Sprintf("/: %s", v.Message)
+		}
+	}
+
+	return fmt.Sprintf("%s entry content doesn't match schema %s: %s", e.EntryType, e.EntrySchema, strings.Join(errs, "; "))
+
+}
+
+// ValidateEntry validates content of DID entry with EntrySchema selected by entry schema version in ExtIDs
+func ValidateEntry(entry *factom.Entry) error {
+
+	if entry == nil || len(entry.ExtIDs) < 2 {
+		return fmt.Errorf("DID entry must have entry type and entry schema ExtIDs")
+	}
+
+	return ValidateEntryContent(string(entry.ExtIDs[0]), string(entry.ExtIDs[1]), entry.Content)
+
+}
+
+// ValidateEntryContent validates content of DID entry of entryType with EntrySchema of entrySchema version,
+// returns *SchemaValidationError with JSON pointers to invalid values
+func ValidateEntryContent(entryType string, entrySchema string, content []byte) error {
+
+	schema, err := GetEntrySchema(entrySchema)
+	if err != nil {
+		return err
+	}
+
+	return schema.ValidateContent(entryType, content)
+
+}
+
+// helper function that validates content of DID entry of entryType against embedded schemas/<entrySchema> JSON schema
+func validateEmbeddedSchema(entryType string, entrySchema string, content []byte) error {
+
+	schemas, err := loadEntrySchemas()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := schemas[path.Join(entrySchema, entryContentSchemas[EntryTypeCreate])]; !ok {
+		return fmt.Errorf("JSON schemas of entry schema %s not found", entrySchema)
+	}
+
+	if entryType == EntryTypeDeactivation {
+		if len(content) > 0 {
+			return &SchemaValidationError{EntryType: entryType, EntrySchema: entrySchema, Errors: []*SchemaError{{Message: "content must be empty"}}}
+		}
+		return nil
+	}
+
+	file, ok := entryContentSchemas[entryType]
+	if !ok {
+		return fmt.Errorf("Unknown entry type %s", entryType)
+	}
+
+	var v interface{}
+	d := json.NewDecoder(bytes.NewReader(content))
+	d.UseNumber()
+	if err = d.Decode(&v); err == nil && d.More() {
+		err = fmt.Errorf("unexpected data after JSON value")
+	}
+	if err != nil {
+		return &SchemaValidationError{EntryType: entryType, EntrySchema: entrySchema, Errors: []*SchemaError{{Message: err.Error()}}}
+	}
+
+	err = schemas[path.Join(entrySchema, file)].Validate(v)
+	if err == nil {
+		return nil
+	}
+
+	verr, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		return err
+	}
+
+	return &SchemaValidationError{EntryType: entryType, EntrySchema: entrySchema, Errors: schemaErrors(verr)}
+
+}
+
+// helper function that compiles embedded JSON schemas once, the result is keyed by "<version>/<file>"
+func loadEntrySchemas() (map[string]*jsonschema.Schema, error) {
+
+	compileSchemasOnce.Do(func() {
+
+		c := jsonschema.NewCompiler()
+		c.Draft = jsonschema.Draft7
+
+		files, err := fs.Glob(schemaFiles, "schemas/*/*.json")
+		if err != nil {
+			compiledSchemasErr = err
+			return
+		}
+
+		for _, f := range files {
+			data, err := schemaFiles.ReadFile(f)
+			if err != nil {
+				compiledSchemasErr = err
+				return
+			}
+			if err = c.AddResource("file:///"+f, bytes.NewReader(data)); err != nil {
+				compiledSchemasErr = err
+				return
+			}
+		}
+
+		schemas := make(map[string]*jsonschema.Schema)
+		for _, f := range files {
+			schema, err := c.Compile("file:///" + f)
+			if err != nil {
+				compiledSchemasErr = err
+				return
+			}
+			schemas[strings.TrimPrefix(f, "schemas/")] = schema
+		}
+
+		compiledSchemas = schemas
+
+	})
+
+	return compiledSchemas, compiledSchemasErr
+
+}
+
+// helper function that flattens validation error into leaf violations
+func schemaErrors(verr *jsonschema.ValidationError) []*SchemaError {
+
+	if len(verr.Causes) == 0 {
+		return []*SchemaError{{Path: verr.InstanceLocation, Message: verr.Message}}
+	}
+
+	var errs []*SchemaError
+	for _, v := range verr.Causes {
+		errs = append(errs, schemaErrors(v)...)
+	}
+
+	return errs
+
+}
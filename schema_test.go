@@ -1,3 +1,93 @@
 package factomdid
 
-// no functions in schema.go
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/FactomProject/factom"
+	"github.com/stretchr/testify/assert"
+)
+
+// helper function that returns JSON pointers of schema violations
+func schemaErrorPaths(t *testing.T, err error) []string {
+
+	verr, ok := err.(*SchemaValidationError)
+	if !assert.True(t, ok, "%v", err) {
+		return nil
+	}
+
+	var paths []string
+	for _, v := range verr.Errors {
+		paths = append(paths, v.Path)
+	}
+
+	return paths
+
+}
+
+func TestValidateEntryContent(t *testing.T) {
+
+	did, _ := addTestKeys(t, NewDID(), "default-did-key", KeyTypeEdDSA, KeyPurposePublic)
+
+	fe, err := did.Create()
+	assert.NoError(t, err)
+	assert.NoError(t, ValidateEntry(fe))
+
+	// DIDKey with all purposes, the schema allows at most 2 and keyAgreement isn't defined by entry schema 1.0.0
+	s := &DIDManagementEntrySchema{}
+	assert.NoError(t, json.Unmarshal(fe.Content, s))
+	s.DIDKey[0].Purpose = []string{KeyPurposePublic, KeyPurposeAuthentication, KeyPurposeKeyAgreement}
+	s.DIDMethodVersion = "0.1.0"
+	content, _ := json.Marshal(s)
+	err = ValidateEntryContent(EntryTypeCreate, EntrySchemaV100, content)
+	assert.Error(t, err)
+	assert.ElementsMatch(t, []string{"/didKey/0/purpose", "/didKey/0/purpose/2", "/didMethodVersion"}, schemaErrorPaths(t, err))
+
+	// capitalized keys of DIDUpdate content are not allowed by the schema
+	err = ValidateEntryContent(EntryTypeUpdate, EntrySchemaV100, []byte(`{"Add":{},"Revoke":{}}`))
+	assert.Error(t, err)
+	assert.Equal(t, []string{""}, schemaErrorPaths(t, err))
+	assert.Contains(t, err.Error(), "Add")
+
+	// generated DIDUpdate entry uses lowercase keys
+	updated := did.Copy()
+	service, _ := NewService("service", "Demo", "https://demo.com")
+	updated.AddService(service)
+	updated.RevokeDIDKey("default-did-key")
+	key, _ := NewDIDKey("new-key", KeyTypeEdDSA)
+	key.AddPurpose(KeyPurposePublic)
+	updated.AddDIDKey(key)
+	fe, err = did.Update(updated, "default-mgmt-key")
+	assert.NoError(t, err)
+	assert.NoError(t, ValidateEntry(fe))
+	assert.True(t, strings.HasPrefix(string(fe.Content), `{"add":`))
+	assert.Contains(t, string(fe.Content), `"revoke":`)
+
+	// service endpoint must be URI
+	err = ValidateEntryContent(EntryTypeUpdate, EntrySchemaV100, []byte(`{"add":{"service":[{"id":"service","type":"Demo","serviceEndpoint":"demo com"}]}}`))
+	assert.Equal(t, []string{"/add/service/0/serviceEndpoint"}, schemaErrorPaths(t, err))
+
+	fe, err = did.UpgradeVersion("0.3.0", "default-mgmt-key")
+	assert.NoError(t, err)
+	assert.NoError(t, ValidateEntry(fe))
+
+	fe, err = did.Deactivate("default-mgmt-key")
+	assert.NoError(t, err)
+	assert.NoError(t, ValidateEntry(fe))
+
+	// DIDDeactivation content must be empty
+	err = ValidateEntryContent(EntryTypeDeactivation, EntrySchemaV100, []byte("{}"))
+	assert.Error(t, err)
+
+	// invalid JSON
+	err = ValidateEntryContent(EntryTypeVersionUpgrade, EntrySchemaV100, []byte(`{"didMethodVersion":"0.3.0"} {}`))
+	assert.Error(t, err)
+
+	_, ok := ValidateEntryContent(EntryTypeUpdate, "2.0.0", []byte("{}")).(*SchemaValidationError)
+	assert.False(t, ok)
+	assert.Error(t, ValidateEntryContent("DIDUnknown", EntrySchemaV100, []byte("{}")))
+	assert.Error(t, ValidateEntry(nil))
+	assert.Error(t, ValidateEntry(&factom.Entry{ExtIDs: [][]byte{[]byte(EntryTypeUpdate)}}))
+
+}
@@ -1,21 +1,100 @@
 package factomdid
 
 import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
 	"strings"
+
+	"gopkg.in/go-playground/validator.v9"
 )
 
 // Service represents a service associated with a DID.
 // A service is an end-point, which can be used to communicate with the DID or to carry out different tasks on behalf of the DID (such as signatures, e.g.)
 type Service struct {
-	Alias               string `json:"alias" form:"alias" query:"alias" validate:"required"`
-	ServiceType         string `json:"serviceType" form:"serviceType" query:"serviceType" validate:"required"`
-	Endpoint            string `json:"endpoint" form:"endpoint" query:"endpoint" validate:"required,url"`
-	PriorityRequirement *int   `json:"priorityRequirement" form:"priorityRequirement" query:"priorityRequirement" validate:"omitempty,min=0"`
-	CustomField         []byte `json:"customFields" form:"customFields" query:"customFields"`
+	Alias       string `json:"alias" form:"alias" query:"alias" validate:"required"`
+	ServiceType string `json:"serviceType" form:"serviceType" query:"serviceType" validate:"required"`
+	// Endpoint is DID Core service endpoint: URI string, map or array of URIs and maps.
+	// Maps and arrays require entry schema 1.0.0+factomdid-ext
+	Endpoint            interface{} `json:"endpoint" form:"endpoint" query:"endpoint" validate:"required,serviceendpoint"`
+	PriorityRequirement *int        `json:"priorityRequirement" form:"priorityRequirement" query:"priorityRequirement" validate:"omitempty,min=0"`
+	// CustomField is JSON object of additional service properties (e.g. DIDComm routingKeys and accept),
+	// written on-chain next to the standard service properties
+	CustomField json.RawMessage `json:"customFields" form:"customFields" query:"customFields"`
+}
+
+// serviceProperties are standard on-chain service properties, custom fields can't override them
+var serviceProperties = []string{"id", "type", "serviceEndpoint", "priorityRequirement"}
+
+// legacyCustomField is custom field which keeps base64 customFields of stored Service, if they aren't JSON object
+const legacyCustomField = "legacyCustomField"
+
+// UnmarshalJSON decodes Service. Before custom fields were JSON object, CustomField was []byte encoded
+// as base64 string, such customFields are migrated: base64 of JSON object becomes the object,
+// other base64 values are kept as string custom field "legacyCustomField"
+func (service *Service) UnmarshalJSON(data []byte) error {
+
+	type plainService Service
+
+	v := &struct {
+		*plainService
+		CustomField json.RawMessage `json:"customFields"`
+	}{plainService: (*plainService)(service)}
+
+	err := json.Unmarshal(data, v)
+	if err != nil {
+		return err
+	}
+
+	service.CustomField = nil
+
+	raw := bytes.TrimSpace(v.CustomField)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return nil
+	}
+
+	if raw[0] != '"' {
+		service.CustomField = raw
+		return nil
+	}
+
+	var encoded string
+	err = json.Unmarshal(raw, &encoded)
+	if err != nil {
+		return err
+	}
+
+	legacy, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return fmt.Errorf("Service custom fields must be JSON object or base64 string: %v", err)
+	}
+
+	if len(legacy) == 0 {
+		return nil
+	}
+
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(legacy, &fields) == nil && fields != nil {
+		service.CustomField = legacy
+		return nil
+	}
+
+	service.CustomField, err = json.Marshal(map[string]string{legacyCustomField: encoded})
+
+	return err
+
 }
 
-// NewService creates new Service
-func NewService(alias string, serviceType string, endpoint string) (*Service, error) {
+// NewService creates new Service, endpoint is URI string, map (e.g. map[string]interface{}) or array (e.g. []string)
+// of URIs and maps
+func NewService(alias string, serviceType string, endpoint interface{}) (*Service, error) {
+
+	endpoint, err := normalizeServiceEndpoint(endpoint)
+	if err != nil {
+		return nil, err
+	}
 
 	service := &Service{}
 	service.Alias = alias
@@ -23,7 +102,7 @@ func NewService(alias string, serviceType string, endpoint string) (*Service, er
 	service.Endpoint = endpoint
 
 	// validate
-	err := validate.Struct(service)
+	err = validate.Struct(service)
 	if err != nil {
 		return nil, err
 	}
@@ -41,11 +120,22 @@ func (service *Service) toSchema(DID string) (*ServiceSchema, error) {
 		return nil, err
 	}
 
+	fields, err := service.customFields()
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint, err := normalizeServiceEndpoint(service.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+
 	s := &ServiceSchema{}
 	s.ID = strings.Join([]string{DID, service.Alias}, "#")
 	s.PriorityRequirement = service.PriorityRequirement
 	s.Type = service.ServiceType
-	s.ServiceEndpoint = service.Endpoint
+	s.ServiceEndpoint = endpoint
+	s.CustomFields = fields
 
 	return s, nil
 
@@ -67,8 +157,255 @@ func (service *Service) toRevokeIDSchema(DID string) (*RevokeIDSchema, error) {
 
 }
 
+// helper function to convert on-chain ServiceSchema of DID into Service
+func serviceFromSchema(s *ServiceSchema, DID string) (*Service, error) {
+
+	alias, err := schemaIDAlias(s.ID, DID)
+	if err != nil {
+		return nil, err
+	}
+
+	service := &Service{}
+	service.Alias = alias
+	service.ServiceType = s.Type
+	service.Endpoint = s.ServiceEndpoint
+	service.PriorityRequirement = s.PriorityRequirement
+
+	err = validate.Struct(service)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(s.CustomFields) > 0 {
+		service.CustomField, err = json.Marshal(s.CustomFields)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = service.customFields()
+	if err != nil {
+		return nil, err
+	}
+
+	return service, nil
+
+}
+
 // SetPriorityRequirement sets PriorityRequirement for Service
 func (service *Service) SetPriorityRequirement(i int) *Service {
 	service.PriorityRequirement = &i
 	return service
 }
+
+// SetCustomFields sets additional service properties, values may be any JSON values including maps and arrays
+func (service *Service) SetCustomFields(fields map[string]interface{}) (*Service, error) {
+
+	if len(fields) == 0 {
+		service.CustomField = nil
+		return service, nil
+	}
+
+	// map keys are sorted, so equal fields are always encoded the same way
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	prev := service.CustomField
+	service.CustomField = data
+
+	_, err = service.customFields()
+	if err != nil {
+		service.CustomField = prev
+		return nil, err
+	}
+
+	return service, nil
+
+}
+
+// GetCustomFields returns additional service properties
+func (service *Service) GetCustomFields() (map[string]interface{}, error) {
+
+	fields := make(map[string]interface{})
+
+	if len(service.CustomField) == 0 {
+		return fields, nil
+	}
+
+	err := json.Unmarshal(service.CustomField, &fields)
+	if err != nil {
+		return nil, fmt.Errorf("Service custom fields must be JSON object: %v", err)
+	}
+
+	return fields, nil
+
+}
+
+// helper function that parses and validates custom fields of Service, DIDComm fields and endpoint are validated too
+func (service *Service) customFields() (map[string]json.RawMessage, error) {
+
+	var fields map[string]json.RawMessage
+
+	if len(service.CustomField) > 0 {
+
+		err := json.Unmarshal(service.CustomField, &fields)
+		if err != nil || fields == nil {
+			return nil, fmt.Errorf("Service custom fields must be JSON object")
+		}
+
+		for k := range fields {
+			if k == "" || isServiceProperty(k) {
+				return nil, fmt.Errorf("Service custom field %q is not allowed", k)
+			}
+		}
+
+	}
+
+	if service.ServiceType == ServiceTypeDIDCommMessaging {
+
+		err := checkDIDCommFields(fields)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = didCommEndpoints(service.Endpoint, fields)
+		if err != nil {
+			return nil, err
+		}
+
+	}
+
+	return fields, nil
+
+}
+
+// helper function that converts service endpoint into JSON values (string, map[string]interface{} or []interface{}),
+// so endpoints set in Go and resolved from the chain are equal
+func normalizeServiceEndpoint(endpoint interface{}) (interface{}, error) {
+
+	switch endpoint.(type) {
+	case nil, string:
+		return endpoint, nil
+	}
+
+	data, err := json.Marshal(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("Service endpoint must be URI, map or array of URIs and maps: %v", err)
+	}
+
+	var v interface{}
+	err = json.Unmarshal(data, &v)
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+
+}
+
+// helper function that checks service endpoint: URI string, not empty map or not empty array of URIs and maps
+func checkServiceEndpoint(endpoint interface{}) error {
+
+	endpoint, err := normalizeServiceEndpoint(endpoint)
+	if err != nil {
+		return err
+	}
+
+	switch e := endpoint.(type) {
+	case string:
+		return checkServiceEndpointURI(e)
+	case map[string]interface{}:
+		if len(e) == 0 {
+			return fmt.Errorf("Service endpoint map must not be empty")
+		}
+		return nil
+	case []interface{}:
+		if len(e) == 0 {
+			return fmt.Errorf("Service endpoint array must not be empty")
+		}
+		for _, item := range e {
+			switch v := item.(type) {
+			case string:
+				err = checkServiceEndpointURI(v)
+			case map[string]interface{}:
+				if len(v) == 0 {
+					err = fmt.Errorf("Service endpoint map must not be empty")
+				}
+			default:
+				err = fmt.Errorf("Service endpoint array must contain URIs and maps")
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return fmt.Errorf("Service endpoint must be URI, map or array of URIs and maps")
+
+}
+
+// helper function that checks service endpoint URI
+func checkServiceEndpointURI(uri string) error {
+
+	if validate.Var(uri, "required,url") != nil {
+		return fmt.Errorf("Service endpoint %q is not valid URI", uri)
+	}
+
+	return nil
+
+}
+
+// helper function that validates serviceendpoint field
+func validateServiceEndpoint(fl validator.FieldLevel) bool {
+	return checkServiceEndpoint(fl.Field().Interface()) == nil
+}
+
+// helper function that checks whether name is standard service property, JSON decoding is case-insensitive
+func isServiceProperty(name string) bool {
+
+	for _, v := range serviceProperties {
+		if strings.EqualFold(v, name) {
+			return true
+		}
+	}
+
+	return false
+
+}
+
+// helper function that encodes JSON object and appends properties to it in sorted order
+func marshalWithProperties(v interface{}, properties map[string]json.RawMessage) ([]byte, error) {
+
+	data, err := json.Marshal(v)
+	if err != nil || len(properties) == 0 {
+		return data, err
+	}
+
+	keys := make([]string, 0, len(properties))
+	for k := range properties {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	buf := bytes.NewBuffer(data[:len(data)-1])
+	for _, k := range keys {
+		key, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteByte(',')
+		buf.Write(key)
+		buf.WriteByte(':')
+		err = json.Compact(buf, properties[k])
+		if err != nil {
+			return nil, err
+		}
+	}
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+
+}
@@ -1,6 +1,7 @@
 package factomdid
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -34,6 +35,63 @@ func TestNewService(t *testing.T) {
 	assert.Nil(t, s6)
 	assert.Error(t, err)
 
+	// endpoint maps and arrays
+	s7, err := NewService("hub", "IdentityHub", map[string]interface{}{"origins": []string{"https://hub.example.com"}})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"origins": []interface{}{"https://hub.example.com"}}, s7.Endpoint)
+
+	s8, err := NewService("hub", "IdentityHub", []interface{}{"https://hub.example.com", map[string]string{"uri": "https://hub2.example.com"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"https://hub.example.com", map[string]interface{}{"uri": "https://hub2.example.com"}}, s8.Endpoint)
+
+	for _, endpoint := range []interface{}{
+		nil,
+		2,
+		map[string]interface{}{},
+		[]string{},
+		[]string{"example.com"},
+		[]interface{}{"https://hub.example.com", 2},
+		[]interface{}{map[string]interface{}{}},
+	} {
+		s, err := NewService("hub", "IdentityHub", endpoint)
+		assert.Nil(t, s, "%v", endpoint)
+		assert.Error(t, err, "%v", endpoint)
+	}
+
+}
+
+func TestServiceEndpointToSchema(t *testing.T) {
+
+	did := "did:factom:301a57c2e753d061928cf6b6a692ea052885d75d2af5640e9b5cbc8897bbf7d5"
+
+	s, _ := NewService("hub", "IdentityHub", []string{"https://hub.example.com", "https://hub2.example.com"})
+	schema, err := s.toSchema(did)
+	assert.NoError(t, err)
+	data, err := json.Marshal(schema)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"id":"`+did+`#hub","type":"IdentityHub","serviceEndpoint":["https://hub.example.com","https://hub2.example.com"]}`, string(data))
+
+	decoded := &ServiceSchema{}
+	assert.NoError(t, json.Unmarshal(data, decoded))
+	restored, err := serviceFromSchema(decoded, did)
+	assert.NoError(t, err)
+	assert.Equal(t, s, restored)
+
+	// endpoint set directly is normalized and validated
+	s.Endpoint = map[string][]string{"origins": {"https://hub.example.com"}}
+	schema, err = s.toSchema(did)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"origins": []interface{}{"https://hub.example.com"}}, schema.ServiceEndpoint)
+
+	s.Endpoint = []string{}
+	_, err = s.toSchema(did)
+	assert.Error(t, err)
+
+	// on-chain endpoint must be valid too
+	decoded.ServiceEndpoint = []interface{}{"not uri"}
+	_, err = serviceFromSchema(decoded, did)
+	assert.Error(t, err)
+
 }
 
 func TestServiceToSchema(t *testing.T) {
@@ -53,3 +111,97 @@ func TestServiceToSchema(t *testing.T) {
 	assert.NoError(t, err)
 
 }
+
+func TestServiceCustomFields(t *testing.T) {
+
+	did := "did:factom:301a57c2e753d061928cf6b6a692ea052885d75d2af5640e9b5cbc8897bbf7d5"
+
+	s, _ := NewService("hub", "IdentityHub", "https://hub.example.com")
+	_, err := s.SetCustomFields(map[string]interface{}{"version": 2, "instances": []string{"a", "b"}})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"instances":["a","b"],"version":2}`, string(s.CustomField))
+
+	fields, err := s.GetCustomFields()
+	assert.NoError(t, err)
+	assert.Equal(t, float64(2), fields["version"])
+
+	// custom fields are written after standard properties
+	schema, err := s.toSchema(did)
+	assert.NoError(t, err)
+	data, err := json.Marshal(schema)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"id":"`+did+`#hub","type":"IdentityHub","serviceEndpoint":"https://hub.example.com","instances":["a","b"],"version":2}`, string(data))
+
+	decoded := &ServiceSchema{}
+	assert.NoError(t, json.Unmarshal(data, decoded))
+	assert.Equal(t, schema, decoded)
+
+	restored, err := serviceFromSchema(decoded, did)
+	assert.NoError(t, err)
+	assert.Equal(t, s, restored)
+
+	// standard properties can't be overridden
+	_, err = s.SetCustomFields(map[string]interface{}{"ServiceEndpoint": "https://other.example.com"})
+	assert.Error(t, err)
+	assert.Equal(t, `{"instances":["a","b"],"version":2}`, string(s.CustomField))
+
+	s.CustomField = []byte(`["a"]`)
+	_, err = s.toSchema(did)
+	assert.Error(t, err)
+
+	_, err = s.SetCustomFields(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, s.CustomField)
+	data, _ = json.Marshal(&ServiceSchema{ID: did + "#hub", Type: "IdentityHub", ServiceEndpoint: "https://hub.example.com"})
+	assert.Equal(t, `{"id":"`+did+`#hub","type":"IdentityHub","serviceEndpoint":"https://hub.example.com"}`, string(data))
+
+}
+
+func TestServiceUnmarshalJSON(t *testing.T) {
+
+	testCases := []struct {
+		Data        string
+		CustomField string
+		Error       bool
+	}{
+		// JSON object
+		{`{"alias":"hub","serviceType":"IdentityHub","endpoint":"https://hub.example.com","customFields":{"version":2}}`, `{"version":2}`, false},
+		// stored base64 of JSON object is migrated
+		{`{"alias":"hub","serviceType":"IdentityHub","endpoint":"https://hub.example.com","customFields":"eyJ2ZXJzaW9uIjoyfQ=="}`, `{"version":2}`, false},
+		// other stored base64 is kept under legacyCustomField
+		{`{"alias":"hub","serviceType":"IdentityHub","endpoint":"https://hub.example.com","customFields":"AQID"}`, `{"legacyCustomField":"AQID"}`, false},
+		{`{"alias":"hub","serviceType":"IdentityHub","endpoint":"https://hub.example.com","customFields":""}`, ``, false},
+		{`{"alias":"hub","serviceType":"IdentityHub","endpoint":"https://hub.example.com","customFields":null}`, ``, false},
+		{`{"alias":"hub","serviceType":"IdentityHub","endpoint":"https://hub.example.com"}`, ``, false},
+		{`{"alias":"hub","serviceType":"IdentityHub","endpoint":"https://hub.example.com","customFields":"not base64"}`, ``, true},
+	}
+
+	for _, v := range testCases {
+
+		s := &Service{}
+		err := json.Unmarshal([]byte(v.Data), s)
+		if v.Error {
+			assert.Error(t, err)
+			continue
+		}
+
+		assert.NoError(t, err)
+		assert.Equal(t, "hub", s.Alias)
+		assert.Equal(t, "https://hub.example.com", s.Endpoint)
+		assert.Equal(t, v.CustomField, string(s.CustomField))
+
+	}
+
+	// JSON object custom fields survive round trip
+	s, _ := NewService("hub", "IdentityHub", "https://hub.example.com")
+	_, err := s.SetCustomFields(map[string]interface{}{"instances": []string{"a", "b"}})
+	assert.NoError(t, err)
+
+	data, err := json.Marshal(s)
+	assert.NoError(t, err)
+
+	restored := &Service{}
+	assert.NoError(t, json.Unmarshal(data, restored))
+	assert.Equal(t, s, restored)
+
+}
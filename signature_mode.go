@@ -0,0 +1,61 @@
+package factomdid
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/sha512"
+	"fmt"
+)
+
+// SignatureMode defines what exactly is signed by AbstractKey.SignWithMode
+type SignatureMode string
+
+const (
+
+	// Signature modes
+
+	// SignatureModePrehashed signs SHA-256 hash of the message, used by Factom DID spec for entries and by AbstractKey.Sign
+	SignatureModePrehashed SignatureMode = "prehashed"
+	// SignatureModePureEd25519 signs the raw message with Ed25519 (RFC 8032), used by JWS EdDSA and Linked Data proofs
+	SignatureModePureEd25519 SignatureMode = "ed25519"
+	// SignatureModeEd25519ph signs SHA-512 hash of the message with Ed25519ph (RFC 8032)
+	SignatureModeEd25519ph SignatureMode = "ed25519ph"
+	// SignatureModeDigest signs the message as is, the message must be SHA-256 digest, e.g. ES256K and ES256 over raw digest
+	SignatureModeDigest SignatureMode = "digest"
+)
+
+// helper function that returns data and crypto.SignerOpts passed to KeySuite for the mode.
+// keyOpts are SignerOpts of the key used with SHA-256 digests
+func (mode SignatureMode) prepare(message []byte, keyOpts crypto.SignerOpts) ([]byte, crypto.SignerOpts, error) {
+
+	switch mode {
+	case SignatureModePrehashed:
+
+		hashed := sha256.Sum256(message)
+
+		return hashed[:], keyOpts, nil
+
+	case SignatureModeDigest:
+
+		if len(message) != sha256.Size {
+			return nil, nil, fmt.Errorf("%s signature mode requires %d bytes SHA-256 digest", mode, sha256.Size)
+		}
+
+		return message, keyOpts, nil
+
+	case SignatureModePureEd25519:
+
+		return message, crypto.Hash(0), nil
+
+	case SignatureModeEd25519ph:
+
+		hashed := sha512.Sum512(message)
+
+		return hashed[:], &ed25519.Options{Hash: crypto.SHA512}, nil
+
+	}
+
+	return nil, nil, fmt.Errorf("Invalid signature mode %s", mode)
+
+}
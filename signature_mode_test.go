@@ -0,0 +1,113 @@
+package factomdid
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/frankbraun/dcrd/dcrec/secp256k1"
+	"github.com/stretchr/testify/assert"
+)
+
+// helper function that creates Ed25519 AbstractKey from hex seed
+func newTestEd25519Key(seedHex string) *AbstractKey {
+
+	seed, _ := hex.DecodeString(seedHex)
+
+	key := &AbstractKey{KeyType: KeyTypeEdDSA}
+	key.PrivateKey = ed25519.NewKeyFromSeed(seed)
+	key.PublicKey = ed25519.PrivateKey(key.PrivateKey).Public().(ed25519.PublicKey)
+
+	return key
+
+}
+
+func TestSignatureModePureEd25519(t *testing.T) {
+
+	// RFC 8032 7.1 TEST 1
+	key := newTestEd25519Key("9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60")
+	assert.Equal(t, "d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a", hex.EncodeToString(key.PublicKey))
+
+	signature, err := key.SignWithMode([]byte{}, SignatureModePureEd25519)
+	assert.NoError(t, err)
+	assert.Equal(t, "e5564300c360ac729086e2cc806e828a84877f1eb8e5d974d873e065224901555fb8821590a33bacc61e39701cf9b46bd25bf5f0595bbe24655141438e7a100b", hex.EncodeToString(signature))
+
+	v, err := key.VerifyWithMode([]byte{}, signature, SignatureModePureEd25519)
+	assert.NoError(t, err)
+	assert.True(t, v)
+
+	// pure signature is a standard Ed25519 signature
+	assert.True(t, ed25519.Verify(key.PublicKey, []byte{}, signature))
+
+	// pure signature is not valid in prehashed mode
+	v, _ = key.Verify([]byte{}, signature)
+	assert.False(t, v)
+
+}
+
+func TestSignatureModeEd25519ph(t *testing.T) {
+
+	// RFC 8032 7.3 TEST abc
+	key := newTestEd25519Key("833fe62409237b9d62ec77587520911e9a759cec1d19755b7da901b96dca3d42")
+	assert.Equal(t, "ec172b93ad5e563bf4932c70e1245034c35467ef2efd4d64ebf819683467e2bf", hex.EncodeToString(key.PublicKey))
+
+	signature, err := key.SignWithMode([]byte("abc"), SignatureModeEd25519ph)
+	assert.NoError(t, err)
+	assert.Equal(t, "98a70222f0b8121aa9d30f813d683f809e462b469c7ff87639499bb94e6dae4131f85042463c2a355a2003d062adf5aaa10b8c61e636062aaad11c2a26083406", hex.EncodeToString(signature))
+
+	v, err := key.VerifyWithMode([]byte("abc"), signature, SignatureModeEd25519ph)
+	assert.NoError(t, err)
+	assert.True(t, v)
+
+	v, _ = key.VerifyWithMode([]byte("abc"), signature, SignatureModePureEd25519)
+	assert.False(t, v)
+
+}
+
+func TestSignatureModeDigest(t *testing.T) {
+
+	key := &AbstractKey{KeyType: KeyTypeECDSA}
+	key.generateRandomKeys()
+
+	digest := sha256.Sum256([]byte("Test"))
+
+	// ES256K over raw digest
+	signature, err := key.SignWithMode(digest[:], SignatureModeDigest)
+	assert.NoError(t, err)
+
+	pubKey, _ := secp256k1.ParsePubKey(key.PublicKey, secp256k1.S256())
+	sig, _ := secp256k1.ParseSignature(signature, secp256k1.S256())
+	assert.True(t, sig.Verify(digest[:], pubKey))
+
+	// digest mode signature equals prehashed mode signature of the message
+	v, err := key.Verify([]byte("Test"), signature)
+	assert.NoError(t, err)
+	assert.True(t, v)
+
+	v, err = key.VerifyWithMode(digest[:], signature, SignatureModeDigest)
+	assert.NoError(t, err)
+	assert.True(t, v)
+
+	// digest must be 32 bytes
+	signature, err = key.SignWithMode([]byte("Test"), SignatureModeDigest)
+	assert.Nil(t, signature)
+	assert.Error(t, err)
+
+	// Ed25519 modes are not supported by ECDSA and RSA keys
+	signature, err = key.SignWithMode([]byte("Test"), SignatureModePureEd25519)
+	assert.Nil(t, signature)
+	assert.Error(t, err)
+
+	rsa := &AbstractKey{KeyType: KeyTypeRSA}
+	rsa.generateRandomKeys()
+	signature, err = rsa.SignWithMode([]byte("Test"), SignatureModeEd25519ph)
+	assert.Nil(t, signature)
+	assert.Error(t, err)
+
+	// invalid mode
+	signature, err = key.SignWithMode([]byte("Test"), SignatureMode("invalid"))
+	assert.Nil(t, signature)
+	assert.Error(t, err)
+
+}
@@ -0,0 +1,212 @@
+package factomdid
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// VanityOption configures NewVanityDID
+type VanityOption func(*vanityOptions)
+
+type vanityOptions struct {
+	ctx     context.Context
+	workers int
+}
+
+const (
+	// MaxVanityPrefixSize is maximum length of hex chain ID prefix accepted by NewVanityDID
+	MaxVanityPrefixSize = 16
+
+	// number of attempts between context checks
+	vanityBatchSize = 1024
+)
+
+// WithVanityContext makes NewVanityDID stop when ctx is done
+func WithVanityContext(ctx context.Context) VanityOption {
+	return func(o *vanityOptions) {
+		o.ctx = ctx
+	}
+}
+
+// WithVanityWorkers sets number of goroutines grinding nonces, runtime.NumCPU() by default
+func WithVanityWorkers(workers int) VanityOption {
+	return func(o *vanityOptions) {
+		o.workers = workers
+	}
+}
+
+// VanityExpectedWork returns expected number of nonces to try until DID chain ID starts with hex prefix
+func VanityExpectedWork(prefix string) (float64, error) {
+
+	prefix, err := normalizeVanityPrefix(prefix)
+	if err != nil {
+		return 0, err
+	}
+
+	return math.Pow(16, float64(len(prefix))), nil
+
+}
+
+// NewVanityDID generates new blank DID document, which chain ID starts with hex prefix.
+// Random nonces are tried by several goroutines (see WithVanityWorkers), every character of prefix makes
+// expected work (see VanityExpectedWork) 16 times bigger. Use WithVanityContext to cancel or limit the search
+func NewVanityDID(prefix string, opts ...VanityOption) (*DID, error) {
+
+	prefix, err := normalizeVanityPrefix(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	o := &vanityOptions{ctx: context.Background(), workers: runtime.NumCPU()}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if o.workers < 1 {
+		return nil, fmt.Errorf("Number of workers must be positive")
+	}
+
+	ctx, cancel := context.WithCancel(o.ctx)
+	defer cancel()
+
+	var attempts uint64
+	var once sync.Once
+	var found []byte
+	var wg sync.WaitGroup
+	errs := make(chan error, o.workers)
+
+	for i := 0; i < o.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			nonce, err := grindVanityNonce(ctx, prefix, &attempts)
+			if err != nil {
+				errs <- err
+				return
+			}
+			if nonce != nil {
+				once.Do(func() {
+					found = nonce
+					cancel()
+				})
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	if found == nil {
+		for err := range errs {
+			return nil, err
+		}
+		expected, _ := VanityExpectedWork(prefix)
+		return nil, fmt.Errorf("Vanity DID search stopped after %d of %.0f expected attempts: %v", atomic.LoadUint64(&attempts), expected, o.ctx.Err())
+	}
+
+	did, err := NewDIDWithNonce(found)
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.HasPrefix(did.GetChainID(), prefix) {
+		return nil, fmt.Errorf("Vanity DID %s doesn't match prefix %s", did.ID, prefix)
+	}
+
+	return did, nil
+
+}
+
+// helper function that tries nonces starting from random one until chain ID matches prefix or ctx is done.
+// Returns nil nonce if ctx is done
+func grindVanityNonce(ctx context.Context, prefix string, attempts *uint64) ([]byte, error) {
+
+	nonce, err := generateNonce(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	// chain ID is sha256(sha256(ExtID 1) || sha256(ExtID 2) || sha256(nonce)), the first 2 hashes are constant
+	h0 := sha256.Sum256([]byte(EntryTypeCreate))
+	h1 := sha256.Sum256([]byte(LatestEntrySchema))
+	buf := make([]byte, 3*sha256.Size)
+	copy(buf, h0[:])
+	copy(buf[sha256.Size:], h1[:])
+
+	counter := binary.BigEndian.Uint64(nonce[len(nonce)-8:])
+
+	for {
+
+		select {
+		case <-ctx.Done():
+			return nil, nil
+		default:
+		}
+
+		for i := 0; i < vanityBatchSize; i++ {
+
+			counter++
+			binary.BigEndian.PutUint64(nonce[len(nonce)-8:], counter)
+
+			h2 := sha256.Sum256(nonce)
+			copy(buf[2*sha256.Size:], h2[:])
+			chainID := sha256.Sum256(buf)
+
+			if hasHexPrefix(chainID[:], prefix) {
+				atomic.AddUint64(attempts, uint64(i+1))
+				return nonce, nil
+			}
+
+		}
+
+		atomic.AddUint64(attempts, vanityBatchSize)
+
+	}
+
+}
+
+// helper function that checks that lowercase hex encoding of b starts with prefix
+func hasHexPrefix(b []byte, prefix string) bool {
+
+	const hextable = "0123456789abcdef"
+
+	for i := 0; i < len(prefix); i++ {
+		nibble := b[i/2] >> 4
+		if i%2 == 1 {
+			nibble = b[i/2] & 0x0f
+		}
+		if hextable[nibble] != prefix[i] {
+			return false
+		}
+	}
+
+	return true
+
+}
+
+// helper function that validates and lowercases hex prefix
+func normalizeVanityPrefix(prefix string) (string, error) {
+
+	prefix = strings.ToLower(prefix)
+
+	if len(prefix) > MaxVanityPrefixSize {
+		return "", fmt.Errorf("Vanity prefix must be at most %d characters", MaxVanityPrefixSize)
+	}
+
+	for _, c := range prefix {
+		if !strings.ContainsRune("0123456789abcdef", c) {
+			return "", fmt.Errorf("Vanity prefix must be hex, got %s", prefix)
+		}
+	}
+
+	return prefix, nil
+
+}
@@ -0,0 +1,74 @@
+package factomdid
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FactomProject/factom"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewVanityDID(t *testing.T) {
+
+	did, err := NewVanityDID("AB", WithVanityWorkers(2))
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(did.GetChainID(), "ab"))
+	assert.Equal(t, 3, len(did.ExtIDs))
+	assert.Equal(t, did.GetChainID(), factom.ChainIDFromFields(did.ExtIDs))
+
+	// DIDManagement entry creates vanity chain
+	addTestKeys(t, did, "default-did-key", KeyTypeEdDSA, KeyPurposePublic)
+	fe, err := did.Create()
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(factom.NewChain(fe).ChainID, "ab"))
+
+	did, err = NewVanityDID("")
+	assert.NoError(t, err)
+	assert.NotNil(t, did)
+
+	_, err = NewVanityDID("xyz")
+	assert.Error(t, err)
+
+	_, err = NewVanityDID(strings.Repeat("a", MaxVanityPrefixSize+1))
+	assert.Error(t, err)
+
+	_, err = NewVanityDID("ab", WithVanityWorkers(0))
+	assert.Error(t, err)
+
+	// search is stopped by context
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = NewVanityDID(strings.Repeat("0", MaxVanityPrefixSize), WithVanityContext(ctx), WithVanityWorkers(2))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), context.DeadlineExceeded.Error())
+
+}
+
+func TestVanityExpectedWork(t *testing.T) {
+
+	work, err := VanityExpectedWork("")
+	assert.NoError(t, err)
+	assert.Equal(t, float64(1), work)
+
+	work, err = VanityExpectedWork("fac")
+	assert.NoError(t, err)
+	assert.Equal(t, float64(4096), work)
+
+	_, err = VanityExpectedWork("g")
+	assert.Error(t, err)
+
+}
+
+func TestHasHexPrefix(t *testing.T) {
+
+	b := []byte{0xab, 0xcd}
+	assert.True(t, hasHexPrefix(b, ""))
+	assert.True(t, hasHexPrefix(b, "a"))
+	assert.True(t, hasHexPrefix(b, "abc"))
+	assert.True(t, hasHexPrefix(b, "abcd"))
+	assert.False(t, hasHexPrefix(b, "b"))
+	assert.False(t, hasHexPrefix(b, "abd"))
+
+}
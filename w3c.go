@@ -0,0 +1,164 @@
+package factomdid
+
+import (
+	"encoding/json"
+)
+
+const (
+	// W3CContext is JSON-LD context of W3C DID document
+	W3CContext = "https://www.w3.org/ns/did/v1"
+)
+
+// W3CDocument describes W3C DID document (DID Core) built from DID
+type W3CDocument struct {
+	Context            string                   `json:"@context" form:"@context" query:"@context"`
+	ID                 string                   `json:"id" form:"id" query:"id"`
+	VerificationMethod []*W3CVerificationMethod `json:"verificationMethod,omitempty" form:"verificationMethod" query:"verificationMethod"`
+	Authentication     []string                 `json:"authentication,omitempty" form:"authentication" query:"authentication"`
+	AssertionMethod    []string                 `json:"assertionMethod,omitempty" form:"assertionMethod" query:"assertionMethod"`
+	KeyAgreement       []string                 `json:"keyAgreement,omitempty" form:"keyAgreement" query:"keyAgreement"`
+	Service            []*W3CService            `json:"service,omitempty" form:"service" query:"service"`
+}
+
+// W3CVerificationMethod describes verification method of W3C DID document
+type W3CVerificationMethod struct {
+	ID                 string `json:"id" form:"id" query:"id"`
+	Type               string `json:"type" form:"type" query:"type"`
+	Controller         string `json:"controller" form:"controller" query:"controller"`
+	PublicKeyBase58    string `json:"publicKeyBase58,omitempty" form:"publicKeyBase58" query:"publicKeyBase58"`
+	PublicKeyPem       string `json:"publicKeyPem,omitempty" form:"publicKeyPem" query:"publicKeyPem"`
+	PublicKeyMultibase string `json:"publicKeyMultibase,omitempty" form:"publicKeyMultibase" query:"publicKeyMultibase"`
+	PublicKeyJwk       *JWK   `json:"publicKeyJwk,omitempty" form:"publicKeyJwk" query:"publicKeyJwk"`
+}
+
+// W3CService describes service of W3C DID document.
+// ServiceEndpoint is URI string, map, array of URIs and maps or *W3CServiceEndpoint map, custom fields of Service are exported as Properties
+type W3CService struct {
+	ID              string                     `json:"id" form:"id" query:"id"`
+	Type            string                     `json:"type" form:"type" query:"type"`
+	ServiceEndpoint interface{}                `json:"serviceEndpoint" form:"serviceEndpoint" query:"serviceEndpoint"`
+	Properties      map[string]json.RawMessage `json:"-" form:"-" query:"-"`
+}
+
+// W3CServiceEndpoint describes DIDCommMessaging service endpoint map of W3C DID document
+type W3CServiceEndpoint struct {
+	URI         string   `json:"uri" form:"uri" query:"uri"`
+	Accept      []string `json:"accept,omitempty" form:"accept" query:"accept"`
+	RoutingKeys []string `json:"routingKeys,omitempty" form:"routingKeys" query:"routingKeys"`
+}
+
+// MarshalJSON encodes service with its additional properties
+func (s W3CService) MarshalJSON() ([]byte, error) {
+
+	type service W3CService
+
+	return marshalWithProperties(service(s), s.Properties)
+
+}
+
+// ToW3C exports DID document into W3C DID document.
+// DIDKeys become verification methods referenced by authentication, assertionMethod (publicKey purpose) and keyAgreement relationships
+func (did *DID) ToW3C() (*W3CDocument, error) {
+
+	err := did.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &W3CDocument{}
+	doc.Context = W3CContext
+	doc.ID = did.ID
+
+	for i := range did.DIDKeys {
+
+		s, err := did.DIDKeys[i].toSchema(did.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		jwk, err := did.DIDKeys[i].ToJWK()
+		if err != nil {
+			return nil, err
+		}
+
+		vm := &W3CVerificationMethod{}
+		vm.ID = s.ID
+		vm.Type = s.Type
+		vm.Controller = s.Controller
+		vm.PublicKeyBase58 = s.PublicKeyBase58
+		vm.PublicKeyPem = s.PublicKeyPem
+		vm.PublicKeyJwk = jwk
+
+		// publicKeyMultibase is optional, custom key types may have no multicodec
+		vm.PublicKeyMultibase, _ = did.DIDKeys[i].ToMultibase()
+
+		doc.VerificationMethod = append(doc.VerificationMethod, vm)
+
+		for _, p := range s.Purpose {
+			switch p {
+			case KeyPurposeAuthentication:
+				doc.Authentication = append(doc.Authentication, s.ID)
+			case KeyPurposePublic:
+				doc.AssertionMethod = append(doc.AssertionMethod, s.ID)
+			case KeyPurposeKeyAgreement:
+				doc.KeyAgreement = append(doc.KeyAgreement, s.ID)
+			}
+		}
+
+	}
+
+	for i := range did.Services {
+
+		s, err := did.Services[i].toSchema(did.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		service, err := w3cService(s)
+		if err != nil {
+			return nil, err
+		}
+
+		doc.Service = append(doc.Service, service)
+
+	}
+
+	return doc, nil
+
+}
+
+// helper function that converts ServiceSchema into W3CService, DIDComm routingKeys and accept of URI endpoint
+// become endpoint map, endpoint maps and arrays are exported as they are
+func w3cService(s *ServiceSchema) (*W3CService, error) {
+
+	service := &W3CService{ID: s.ID, Type: s.Type, ServiceEndpoint: s.ServiceEndpoint}
+
+	properties := make(map[string]json.RawMessage)
+	for k, v := range s.CustomFields {
+		properties[k] = v
+	}
+
+	uri, ok := s.ServiceEndpoint.(string)
+
+	if s.Type == ServiceTypeDIDCommMessaging && ok {
+
+		endpoint, err := didCommEndpoint(uri, properties)
+		if err != nil {
+			return nil, err
+		}
+		delete(properties, didCommRoutingKeys)
+		delete(properties, didCommAccept)
+
+		if len(endpoint.RoutingKeys)+len(endpoint.Accept) > 0 {
+			service.ServiceEndpoint = endpoint
+		}
+
+	}
+
+	if len(properties) > 0 {
+		service.Properties = properties
+	}
+
+	return service, nil
+
+}
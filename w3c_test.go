@@ -0,0 +1,83 @@
+package factomdid
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToW3C(t *testing.T) {
+
+	did := NewDID()
+
+	authKey, _ := NewDIDKey("auth-key", KeyTypeEdDSA)
+	authKey.AddPurpose(KeyPurposeAuthentication)
+	publicKey, _ := NewDIDKey("public-key", KeyTypeRSA)
+	publicKey.AddPurpose(KeyPurposePublic)
+	kaKey, _ := authKey.DeriveKeyAgreementKey("ka-key")
+	mgmtKey, _ := NewManagementKey("mgmt-key", KeyTypeECDSA, 0)
+	service, _ := NewService("kyc", "KYC", "https://kyc.example.com")
+
+	did.AddDIDKey(authKey)
+	did.AddDIDKey(publicKey)
+	did.AddDIDKey(kaKey)
+	did.AddManagementKey(mgmtKey)
+	did.AddService(service)
+
+	doc, err := did.ToW3C()
+	assert.NoError(t, err)
+	assert.Equal(t, W3CContext, doc.Context)
+	assert.Equal(t, did.ID, doc.ID)
+	assert.Equal(t, 3, len(doc.VerificationMethod))
+	assert.Equal(t, []string{did.ID + "#auth-key"}, doc.Authentication)
+	assert.Equal(t, []string{did.ID + "#public-key"}, doc.AssertionMethod)
+	assert.Equal(t, []string{did.ID + "#ka-key"}, doc.KeyAgreement)
+	assert.NotEmpty(t, doc.VerificationMethod[0].PublicKeyBase58)
+	assert.NotEmpty(t, doc.VerificationMethod[1].PublicKeyPem)
+	assert.Equal(t, JWKCurveX25519, doc.VerificationMethod[2].PublicKeyJwk.Crv)
+	assert.Equal(t, 1, len(doc.Service))
+	assert.Equal(t, "https://kyc.example.com", doc.Service[0].ServiceEndpoint)
+
+	// invalid DID document
+	doc, err = NewDID().ToW3C()
+	assert.Nil(t, doc)
+	assert.Error(t, err)
+
+}
+
+func TestToW3CServiceEndpoint(t *testing.T) {
+
+	did, _ := addTestKeys(t, NewDID(), "default-did-key", KeyTypeEdDSA, KeyPurposePublic)
+	didcomm, _ := NewDIDCommService("didcomm", "https://agent.example.com", []string{"did:example:mediator#key-1"}, []string{DIDCommProfileV2})
+	didcomm.SetCustomFields(map[string]interface{}{"routingKeys": []string{"did:example:mediator#key-1"}, "accept": []string{DIDCommProfileV2}, "label": "agent"})
+	hub, _ := NewService("hub", "IdentityHub", "https://hub.example.com")
+	hub.SetCustomFields(map[string]interface{}{"version": 2})
+	did.AddService(didcomm)
+	did.AddService(hub)
+
+	doc, err := did.ToW3C()
+	assert.NoError(t, err)
+	assert.Equal(t, &W3CServiceEndpoint{URI: "https://agent.example.com", Accept: []string{DIDCommProfileV2}, RoutingKeys: []string{"did:example:mediator#key-1"}}, doc.Service[0].ServiceEndpoint)
+
+	data, err := json.Marshal(doc.Service)
+	assert.NoError(t, err)
+	assert.Equal(t, `[{"id":"`+did.ID+`#didcomm","type":"DIDCommMessaging","serviceEndpoint":{"uri":"https://agent.example.com","accept":["didcomm/v2"],"routingKeys":["did:example:mediator#key-1"]},"label":"agent"},`+
+		`{"id":"`+did.ID+`#hub","type":"IdentityHub","serviceEndpoint":"https://hub.example.com","version":2}]`, string(data))
+
+	// endpoint maps and arrays are exported as they are
+	did, _ = addTestKeys(t, NewDID(), "default-did-key", KeyTypeEdDSA, KeyPurposePublic)
+	hub, _ = NewService("hub", "IdentityHub", map[string]interface{}{"origins": []string{"https://hub.example.com"}})
+	multi, _ := NewService("multi", ServiceTypeDIDCommMessaging, []string{"https://a.example.com", "https://b.example.com"})
+	multi.SetCustomFields(map[string]interface{}{"accept": []string{DIDCommProfileV2}})
+	did.AddService(hub)
+	did.AddService(multi)
+
+	doc, err = did.ToW3C()
+	assert.NoError(t, err)
+	data, err = json.Marshal(doc.Service)
+	assert.NoError(t, err)
+	assert.Equal(t, `[{"id":"`+did.ID+`#hub","type":"IdentityHub","serviceEndpoint":{"origins":["https://hub.example.com"]}},`+
+		`{"id":"`+did.ID+`#multi","type":"DIDCommMessaging","serviceEndpoint":["https://a.example.com","https://b.example.com"],"accept":["didcomm/v2"]}]`, string(data))
+
+}